@@ -75,6 +75,7 @@ func Clone(n Node) Node {
 
 		// Bad
 		out.Length = n.Length
+		out.Source = n.Source
 
 		// Decoration: End
 		out.Decs.End = append(out.Decs.End, n.Decs.End...)
@@ -92,6 +93,7 @@ func Clone(n Node) Node {
 
 		// Bad
 		out.Length = n.Length
+		out.Source = n.Source
 
 		// Decoration: End
 		out.Decs.End = append(out.Decs.End, n.Decs.End...)
@@ -109,6 +111,7 @@ func Clone(n Node) Node {
 
 		// Bad
 		out.Length = n.Length
+		out.Source = n.Source
 
 		// Decoration: End
 		out.Decs.End = append(out.Decs.End, n.Decs.End...)
@@ -0,0 +1,29 @@
+package dst
+
+import "strings"
+
+// CommentInfo describes a comment in the decorations of a node.
+type CommentInfo struct {
+	Text  string // Text is the text of the comment, including the "//" or "/*" and "*/" markers.
+	Block bool   // Block is true for a "/*"-style comment, and false for a "//"-style comment.
+	Node  Node   // Node is the node that the comment is attached to.
+	Name  string // Name is the name of the decoration attachment point (e.g. "Start" or "End").
+	Index int    // Index is the index of the comment in the decorations of the attachment point.
+}
+
+// AllComments returns all the comments in the decorations of a file, in the order they are
+// rendered when the file is restored. For a file that has been decorated and not modified, this is
+// the order of the comments in the source.
+func AllComments(f *File) []CommentInfo {
+	var comments []CommentInfo
+	walkDecorations(f, func(n Node, name string, decs Decorations) {
+		for i, d := range decs {
+			block := strings.HasPrefix(d, "/*")
+			if !block && !strings.HasPrefix(d, "//") {
+				continue
+			}
+			comments = append(comments, CommentInfo{Text: d, Block: block, Node: n, Name: name, Index: i})
+		}
+	})
+	return comments
+}
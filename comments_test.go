@@ -0,0 +1,76 @@
+package dst_test
+
+import (
+	"go/parser"
+	"go/token"
+	"testing"
+
+	"github.com/dave/dst"
+	"github.com/dave/dst/decorator"
+)
+
+func TestAllComments(t *testing.T) {
+	src := `// Package a does things.
+package a
+
+import (
+	"fmt" // fmt prints
+)
+
+// T is a type.
+type T struct {
+	A int /* a */
+	// B is a field.
+	B string
+}
+
+func /* name */ F(a /* param */ int) ( /* results */ int) {
+	// block
+	if a > 0 { // positive
+		/* inner */ fmt.Println(a /* arg */)
+	}
+	return a // done
+}
+
+// trailing
+`
+	fset := token.NewFileSet()
+	af, err := parser.ParseFile(fset, "a.go", src, parser.ParseComments)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var expect []string
+	for _, cg := range af.Comments {
+		for _, c := range cg.List {
+			expect = append(expect, c.Text)
+		}
+	}
+
+	f, err := decorator.DecorateFile(fset, af)
+	if err != nil {
+		t.Fatal(err)
+	}
+	comments := dst.AllComments(f)
+	if len(comments) != len(expect) {
+		t.Fatalf("expected %d comments, found %d", len(expect), len(comments))
+	}
+	for i, c := range comments {
+		if c.Text != expect[i] {
+			t.Errorf("comment %d: expected %q, found %q", i, expect[i], c.Text)
+		}
+		if block := c.Text[1] == '*'; c.Block != block {
+			t.Errorf("comment %d: expected Block %v, found %v", i, block, c.Block)
+		}
+		decs := c.Node.Decorations()
+		if c.Name == "Start" && decs.Start[c.Index] != c.Text || c.Name == "End" && decs.End[c.Index] != c.Text {
+			t.Errorf("comment %d: not found at %s %d", i, c.Name, c.Index)
+		}
+	}
+
+	if c := comments[0]; c.Node != f || c.Name != "Start" {
+		t.Errorf("expected package doc in File Start, found %T %s", c.Node, c.Name)
+	}
+	if c := comments[5]; c.Node != f.Decls[2].(*dst.FuncDecl) || c.Name != "Func" {
+		t.Errorf("expected comment in FuncDecl Func, found %T %s", c.Node, c.Name)
+	}
+}
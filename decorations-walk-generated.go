@@ -0,0 +1,714 @@
+package dst
+
+// notest
+
+// walkDecorations calls fn for each decoration attachment point of n and its children, in the
+// order they are rendered by the restorer.
+func walkDecorations(n Node, fn func(n Node, name string, decs Decorations)) {
+	switch n := n.(type) {
+	case *ArrayType:
+		fn(n, "Start", n.Decs.Start)
+		fn(n, "Lbrack", n.Decs.Lbrack)
+		if n.Len != nil {
+			walkDecorations(n.Len, fn)
+		}
+		fn(n, "Len", n.Decs.Len)
+		if n.Elt != nil {
+			walkDecorations(n.Elt, fn)
+		}
+		fn(n, "End", n.Decs.End)
+	case *AssignStmt:
+		fn(n, "Start", n.Decs.Start)
+		for _, v := range n.Lhs {
+			walkDecorations(v, fn)
+		}
+		fn(n, "Tok", n.Decs.Tok)
+		for _, v := range n.Rhs {
+			walkDecorations(v, fn)
+		}
+		fn(n, "End", n.Decs.End)
+	case *BadDecl:
+		fn(n, "Start", n.Decs.Start)
+		fn(n, "End", n.Decs.End)
+	case *BadExpr:
+		fn(n, "Start", n.Decs.Start)
+		fn(n, "End", n.Decs.End)
+	case *BadStmt:
+		fn(n, "Start", n.Decs.Start)
+		fn(n, "End", n.Decs.End)
+	case *BasicLit:
+		fn(n, "Start", n.Decs.Start)
+		fn(n, "End", n.Decs.End)
+	case *BinaryExpr:
+		fn(n, "Start", n.Decs.Start)
+		if n.X != nil {
+			walkDecorations(n.X, fn)
+		}
+		fn(n, "X", n.Decs.X)
+		fn(n, "Op", n.Decs.Op)
+		if n.Y != nil {
+			walkDecorations(n.Y, fn)
+		}
+		fn(n, "End", n.Decs.End)
+	case *BlockStmt:
+		fn(n, "Start", n.Decs.Start)
+		fn(n, "Lbrace", n.Decs.Lbrace)
+		for _, v := range n.List {
+			walkDecorations(v, fn)
+		}
+		fn(n, "End", n.Decs.End)
+	case *BranchStmt:
+		fn(n, "Start", n.Decs.Start)
+		fn(n, "Tok", n.Decs.Tok)
+		if n.Label != nil {
+			walkDecorations(n.Label, fn)
+		}
+		fn(n, "End", n.Decs.End)
+	case *CallExpr:
+		fn(n, "Start", n.Decs.Start)
+		if n.Fun != nil {
+			walkDecorations(n.Fun, fn)
+		}
+		fn(n, "Fun", n.Decs.Fun)
+		fn(n, "Lparen", n.Decs.Lparen)
+		for _, v := range n.Args {
+			walkDecorations(v, fn)
+		}
+		fn(n, "Ellipsis", n.Decs.Ellipsis)
+		fn(n, "End", n.Decs.End)
+	case *CaseClause:
+		fn(n, "Start", n.Decs.Start)
+		fn(n, "Case", n.Decs.Case)
+		for _, v := range n.List {
+			walkDecorations(v, fn)
+		}
+		fn(n, "Colon", n.Decs.Colon)
+		for _, v := range n.Body {
+			walkDecorations(v, fn)
+		}
+		fn(n, "End", n.Decs.End)
+	case *ChanType:
+		fn(n, "Start", n.Decs.Start)
+		fn(n, "Begin", n.Decs.Begin)
+		fn(n, "Arrow", n.Decs.Arrow)
+		if n.Value != nil {
+			walkDecorations(n.Value, fn)
+		}
+		fn(n, "End", n.Decs.End)
+	case *CommClause:
+		fn(n, "Start", n.Decs.Start)
+		fn(n, "Case", n.Decs.Case)
+		if n.Comm != nil {
+			walkDecorations(n.Comm, fn)
+		}
+		fn(n, "Comm", n.Decs.Comm)
+		fn(n, "Colon", n.Decs.Colon)
+		for _, v := range n.Body {
+			walkDecorations(v, fn)
+		}
+		fn(n, "End", n.Decs.End)
+	case *CompositeLit:
+		fn(n, "Start", n.Decs.Start)
+		if n.Type != nil {
+			walkDecorations(n.Type, fn)
+		}
+		fn(n, "Type", n.Decs.Type)
+		fn(n, "Lbrace", n.Decs.Lbrace)
+		for _, v := range n.Elts {
+			walkDecorations(v, fn)
+		}
+		fn(n, "End", n.Decs.End)
+	case *DeclStmt:
+		fn(n, "Start", n.Decs.Start)
+		if n.Decl != nil {
+			walkDecorations(n.Decl, fn)
+		}
+		fn(n, "End", n.Decs.End)
+	case *DeferStmt:
+		fn(n, "Start", n.Decs.Start)
+		fn(n, "Defer", n.Decs.Defer)
+		if n.Call != nil {
+			walkDecorations(n.Call, fn)
+		}
+		fn(n, "End", n.Decs.End)
+	case *Ellipsis:
+		fn(n, "Start", n.Decs.Start)
+		fn(n, "Ellipsis", n.Decs.Ellipsis)
+		if n.Elt != nil {
+			walkDecorations(n.Elt, fn)
+		}
+		fn(n, "End", n.Decs.End)
+	case *EmptyStmt:
+		fn(n, "Start", n.Decs.Start)
+		fn(n, "End", n.Decs.End)
+	case *ExprStmt:
+		fn(n, "Start", n.Decs.Start)
+		if n.X != nil {
+			walkDecorations(n.X, fn)
+		}
+		fn(n, "End", n.Decs.End)
+	case *Field:
+		fn(n, "Start", n.Decs.Start)
+		for _, v := range n.Names {
+			walkDecorations(v, fn)
+		}
+		if n.Type != nil {
+			walkDecorations(n.Type, fn)
+		}
+		fn(n, "Type", n.Decs.Type)
+		if n.Tag != nil {
+			walkDecorations(n.Tag, fn)
+		}
+		fn(n, "End", n.Decs.End)
+	case *FieldList:
+		fn(n, "Start", n.Decs.Start)
+		fn(n, "Opening", n.Decs.Opening)
+		for _, v := range n.List {
+			walkDecorations(v, fn)
+		}
+		fn(n, "End", n.Decs.End)
+	case *File:
+		fn(n, "Start", n.Decs.Start)
+		fn(n, "Package", n.Decs.Package)
+		if n.Name != nil {
+			walkDecorations(n.Name, fn)
+		}
+		fn(n, "Name", n.Decs.Name)
+		for _, v := range n.Decls {
+			walkDecorations(v, fn)
+		}
+		fn(n, "End", n.Decs.End)
+	case *ForStmt:
+		fn(n, "Start", n.Decs.Start)
+		fn(n, "For", n.Decs.For)
+		if n.Init != nil {
+			walkDecorations(n.Init, fn)
+		}
+		fn(n, "Init", n.Decs.Init)
+		if n.Cond != nil {
+			walkDecorations(n.Cond, fn)
+		}
+		fn(n, "Cond", n.Decs.Cond)
+		if n.Post != nil {
+			walkDecorations(n.Post, fn)
+		}
+		fn(n, "Post", n.Decs.Post)
+		if n.Body != nil {
+			walkDecorations(n.Body, fn)
+		}
+		fn(n, "End", n.Decs.End)
+	case *FuncDecl:
+		fn(n, "Start", n.Decs.Start)
+		fn(n.Type, "Start", n.Type.Decs.Start)
+		fn(n, "Func", n.Decs.Func)
+		fn(n.Type, "Func", n.Type.Decs.Func)
+		if n.Recv != nil {
+			walkDecorations(n.Recv, fn)
+		}
+		fn(n, "Recv", n.Decs.Recv)
+		if n.Name != nil {
+			walkDecorations(n.Name, fn)
+		}
+		fn(n, "Name", n.Decs.Name)
+		if n.Type.Params != nil {
+			walkDecorations(n.Type.Params, fn)
+		}
+		fn(n, "Params", n.Decs.Params)
+		fn(n.Type, "Params", n.Type.Decs.Params)
+		if n.Type.Results != nil {
+			walkDecorations(n.Type.Results, fn)
+		}
+		fn(n, "Results", n.Decs.Results)
+		fn(n.Type, "End", n.Type.Decs.End)
+		if n.Body != nil {
+			walkDecorations(n.Body, fn)
+		}
+		fn(n, "End", n.Decs.End)
+	case *FuncLit:
+		fn(n, "Start", n.Decs.Start)
+		if n.Type != nil {
+			walkDecorations(n.Type, fn)
+		}
+		fn(n, "Type", n.Decs.Type)
+		if n.Body != nil {
+			walkDecorations(n.Body, fn)
+		}
+		fn(n, "End", n.Decs.End)
+	case *FuncType:
+		fn(n, "Start", n.Decs.Start)
+		fn(n, "Func", n.Decs.Func)
+		if n.Params != nil {
+			walkDecorations(n.Params, fn)
+		}
+		fn(n, "Params", n.Decs.Params)
+		if n.Results != nil {
+			walkDecorations(n.Results, fn)
+		}
+		fn(n, "End", n.Decs.End)
+	case *GenDecl:
+		fn(n, "Start", n.Decs.Start)
+		fn(n, "Tok", n.Decs.Tok)
+		fn(n, "Lparen", n.Decs.Lparen)
+		for _, v := range n.Specs {
+			walkDecorations(v, fn)
+		}
+		fn(n, "End", n.Decs.End)
+	case *GoStmt:
+		fn(n, "Start", n.Decs.Start)
+		fn(n, "Go", n.Decs.Go)
+		if n.Call != nil {
+			walkDecorations(n.Call, fn)
+		}
+		fn(n, "End", n.Decs.End)
+	case *Ident:
+		fn(n, "Start", n.Decs.Start)
+		fn(n, "X", n.Decs.X)
+		fn(n, "End", n.Decs.End)
+	case *IfStmt:
+		fn(n, "Start", n.Decs.Start)
+		fn(n, "If", n.Decs.If)
+		if n.Init != nil {
+			walkDecorations(n.Init, fn)
+		}
+		fn(n, "Init", n.Decs.Init)
+		if n.Cond != nil {
+			walkDecorations(n.Cond, fn)
+		}
+		fn(n, "Cond", n.Decs.Cond)
+		if n.Body != nil {
+			walkDecorations(n.Body, fn)
+		}
+		fn(n, "Else", n.Decs.Else)
+		if n.Else != nil {
+			walkDecorations(n.Else, fn)
+		}
+		fn(n, "End", n.Decs.End)
+	case *ImportSpec:
+		fn(n, "Start", n.Decs.Start)
+		if n.Name != nil {
+			walkDecorations(n.Name, fn)
+		}
+		fn(n, "Name", n.Decs.Name)
+		if n.Path != nil {
+			walkDecorations(n.Path, fn)
+		}
+		fn(n, "End", n.Decs.End)
+	case *IncDecStmt:
+		fn(n, "Start", n.Decs.Start)
+		if n.X != nil {
+			walkDecorations(n.X, fn)
+		}
+		fn(n, "X", n.Decs.X)
+		fn(n, "End", n.Decs.End)
+	case *IndexExpr:
+		fn(n, "Start", n.Decs.Start)
+		if n.X != nil {
+			walkDecorations(n.X, fn)
+		}
+		fn(n, "X", n.Decs.X)
+		fn(n, "Lbrack", n.Decs.Lbrack)
+		if n.Index != nil {
+			walkDecorations(n.Index, fn)
+		}
+		fn(n, "Index", n.Decs.Index)
+		fn(n, "End", n.Decs.End)
+	case *InterfaceType:
+		fn(n, "Start", n.Decs.Start)
+		fn(n, "Interface", n.Decs.Interface)
+		if n.Methods != nil {
+			walkDecorations(n.Methods, fn)
+		}
+		fn(n, "End", n.Decs.End)
+	case *KeyValueExpr:
+		fn(n, "Start", n.Decs.Start)
+		if n.Key != nil {
+			walkDecorations(n.Key, fn)
+		}
+		fn(n, "Key", n.Decs.Key)
+		fn(n, "Colon", n.Decs.Colon)
+		if n.Value != nil {
+			walkDecorations(n.Value, fn)
+		}
+		fn(n, "End", n.Decs.End)
+	case *LabeledStmt:
+		fn(n, "Start", n.Decs.Start)
+		if n.Label != nil {
+			walkDecorations(n.Label, fn)
+		}
+		fn(n, "Label", n.Decs.Label)
+		fn(n, "Colon", n.Decs.Colon)
+		if n.Stmt != nil {
+			walkDecorations(n.Stmt, fn)
+		}
+		fn(n, "End", n.Decs.End)
+	case *MapType:
+		fn(n, "Start", n.Decs.Start)
+		fn(n, "Map", n.Decs.Map)
+		if n.Key != nil {
+			walkDecorations(n.Key, fn)
+		}
+		fn(n, "Key", n.Decs.Key)
+		if n.Value != nil {
+			walkDecorations(n.Value, fn)
+		}
+		fn(n, "End", n.Decs.End)
+	case *Package:
+	case *ParenExpr:
+		fn(n, "Start", n.Decs.Start)
+		fn(n, "Lparen", n.Decs.Lparen)
+		if n.X != nil {
+			walkDecorations(n.X, fn)
+		}
+		fn(n, "X", n.Decs.X)
+		fn(n, "End", n.Decs.End)
+	case *RangeStmt:
+		fn(n, "Start", n.Decs.Start)
+		fn(n, "For", n.Decs.For)
+		if n.Key != nil {
+			walkDecorations(n.Key, fn)
+		}
+		fn(n, "Key", n.Decs.Key)
+		if n.Value != nil {
+			walkDecorations(n.Value, fn)
+		}
+		fn(n, "Value", n.Decs.Value)
+		fn(n, "Range", n.Decs.Range)
+		if n.X != nil {
+			walkDecorations(n.X, fn)
+		}
+		fn(n, "X", n.Decs.X)
+		if n.Body != nil {
+			walkDecorations(n.Body, fn)
+		}
+		fn(n, "End", n.Decs.End)
+	case *ReturnStmt:
+		fn(n, "Start", n.Decs.Start)
+		fn(n, "Return", n.Decs.Return)
+		for _, v := range n.Results {
+			walkDecorations(v, fn)
+		}
+		fn(n, "End", n.Decs.End)
+	case *SelectStmt:
+		fn(n, "Start", n.Decs.Start)
+		fn(n, "Select", n.Decs.Select)
+		if n.Body != nil {
+			walkDecorations(n.Body, fn)
+		}
+		fn(n, "End", n.Decs.End)
+	case *SelectorExpr:
+		fn(n, "Start", n.Decs.Start)
+		if n.X != nil {
+			walkDecorations(n.X, fn)
+		}
+		fn(n, "X", n.Decs.X)
+		if n.Sel != nil {
+			walkDecorations(n.Sel, fn)
+		}
+		fn(n, "End", n.Decs.End)
+	case *SendStmt:
+		fn(n, "Start", n.Decs.Start)
+		if n.Chan != nil {
+			walkDecorations(n.Chan, fn)
+		}
+		fn(n, "Chan", n.Decs.Chan)
+		fn(n, "Arrow", n.Decs.Arrow)
+		if n.Value != nil {
+			walkDecorations(n.Value, fn)
+		}
+		fn(n, "End", n.Decs.End)
+	case *SliceExpr:
+		fn(n, "Start", n.Decs.Start)
+		if n.X != nil {
+			walkDecorations(n.X, fn)
+		}
+		fn(n, "X", n.Decs.X)
+		fn(n, "Lbrack", n.Decs.Lbrack)
+		if n.Low != nil {
+			walkDecorations(n.Low, fn)
+		}
+		fn(n, "Low", n.Decs.Low)
+		if n.High != nil {
+			walkDecorations(n.High, fn)
+		}
+		fn(n, "High", n.Decs.High)
+		if n.Max != nil {
+			walkDecorations(n.Max, fn)
+		}
+		fn(n, "Max", n.Decs.Max)
+		fn(n, "End", n.Decs.End)
+	case *StarExpr:
+		fn(n, "Start", n.Decs.Start)
+		fn(n, "Star", n.Decs.Star)
+		if n.X != nil {
+			walkDecorations(n.X, fn)
+		}
+		fn(n, "End", n.Decs.End)
+	case *StructType:
+		fn(n, "Start", n.Decs.Start)
+		fn(n, "Struct", n.Decs.Struct)
+		if n.Fields != nil {
+			walkDecorations(n.Fields, fn)
+		}
+		fn(n, "End", n.Decs.End)
+	case *SwitchStmt:
+		fn(n, "Start", n.Decs.Start)
+		fn(n, "Switch", n.Decs.Switch)
+		if n.Init != nil {
+			walkDecorations(n.Init, fn)
+		}
+		fn(n, "Init", n.Decs.Init)
+		if n.Tag != nil {
+			walkDecorations(n.Tag, fn)
+		}
+		fn(n, "Tag", n.Decs.Tag)
+		if n.Body != nil {
+			walkDecorations(n.Body, fn)
+		}
+		fn(n, "End", n.Decs.End)
+	case *TypeAssertExpr:
+		fn(n, "Start", n.Decs.Start)
+		if n.X != nil {
+			walkDecorations(n.X, fn)
+		}
+		fn(n, "X", n.Decs.X)
+		fn(n, "Lparen", n.Decs.Lparen)
+		if n.Type != nil {
+			walkDecorations(n.Type, fn)
+		}
+		fn(n, "Type", n.Decs.Type)
+		fn(n, "End", n.Decs.End)
+	case *TypeSpec:
+		fn(n, "Start", n.Decs.Start)
+		if n.Name != nil {
+			walkDecorations(n.Name, fn)
+		}
+		fn(n, "Name", n.Decs.Name)
+		if n.Type != nil {
+			walkDecorations(n.Type, fn)
+		}
+		fn(n, "End", n.Decs.End)
+	case *TypeSwitchStmt:
+		fn(n, "Start", n.Decs.Start)
+		fn(n, "Switch", n.Decs.Switch)
+		if n.Init != nil {
+			walkDecorations(n.Init, fn)
+		}
+		fn(n, "Init", n.Decs.Init)
+		if n.Assign != nil {
+			walkDecorations(n.Assign, fn)
+		}
+		fn(n, "Assign", n.Decs.Assign)
+		if n.Body != nil {
+			walkDecorations(n.Body, fn)
+		}
+		fn(n, "End", n.Decs.End)
+	case *UnaryExpr:
+		fn(n, "Start", n.Decs.Start)
+		fn(n, "Op", n.Decs.Op)
+		if n.X != nil {
+			walkDecorations(n.X, fn)
+		}
+		fn(n, "End", n.Decs.End)
+	case *ValueSpec:
+		fn(n, "Start", n.Decs.Start)
+		for _, v := range n.Names {
+			walkDecorations(v, fn)
+		}
+		if n.Type != nil {
+			walkDecorations(n.Type, fn)
+		}
+		fn(n, "Assign", n.Decs.Assign)
+		for _, v := range n.Values {
+			walkDecorations(v, fn)
+		}
+		fn(n, "End", n.Decs.End)
+	}
+}
+
+// decorationSlots returns the named decoration attachment points of n, excluding Start and End.
+func decorationSlots(n Node) map[string]*Decorations {
+	switch n := n.(type) {
+	case *ArrayType:
+		return map[string]*Decorations{
+			"Lbrack": &n.Decs.Lbrack,
+			"Len":    &n.Decs.Len,
+		}
+	case *AssignStmt:
+		return map[string]*Decorations{"Tok": &n.Decs.Tok}
+	case *BinaryExpr:
+		return map[string]*Decorations{
+			"Op": &n.Decs.Op,
+			"X":  &n.Decs.X,
+		}
+	case *BlockStmt:
+		return map[string]*Decorations{"Lbrace": &n.Decs.Lbrace}
+	case *BranchStmt:
+		return map[string]*Decorations{"Tok": &n.Decs.Tok}
+	case *CallExpr:
+		return map[string]*Decorations{
+			"Ellipsis": &n.Decs.Ellipsis,
+			"Fun":      &n.Decs.Fun,
+			"Lparen":   &n.Decs.Lparen,
+		}
+	case *CaseClause:
+		return map[string]*Decorations{
+			"Case":  &n.Decs.Case,
+			"Colon": &n.Decs.Colon,
+		}
+	case *ChanType:
+		return map[string]*Decorations{
+			"Arrow": &n.Decs.Arrow,
+			"Begin": &n.Decs.Begin,
+		}
+	case *CommClause:
+		return map[string]*Decorations{
+			"Case":  &n.Decs.Case,
+			"Colon": &n.Decs.Colon,
+			"Comm":  &n.Decs.Comm,
+		}
+	case *CompositeLit:
+		return map[string]*Decorations{
+			"Lbrace": &n.Decs.Lbrace,
+			"Type":   &n.Decs.Type,
+		}
+	case *DeferStmt:
+		return map[string]*Decorations{"Defer": &n.Decs.Defer}
+	case *Ellipsis:
+		return map[string]*Decorations{"Ellipsis": &n.Decs.Ellipsis}
+	case *Field:
+		return map[string]*Decorations{"Type": &n.Decs.Type}
+	case *FieldList:
+		return map[string]*Decorations{"Opening": &n.Decs.Opening}
+	case *File:
+		return map[string]*Decorations{
+			"Name":    &n.Decs.Name,
+			"Package": &n.Decs.Package,
+		}
+	case *ForStmt:
+		return map[string]*Decorations{
+			"Cond": &n.Decs.Cond,
+			"For":  &n.Decs.For,
+			"Init": &n.Decs.Init,
+			"Post": &n.Decs.Post,
+		}
+	case *FuncDecl:
+		return map[string]*Decorations{
+			"Func":    &n.Decs.Func,
+			"Name":    &n.Decs.Name,
+			"Params":  &n.Decs.Params,
+			"Recv":    &n.Decs.Recv,
+			"Results": &n.Decs.Results,
+		}
+	case *FuncLit:
+		return map[string]*Decorations{"Type": &n.Decs.Type}
+	case *FuncType:
+		return map[string]*Decorations{
+			"Func":   &n.Decs.Func,
+			"Params": &n.Decs.Params,
+		}
+	case *GenDecl:
+		return map[string]*Decorations{
+			"Lparen": &n.Decs.Lparen,
+			"Tok":    &n.Decs.Tok,
+		}
+	case *GoStmt:
+		return map[string]*Decorations{"Go": &n.Decs.Go}
+	case *Ident:
+		return map[string]*Decorations{"X": &n.Decs.X}
+	case *IfStmt:
+		return map[string]*Decorations{
+			"Cond": &n.Decs.Cond,
+			"Else": &n.Decs.Else,
+			"If":   &n.Decs.If,
+			"Init": &n.Decs.Init,
+		}
+	case *ImportSpec:
+		return map[string]*Decorations{"Name": &n.Decs.Name}
+	case *IncDecStmt:
+		return map[string]*Decorations{"X": &n.Decs.X}
+	case *IndexExpr:
+		return map[string]*Decorations{
+			"Index":  &n.Decs.Index,
+			"Lbrack": &n.Decs.Lbrack,
+			"X":      &n.Decs.X,
+		}
+	case *InterfaceType:
+		return map[string]*Decorations{"Interface": &n.Decs.Interface}
+	case *KeyValueExpr:
+		return map[string]*Decorations{
+			"Colon": &n.Decs.Colon,
+			"Key":   &n.Decs.Key,
+		}
+	case *LabeledStmt:
+		return map[string]*Decorations{
+			"Colon": &n.Decs.Colon,
+			"Label": &n.Decs.Label,
+		}
+	case *MapType:
+		return map[string]*Decorations{
+			"Key": &n.Decs.Key,
+			"Map": &n.Decs.Map,
+		}
+	case *ParenExpr:
+		return map[string]*Decorations{
+			"Lparen": &n.Decs.Lparen,
+			"X":      &n.Decs.X,
+		}
+	case *RangeStmt:
+		return map[string]*Decorations{
+			"For":   &n.Decs.For,
+			"Key":   &n.Decs.Key,
+			"Range": &n.Decs.Range,
+			"Value": &n.Decs.Value,
+			"X":     &n.Decs.X,
+		}
+	case *ReturnStmt:
+		return map[string]*Decorations{"Return": &n.Decs.Return}
+	case *SelectStmt:
+		return map[string]*Decorations{"Select": &n.Decs.Select}
+	case *SelectorExpr:
+		return map[string]*Decorations{"X": &n.Decs.X}
+	case *SendStmt:
+		return map[string]*Decorations{
+			"Arrow": &n.Decs.Arrow,
+			"Chan":  &n.Decs.Chan,
+		}
+	case *SliceExpr:
+		return map[string]*Decorations{
+			"High":   &n.Decs.High,
+			"Lbrack": &n.Decs.Lbrack,
+			"Low":    &n.Decs.Low,
+			"Max":    &n.Decs.Max,
+			"X":      &n.Decs.X,
+		}
+	case *StarExpr:
+		return map[string]*Decorations{"Star": &n.Decs.Star}
+	case *StructType:
+		return map[string]*Decorations{"Struct": &n.Decs.Struct}
+	case *SwitchStmt:
+		return map[string]*Decorations{
+			"Init":   &n.Decs.Init,
+			"Switch": &n.Decs.Switch,
+			"Tag":    &n.Decs.Tag,
+		}
+	case *TypeAssertExpr:
+		return map[string]*Decorations{
+			"Lparen": &n.Decs.Lparen,
+			"Type":   &n.Decs.Type,
+			"X":      &n.Decs.X,
+		}
+	case *TypeSpec:
+		return map[string]*Decorations{"Name": &n.Decs.Name}
+	case *TypeSwitchStmt:
+		return map[string]*Decorations{
+			"Assign": &n.Decs.Assign,
+			"Init":   &n.Decs.Init,
+			"Switch": &n.Decs.Switch,
+		}
+	case *UnaryExpr:
+		return map[string]*Decorations{"Op": &n.Decs.Op}
+	case *ValueSpec:
+		return map[string]*Decorations{"Assign": &n.Decs.Assign}
+	}
+	return nil
+}
@@ -1,5 +1,7 @@
 package dst
 
+import "fmt"
+
 // NodeDecs holds the decorations that are common to all nodes (except Package).
 type NodeDecs struct {
 	Before SpaceType
@@ -39,6 +41,11 @@ func (d *Decorations) All() []string {
 // SpaceType represents the line spacing before or after a node. When the start of one node is
 // adjacent to the end of another node, the SpaceType values are not additive (e.g. two NewLines
 // will render a NewLine and not an EmptyLine).
+//
+// Values greater than EmptyLine represent several empty lines: SpaceType(n+1) is n empty lines.
+// The decorator only records these when Decorator.MaxBlankLines is greater than one, and the
+// restorer only prints them when Restorer.MaxBlankLines is greater than one. Otherwise they are
+// printed as EmptyLine.
 type SpaceType int
 
 const (
@@ -47,7 +54,8 @@ const (
 	EmptyLine SpaceType = 2 // EmptyLine is a double "\n"
 )
 
-// String returns a human readable representation of the space type
+// String returns a human readable representation of the space type. Values greater than EmptyLine
+// are returned with the number of empty lines, e.g. "EmptyLines(3)".
 func (s SpaceType) String() string {
 	switch s {
 	case None:
@@ -57,5 +65,31 @@ func (s SpaceType) String() string {
 	case EmptyLine:
 		return "EmptyLine"
 	}
+	if s > EmptyLine {
+		return fmt.Sprintf("EmptyLines(%d)", s-1)
+	}
 	return ""
 }
+
+// CopyDecorations replaces the decorations of to with a copy of the decorations of from, e.g. so a
+// replacement node inherits the comments and line spacing of the node it replaces. The Before,
+// Start, End and After decorations (see NodeDecs) are always copied. Other decoration attachment
+// points are copied when both node types have a point with the same name (e.g. the Tok decorations
+// of an AssignStmt and a BranchStmt), and are left unchanged otherwise. Decorations of from with no
+// matching point in to are not copied. Package nodes have no decorations, so are ignored.
+func CopyDecorations(from, to Node) {
+	fromDecs, toDecs := from.Decorations(), to.Decorations()
+	if fromDecs == nil || toDecs == nil {
+		return
+	}
+	toDecs.Before = fromDecs.Before
+	toDecs.Start.Replace(fromDecs.Start...)
+	toDecs.End.Replace(fromDecs.End...)
+	toDecs.After = fromDecs.After
+	toSlots := decorationSlots(to)
+	for name, decs := range decorationSlots(from) {
+		if target, ok := toSlots[name]; ok {
+			target.Replace(*decs...)
+		}
+	}
+}
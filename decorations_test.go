@@ -9,6 +9,7 @@ import (
 	"go/types"
 	"os"
 	"strconv"
+	"strings"
 	"testing"
 
 	"github.com/dave/dst"
@@ -80,8 +81,11 @@ func TestSpaceType_String(t *testing.T) {
 	if dst.EmptyLine.String() != "EmptyLine" {
 		t.Fatalf("expected EmptyLine, found %s", dst.EmptyLine.String())
 	}
-	if dst.SpaceType(99).String() != "" {
-		t.Fatalf("expected , found %s", dst.SpaceType(99).String())
+	if dst.SpaceType(4).String() != "EmptyLines(3)" {
+		t.Fatalf("expected EmptyLines(3), found %s", dst.SpaceType(4).String())
+	}
+	if dst.SpaceType(-1).String() != "" {
+		t.Fatalf("expected , found %s", dst.SpaceType(-1).String())
 	}
 }
 
@@ -683,3 +687,60 @@ func ExampleDstFixed() {
 	//	var a int    // foo
 	//}
 }
+
+func TestCopyDecorations(t *testing.T) {
+	code := `package a
+
+func a() {
+	b := 1
+
+	// c
+	c := /* d */ b // e
+	print(c)
+}
+`
+	expect := `package a
+
+func a() {
+	b := 1
+
+	// c
+	b += /* d */ 2 // e
+	print(c)
+}
+`
+	f, err := decorator.Parse(code)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body := f.Decls[0].(*dst.FuncDecl).Body
+	from := body.List[1].(*dst.AssignStmt)
+	to := &dst.AssignStmt{
+		Lhs: []dst.Expr{dst.NewIdent("b")},
+		Tok: token.ADD_ASSIGN,
+		Rhs: []dst.Expr{&dst.BasicLit{Kind: token.INT, Value: "2"}},
+	}
+	dst.CopyDecorations(from, to)
+	body.List[1] = to
+
+	// the decorations are copied, not shared
+	from.Decs.Start.Append("// f")
+	if len(to.Decs.Start) != 1 {
+		t.Errorf("unexpected Start decorations %q", to.Decs.Start)
+	}
+
+	// a statement of a different type only gets the common decorations
+	ret := &dst.ReturnStmt{}
+	dst.CopyDecorations(body.List[2], ret)
+	if ret.Decs.Before != dst.NewLine || ret.Decs.After != dst.NewLine {
+		t.Errorf("unexpected spacing %s %s", ret.Decs.Before, ret.Decs.After)
+	}
+
+	buf := &strings.Builder{}
+	if err := decorator.Fprint(buf, f); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != expect {
+		t.Errorf("\nexpect: %q\nfound : %q", expect, buf.String())
+	}
+}
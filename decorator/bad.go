@@ -0,0 +1,192 @@
+package decorator
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"io"
+	"io/ioutil"
+	"strconv"
+	"strings"
+
+	"github.com/dave/dst"
+	"github.com/dave/dst/dstutil"
+)
+
+// readSource returns the source of a file in the same way as parser.ParseFile: if src != nil, it
+// is used as the source and must be a string, []byte, or io.Reader. Otherwise the file is read.
+func readSource(filename string, src interface{}) ([]byte, error) {
+	if src != nil {
+		switch s := src.(type) {
+		case string:
+			return []byte(s), nil
+		case []byte:
+			return s, nil
+		case *bytes.Buffer:
+			// is io.Reader, but src is already available in []byte form
+			if s != nil {
+				return s.Bytes(), nil
+			}
+		case io.Reader:
+			return ioutil.ReadAll(s)
+		}
+		return nil, errors.New("invalid source")
+	}
+	return ioutil.ReadFile(filename)
+}
+
+// badSource returns the original source between two positions with surrounding white space
+// removed, or an empty string if the source is not available. The source is only available for
+// files with parse errors that were parsed with ParseFile.
+func (f *fileDecorator) badSource(from, to token.Pos) string {
+	tf := f.Fset.File(from)
+	if tf == nil {
+		return ""
+	}
+	src, ok := f.sources[tf]
+	if !ok {
+		return ""
+	}
+	start, end := tf.Offset(from), int(to)-tf.Base()
+	if start < 0 || end > len(src) || start > end {
+		return ""
+	}
+	return string(bytes.TrimSpace(src[start:end]))
+}
+
+// newNonce returns the text for the markers added while printing file: the lowest number that
+// isn't used after the prefix of a marker in any text of the file, so the markers can't match the
+// code.
+func newNonce(file *dst.File) string {
+	var texts []string
+	add := func(text string) {
+		if strings.Contains(text, "dst") {
+			texts = append(texts, text)
+		}
+	}
+	dst.Inspect(file, func(n dst.Node) bool {
+		switch n := n.(type) {
+		case nil:
+			return false
+		case *dst.Ident:
+			add(n.Name)
+		case *dst.BasicLit:
+			add(n.Value)
+		case *dst.BadExpr:
+			add(n.Source)
+		case *dst.BadStmt:
+			add(n.Source)
+		case *dst.BadDecl:
+			add(n.Source)
+		}
+		_, _, points := dstutil.Decorations(n)
+		for _, p := range points {
+			for _, d := range p.Decs {
+				add(d)
+			}
+		}
+		return true
+	})
+	for i := 0; ; i++ {
+		nonce := strconv.Itoa(i)
+		if !containsMarker(texts, nonce) {
+			return nonce
+		}
+	}
+}
+
+// containsMarker returns true if any of texts contains the prefix of a marker with nonce.
+func containsMarker(texts []string, nonce string) bool {
+	for _, text := range texts {
+		for _, prefix := range []string{"dst:blank:", "dst_bad_", "dst:preserve:"} {
+			if strings.Contains(text, prefix+nonce) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// badMarker returns a new marker for the source of a bad node restored while printing.
+func (r *FileRestorer) badMarker() string {
+	return fmt.Sprintf("dst_bad_%s_%d", r.nonce, len(r.badSources))
+}
+
+// addBadSource records the source of a bad node restored while printing. printed is the text that
+// the restored node is printed as, which is replaced by the source in the output by formatFile.
+func (r *FileRestorer) addBadSource(printed, source string) {
+	if r.badSources == nil {
+		r.badSources = map[string]string{}
+	}
+	r.badSources[printed] = source
+}
+
+// restoreBadDeclSource restores a *dst.BadDecl with the original source while printing. There is
+// no declaration node that the printer renders verbatim, so a variable declaration with a marker
+// name is restored.
+func (r *FileRestorer) restoreBadDeclSource(n *dst.BadDecl) ast.Node {
+	marker := r.badMarker()
+	r.addBadSource("var "+marker, n.Source)
+
+	out := &ast.GenDecl{Tok: token.VAR}
+	r.Ast.Nodes[n] = out
+	r.Dst.Nodes[out] = n
+	r.applySpace(n, "Before", n.Decs.Before)
+
+	// Decoration: Start
+	r.applyDecorations(out, n.Decs.Start, false)
+
+	// Token: Tok
+	out.TokPos = r.cursor
+	r.cursor += token.Pos(len("var "))
+
+	// String: marker
+	id := &ast.Ident{NamePos: r.cursor, Name: marker}
+	out.Specs = []ast.Spec{&ast.ValueSpec{Names: []*ast.Ident{id}}}
+	r.cursor += token.Pos(len(marker))
+
+	// Decoration: End
+	r.applyDecorations(out, n.Decs.End, true)
+	r.applySpace(n, "After", n.Decs.After)
+
+	return out
+}
+
+// restoreBadSource restores a *dst.BadExpr or *dst.BadStmt with the original source while
+// printing. The printer renders *ast.BadExpr and *ast.BadStmt as placeholder text, so a raw string
+// literal containing a marker is restored (as an expression statement for a *dst.BadStmt). The
+// literal has the same number of lines as the source, so the printer lays out the code around it
+// in the same way.
+func (r *FileRestorer) restoreBadSource(n dst.Node, source string) ast.Node {
+	decs := n.Decorations()
+	value := "`" + r.badMarker() + strings.Repeat("\n", strings.Count(source, "\n")) + "`"
+	r.addBadSource(value, source)
+	lit := &ast.BasicLit{Kind: token.STRING, Value: value}
+	var out ast.Node = lit
+	if _, ok := n.(*dst.BadStmt); ok {
+		out = &ast.ExprStmt{X: lit}
+	}
+	r.Ast.Nodes[n] = out
+	r.Dst.Nodes[out] = n
+	r.applySpace(n, "Before", decs.Before)
+
+	// Decoration: Start
+	r.applyDecorations(out, decs.Start, false)
+
+	// String: marker
+	lit.ValuePos = r.cursor
+	for i, c := range value {
+		if c == '\n' {
+			r.lines = append(r.lines, int(r.cursor)-r.base+i)
+		}
+	}
+	r.cursor += token.Pos(len(value))
+
+	// Decoration: End
+	r.applyDecorations(out, decs.End, true)
+	r.applySpace(n, "After", decs.After)
+
+	return out
+}
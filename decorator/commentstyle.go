@@ -0,0 +1,87 @@
+package decorator
+
+import (
+	"strings"
+
+	"github.com/dave/dst"
+)
+
+// CommentStyle controls how comments are restored.
+type CommentStyle int
+
+const (
+	// CommentPreserve restores comments exactly as they are in the decorations.
+	CommentPreserve CommentStyle = iota
+	// CommentLine converts block comments to line comments (e.g. "/* a */" is restored as "// a").
+	// Only single-line block comments followed by a line break are converted, because a line
+	// comment ends the line. Multi-line block comments and block comments followed by code (e.g.
+	// "f(/* a */ b)") are left unchanged.
+	CommentLine
+	// CommentBlock converts line comments to block comments (e.g. "// a" is restored as
+	// "/* a */"), followed by a line break where the line comment ended. Comments containing "*/",
+	// directives (e.g. "//go:generate" or "//export") and "// +build" constraints are left
+	// unchanged.
+	CommentBlock
+)
+
+// convertComment returns the decoration at index i converted to the comment style. lineBreak is
+// true if the returned decoration must be followed by a line break (for a line comment converted
+// to a block comment), and skip is true if the following line break decoration must be skipped
+// (for a block comment converted to a line comment, which ends the line).
+func convertComment(style CommentStyle, decorations dst.Decorations, i int) (d string, lineBreak, skip bool) {
+	d = decorations[i]
+	switch style {
+	case CommentLine:
+		if !strings.HasPrefix(d, "/*") || strings.Contains(d, "\n") {
+			return d, false, false
+		}
+		if i+1 >= len(decorations) || decorations[i+1] != "\n" {
+			return d, false, false
+		}
+		return "//" + strings.TrimSuffix(strings.TrimPrefix(d, "/*"), "*/"), false, true
+	case CommentBlock:
+		if !strings.HasPrefix(d, "//") {
+			return d, false, false
+		}
+		text := strings.TrimPrefix(d, "//")
+		if isDirective(text) || strings.HasPrefix(text, " +build") || strings.Contains(text, "*/") {
+			return d, false, false
+		}
+		if !strings.HasSuffix(text, " ") && strings.HasPrefix(text, " ") {
+			text += " "
+		}
+		return "/*" + text + "*/", true, false
+	}
+	return d, false, false
+}
+
+// normalizeCommentSpacing adds a space after the "//" or "/*" of a comment that has text directly
+// after the comment marker (e.g. "//a" is restored as "// a", and "/*a*/" as "/* a */").
+// Directives (e.g. "//go:generate", "//line" and "//export"), "//nolint" comments, comments that
+// start with white space and block comments that start with "*" (e.g. "/**") are left unchanged.
+func normalizeCommentSpacing(d string) string {
+	var marker string
+	switch {
+	case strings.HasPrefix(d, "//"):
+		marker = "//"
+	case strings.HasPrefix(d, "/*"):
+		marker = "/*"
+	default:
+		return d
+	}
+	text := strings.TrimPrefix(d, marker)
+	if marker == "/*" {
+		text = strings.TrimSuffix(text, "*/")
+	}
+	if text == "" || strings.IndexAny(text[:1], " \t\r\n*/") == 0 {
+		return d
+	}
+	if isDirective(text) || text == "nolint" || strings.HasPrefix(text, "nolint:") {
+		return d
+	}
+	if marker == "/*" && !strings.Contains(text, "\n") && !strings.HasSuffix(text, " ") {
+		// single-line block comments get a matching space before the "*/"
+		return "/* " + text + " */"
+	}
+	return marker + " " + text
+}
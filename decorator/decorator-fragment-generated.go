@@ -1463,12 +1463,12 @@ func (f *fileDecorator) addNodeFragments(n ast.Node) {
 		}
 
 		// Token: Assign
-		if n.Values != nil {
+		if len(n.Values) > 0 {
 			f.addTokenFragment(n, token.ASSIGN, token.NoPos)
 		}
 
 		// Decoration: Assign
-		if n.Values != nil {
+		if len(n.Values) > 0 {
 			f.addDecorationFragment(n, "Assign", token.NoPos)
 		}
 
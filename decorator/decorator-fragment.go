@@ -45,10 +45,10 @@ func (f *fileDecorator) addCommentFragment(text string, pos token.Pos) {
 	f.fragments = append(f.fragments, &commentFragment{Text: text, Pos: pos})
 }
 
-func (f *fileDecorator) addNewlineFragment(pos token.Pos, empty bool) {
+func (f *fileDecorator) addNewlineFragment(pos token.Pos, empty bool, blank int) {
 	// Don't need to worry about the cursor with newlines - they are added to the fragment list in
 	// the wrong order, then we sort the list based on Pos
-	f.fragments = append(f.fragments, &newlineFragment{Pos: pos, Empty: empty})
+	f.fragments = append(f.fragments, &newlineFragment{Pos: pos, Empty: empty, Blank: blank})
 }
 
 func (f *fileDecorator) fragment(node ast.Node) {
@@ -149,16 +149,31 @@ func (f *fileDecorator) fragment(node ast.Node) {
 					}
 
 					if nextLine != line {
-						// add an empty line fragment
-						f.addNewlineFragment(token.Pos(i-1), true)
+						pos := token.Pos(i - 1)
 
 						// for empty lines, increment past the second "\n" manually:
 						line = nextLine
 						i++
 
+						// if more than one empty line is recorded, also step over the following
+						// empty lines, counting them.
+						blank := 1
+						for blank < f.MaxBlankLines && i < max-1 {
+							next := f.Fset.Position(token.Pos(i + 1)).Line
+							if next == line || avoid[next] {
+								break
+							}
+							blank++
+							line = next
+							i++
+						}
+
+						// add an empty line fragment
+						f.addNewlineFragment(pos, true, blank)
+
 					} else {
 						// add a new line fragment
-						f.addNewlineFragment(token.Pos(i-1), false)
+						f.addNewlineFragment(token.Pos(i-1), false, 0)
 					}
 
 				}
@@ -342,12 +357,14 @@ func (f *fileDecorator) link() {
 			if foundBefore || foundAfter {
 				spaceType := dst.NewLine
 				if frag.Empty {
-					spaceType = dst.EmptyLine
+					spaceType = dst.EmptyLine + dst.SpaceType(frag.Blank-1)
 				}
-				if foundBefore {
+				// Several newlines can be associated with a node (e.g. an empty line followed by a
+				// newline), so the spacing is never reduced.
+				if foundBefore && spaceType > f.before[nodeBefore] {
 					f.before[nodeBefore] = spaceType
 				}
-				if foundAfter {
+				if foundAfter && spaceType > f.after[nodeAfter] {
 					f.after[nodeAfter] = spaceType
 				}
 				continue
@@ -371,7 +388,7 @@ func (f *fileDecorator) link() {
 					panic("no decoration found for newline")
 				}
 			}
-			appendNewLine(f.decorations, dec.Node, dec.Name, frag.Empty)
+			appendNewLine(f.decorations, dec.Node, dec.Name, frag)
 		}
 	}
 
@@ -385,13 +402,13 @@ func appendDecoration(m map[ast.Node]map[string][]string, n ast.Node, pos, text
 	m[n][pos] = append(m[n][pos], text)
 }
 
-func appendNewLine(m map[ast.Node]map[string][]string, n ast.Node, pos string, empty bool) {
+func appendNewLine(m map[ast.Node]map[string][]string, n ast.Node, pos string, frag *newlineFragment) {
 	if m[n] == nil {
 		m[n] = map[string][]string{}
 	}
 	num := 1
-	if empty {
-		num = 2
+	if frag.Empty {
+		num = 1 + frag.Blank
 	}
 	decs := m[n][pos]
 	if len(decs) > 0 && strings.HasPrefix(decs[len(decs)-1], "//") {
@@ -409,7 +426,7 @@ func (f *fileDecorator) attachToDecoration(frags []fragment, decorations map[ast
 			appendDecoration(decorations, dec.Node, dec.Name, fr.Text)
 			fr.Attached = dec
 		case *newlineFragment:
-			appendNewLine(decorations, dec.Node, dec.Name, fr.Empty)
+			appendNewLine(decorations, dec.Node, dec.Name, fr)
 			fr.Attached = dec
 		}
 	}
@@ -565,6 +582,7 @@ type commentFragment struct {
 type newlineFragment struct {
 	Pos      token.Pos
 	Empty    bool                // true if this newline is an empty line (e.g. follows a "//" comment or "\n")
+	Blank    int                 // number of consecutive empty lines, if Empty (more than one only if MaxBlankLines is set)
 	Attached *decorationFragment // where did we attach this comment in pass 1?
 }
 
@@ -109,6 +109,7 @@ func (f *fileDecorator) decorateNode(parent ast.Node, parentName, parentField, p
 
 		// Bad
 		out.Length = int(n.To - n.From)
+		out.Source = f.badSource(n.From, n.To)
 
 		if nd, ok := f.decorations[n]; ok {
 			if decs, ok := nd["Start"]; ok {
@@ -130,6 +131,7 @@ func (f *fileDecorator) decorateNode(parent ast.Node, parentName, parentField, p
 
 		// Bad
 		out.Length = int(n.To - n.From)
+		out.Source = f.badSource(n.From, n.To)
 
 		if nd, ok := f.decorations[n]; ok {
 			if decs, ok := nd["Start"]; ok {
@@ -151,6 +153,7 @@ func (f *fileDecorator) decorateNode(parent ast.Node, parentName, parentField, p
 
 		// Bad
 		out.Length = int(n.To - n.From)
+		out.Source = f.badSource(n.From, n.To)
 
 		if nd, ok := f.decorations[n]; ok {
 			if decs, ok := nd["Start"]; ok {
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"go/ast"
 	"go/parser"
+	"go/scanner"
 	"go/token"
 	"io"
 	"os"
@@ -22,9 +23,10 @@ func NewDecorator(fset *token.FileSet) *Decorator {
 		fset = token.NewFileSet()
 	}
 	return &Decorator{
-		Map:       newMap(),
-		Filenames: map[*dst.File]string{},
-		Fset:      fset,
+		Map:           newMap(),
+		Filenames:     map[*dst.File]string{},
+		FinalNewlines: map[*dst.File]int{},
+		Fset:          fset,
 	}
 }
 
@@ -48,6 +50,17 @@ type Decorator struct {
 	Filenames map[*dst.File]string // Source file names
 	Fset      *token.FileSet       // The ast FileSet containing ast decoration info for the files
 
+	// MaxBlankLines is the maximum number of consecutive empty lines recorded in the Before and
+	// After spacing of the nodes (see dst.SpaceType) and as "\n" decorations. By default (or if
+	// MaxBlankLines is less than two) several empty lines between nodes are recorded as a single
+	// dst.EmptyLine, in the same way as gofmt collapses them. Set Restorer.MaxBlankLines to print
+	// them.
+	MaxBlankLines int
+
+	// FinalNewlines records the number of newlines at the end of each decorated file, for
+	// restoring with Restorer.FinalNewline set to FinalNewlinePreserve.
+	FinalNewlines map[*dst.File]int
+
 	// If a Resolver is provided, it is used to resolve remote identifiers from *ast.Ident and
 	// *ast.SelectorExpr nodes. Usually a remote identifier is a SelectorExpr qualified identifier,
 	// but in the case of dot-imports they can be simply Ident nodes. During decoration, remote
@@ -55,6 +68,34 @@ type Decorator struct {
 	Resolver resolver.DecoratorResolver
 	// Local package path - required if Resolver is set.
 	Path string
+
+	// If DetectDuplicateImports is set, a Warning is added to Warnings for each duplicated import
+	// path and each import alias used for more than one path in the decorated files.
+	DetectDuplicateImports bool
+	// Warnings lists problems found while decorating that did not cause an error.
+	Warnings []Warning
+
+	// If SkipObjectResolution is set, the Parse, ParseFile, ParseDir, ParseExpr and ParseStmts
+	// methods parse with parser.SkipObjectResolution, which is faster. Decoration doesn't depend
+	// on objects, but the decorated identifiers have no Obj, and the files and packages have no
+	// Scope. The goast resolver uses Obj to detect local identifiers that shadow an imported
+	// package name, so a selector on a local variable with the same name as an import is resolved
+	// as a qualified identifier.
+	SkipObjectResolution bool
+
+	// If PinGenerateDirectives is set, "//go:generate" directives that the decorator attaches to
+	// the End decorations of the previous declaration are moved to the Start decorations of the
+	// following declaration, so they move with it when the declarations are rearranged. Directives
+	// after the last declaration are moved to the End decorations of the file.
+	PinGenerateDirectives bool
+
+	// Cgo "//export" directives that the decorator attaches to the End decorations of the previous
+	// declaration are always moved to the Start decorations of the function, so they move with it
+	// when the declarations are rearranged. If PinFuncDirectives is set, compiler directives for
+	// functions (e.g. "//go:noinline", see dstutil.IsPragma) are moved in the same way.
+	PinFuncDirectives bool
+
+	sources map[*token.File][]byte // source of the files with parse errors being decorated, used for bad nodes
 }
 
 // Parse uses parser.ParseFile to parse and decorate a Go source file. The src parameter should
@@ -69,11 +110,25 @@ func (d *Decorator) ParseFile(filename string, src interface{}, mode parser.Mode
 
 	// If ParseFile returns an error and also a non-nil file, the errors were just parse errors so
 	// we should continue decorating the file and return the error.
-	f, perr := parser.ParseFile(d.Fset, filename, src, mode|parser.ParseComments)
+	text, err := readSource(filename, src)
+	if err != nil {
+		return nil, err
+	}
+
+	f, perr := parser.ParseFile(d.Fset, filename, text, d.parseMode(mode))
 	if perr != nil && f == nil {
 		return nil, perr
 	}
 
+	if tf := d.Fset.File(f.Pos()); perr != nil && tf != nil {
+		// keep the source while the file is decorated, so bad nodes can be restored verbatim
+		if d.sources == nil {
+			d.sources = map[*token.File][]byte{}
+		}
+		d.sources[tf] = text
+		defer delete(d.sources, tf)
+	}
+
 	file, err := d.DecorateFile(f)
 	if err != nil {
 		return nil, err
@@ -85,7 +140,7 @@ func (d *Decorator) ParseFile(filename string, src interface{}, mode parser.Mode
 // ParseDir uses parser.ParseDir to parse and decorate a directory containing Go source. The
 // ParseComments flag is added to mode if it doesn't exist.
 func (d *Decorator) ParseDir(dir string, filter func(os.FileInfo) bool, mode parser.Mode) (map[string]*dst.Package, error) {
-	pkgs, err := parser.ParseDir(d.Fset, dir, filter, mode|parser.ParseComments)
+	pkgs, err := parser.ParseDir(d.Fset, dir, filter, d.parseMode(mode))
 	if err != nil {
 		return nil, err
 	}
@@ -100,6 +155,88 @@ func (d *Decorator) ParseDir(dir string, filter func(os.FileInfo) bool, mode par
 	return out, nil
 }
 
+// parseMode adds the ParseComments flag to mode, and the SkipObjectResolution flag if configured.
+func (d *Decorator) parseMode(mode parser.Mode) parser.Mode {
+	mode |= parser.ParseComments
+	if d.SkipObjectResolution {
+		mode |= parser.SkipObjectResolution
+	}
+	return mode
+}
+
+// ParseExpr parses and decorates a Go expression. The expression is parsed inside a throwaway
+// file, so comments in src are attached to the returned nodes.
+func (d *Decorator) ParseExpr(src string) (dst.Expr, error) {
+
+	// parse the expression on its own first, so any errors refer to positions in src
+	if _, err := parser.ParseExprFrom(token.NewFileSet(), "", src, 0); err != nil {
+		return nil, err
+	}
+
+	f, err := d.parseFragment("package p\nvar _ = ", src, "\n")
+	if err != nil {
+		return nil, err
+	}
+	gd, ok := f.Decls[0].(*dst.GenDecl)
+	if !ok || len(gd.Specs) != 1 || len(gd.Specs[0].(*dst.ValueSpec).Values) != 1 {
+		return nil, fmt.Errorf("src is not a single expression: %q", src)
+	}
+	return gd.Specs[0].(*dst.ValueSpec).Values[0], nil
+}
+
+// ParseStmts parses and decorates a list of Go statements. The statements are parsed inside the
+// body of a throwaway function, so comments in src are attached to the returned nodes.
+func (d *Decorator) ParseStmts(src string) ([]dst.Stmt, error) {
+	f, err := d.parseFragment("package p; func _() {\n", src, "\n}")
+	if err != nil {
+		return nil, err
+	}
+	fd, ok := f.Decls[0].(*dst.FuncDecl)
+	if !ok {
+		return nil, fmt.Errorf("src is not a list of statements: %q", src)
+	}
+	list := fd.Body.List
+	fd.Body.List = nil
+	return list, nil
+}
+
+// SetBodyFromSource parses and decorates a list of Go statements, and sets them as the body of fn,
+// replacing any existing body. The statements are parsed inside the body of a throwaway function,
+// so comments in src (including comments after the last statement) are kept in the body. A
+// FuncDecl without a body (e.g. an external function) is given one.
+func (d *Decorator) SetBodyFromSource(fn *dst.FuncDecl, src string) error {
+	f, err := d.parseFragment("package p; func _() {\n", src, "\n}")
+	if err != nil {
+		return err
+	}
+	fd, ok := f.Decls[0].(*dst.FuncDecl)
+	if !ok || len(f.Decls) != 1 {
+		return fmt.Errorf("src is not a list of statements: %q", src)
+	}
+	fn.Body = fd.Body
+	fd.Body = nil
+	return nil
+}
+
+// parseFragment parses src wrapped in prefix and suffix, which must form a file containing a single
+// declaration. Line numbers in parse errors are adjusted to refer to src.
+func (d *Decorator) parseFragment(prefix, src, suffix string) (*dst.File, error) {
+	offset := strings.Count(prefix, "\n")
+	f, err := d.ParseFile("", prefix+src+suffix, parser.ParseComments)
+	if err != nil {
+		if list, ok := err.(scanner.ErrorList); ok {
+			for _, e := range list {
+				e.Pos.Line -= offset
+			}
+		}
+		return nil, err
+	}
+	if len(f.Decls) != 1 {
+		return nil, fmt.Errorf("src is not a valid fragment: %q", src)
+	}
+	return f, nil
+}
+
 // DecorateFile decorates *ast.File and returns *dst.File
 func (d *Decorator) DecorateFile(f *ast.File) (*dst.File, error) {
 	file, err := d.DecorateNode(f)
@@ -142,15 +279,44 @@ func (d *Decorator) DecorateNode(n ast.Node) (dst.Node, error) {
 	switch n := n.(type) {
 	case *ast.Package:
 		for k, v := range n.Files {
-			d.Filenames[d.Dst.Nodes[v].(*dst.File)] = k
+			file := d.Dst.Nodes[v].(*dst.File)
+			d.Filenames[file] = k
+			d.recordFinalNewlines(file, v)
+			bindExportDirectives(file, d.PinFuncDirectives)
+			if d.PinGenerateDirectives {
+				pinGenerateDirectives(file)
+			}
+			if d.DetectDuplicateImports {
+				d.detectDuplicateImports(v)
+			}
 		}
 	case *ast.File:
 		d.Filenames[out.(*dst.File)] = d.Fset.File(n.Pos()).Name()
+		d.recordFinalNewlines(out.(*dst.File), n)
+		bindExportDirectives(out.(*dst.File), d.PinFuncDirectives)
+		if d.PinGenerateDirectives {
+			pinGenerateDirectives(out.(*dst.File))
+		}
+		if d.DetectDuplicateImports {
+			d.detectDuplicateImports(n)
+		}
 	}
 
 	return out, nil
 }
 
+// recordFinalNewlines adds the number of newlines at the end of f to FinalNewlines.
+func (d *Decorator) recordFinalNewlines(file *dst.File, f *ast.File) {
+	tf := d.Fset.File(f.Pos())
+	if tf == nil {
+		return
+	}
+	if d.FinalNewlines == nil {
+		d.FinalNewlines = map[*dst.File]int{}
+	}
+	d.FinalNewlines[file] = finalNewlines(tf, f)
+}
+
 func (pd *Decorator) newFileDecorator() *fileDecorator {
 	return &fileDecorator{
 		Decorator:    pd,
@@ -326,35 +492,15 @@ func (f *fileDecorator) resolvePath(force bool, parent ast.Node, parentName, par
 		return "", err
 	}
 
-	path = stripVendor(path)
+	path = resolver.StripVendor(path)
 
-	if path == stripVendor(f.Path) {
+	if path == resolver.StripVendor(f.Path) {
 		return "", nil
 	}
 
 	return path, nil
 }
 
-func stripVendor(path string) string {
-	findVendor := func(path string) (index int, ok bool) {
-		// Two cases, depending on internal at start of string or not.
-		// The order matters: we must return the index of the final element,
-		// because the final one is where the effective import path starts.
-		switch {
-		case strings.Contains(path, "/vendor/"):
-			return strings.LastIndex(path, "/vendor/") + 1, true
-		case strings.HasPrefix(path, "vendor/"):
-			return 0, true
-		}
-		return 0, false
-	}
-	i, ok := findVendor(path)
-	if !ok {
-		return path
-	}
-	return path[i+len("vendor/"):]
-}
-
 func (f *fileDecorator) decorateObject(o *ast.Object) (*dst.Object, error) {
 
 	if o == nil {
@@ -490,6 +636,10 @@ func debug(w io.Writer, file dst.Node) {
 			out += " [New line before]"
 		case dst.EmptyLine:
 			out += " [Empty line before]"
+		default:
+			if before > dst.EmptyLine {
+				out += fmt.Sprintf(" [%d empty lines before]", before-1)
+			}
 		}
 		for _, point := range points {
 			if len(point.Decs) > 0 {
@@ -508,6 +658,10 @@ func debug(w io.Writer, file dst.Node) {
 			out += " [New line after]"
 		case dst.EmptyLine:
 			out += " [Empty line after]"
+		default:
+			if after > dst.EmptyLine {
+				out += fmt.Sprintf(" [%d empty lines after]", after-1)
+			}
 		}
 		if out != "" {
 			result += nodeType(n) + out + "\n"
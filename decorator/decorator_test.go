@@ -2,13 +2,19 @@ package decorator
 
 import (
 	"bytes"
+	"go/ast"
 	"go/format"
 	"go/parser"
+	"go/scanner"
 	"go/token"
 	"path/filepath"
 	"regexp"
 	"strings"
 	"testing"
+
+	"github.com/dave/dst"
+	"github.com/dave/dst/decorator/resolver/goast"
+	"github.com/dave/dst/decorator/resolver/guess"
 )
 
 func TestDecorator(t *testing.T) {
@@ -431,6 +437,219 @@ KeyValueExpr [New line before] [New line after]`,
 			expect: `FuncDecl [Empty line before] [Start "// FuncDecl"] [Func "/*FuncDeclDoc*/"] [Recv "/*FuncDeclRecv*/"] [Name "/*FuncDeclName*/"] [Params "/*FuncDeclParams*/"] [Results "/*FuncDeclType*/"]
 BlockStmt [Lbrace "\n"]`,
 		},
+		{
+			name: "range-int",
+			code: `package a
+
+				func main() {
+					for i /* a */ := range /* b */ 10 /* c */ {
+					}
+				}`,
+			expect: `FuncDecl [Empty line before]
+RangeStmt [New line before] [Key "/* a */"] [Range "/* b */"] [X "/* c */"] [New line after]
+BlockStmt [Lbrace "\n"]`,
+		},
+		{
+			name: "type-switch-guard",
+			code: `package a
+
+func a(i interface{}) {
+	switch /* a */ x := /* b */ i. /* c */ (type /* d */) /* e */ {
+	/* f */ case /* g */ int /* h */, string: // i
+	}
+}`,
+			expect: `FuncDecl [Empty line before]
+TypeSwitchStmt [New line before] [Switch "/* a */"] [Assign "/* e */"] [New line after]
+AssignStmt [Tok "/* b */"]
+TypeAssertExpr [X "/* c */"] [Type "/* d */"]
+CaseClause [New line before] [Start "/* f */"] [Case "/* g */"] [End "// i"] [New line after]
+Ident [End "/* h */"]`,
+		},
+		{
+			name: "case-clause-comments",
+			code: `package a
+
+func a(i int, c chan int) {
+	switch i {
+	case 1: // a
+		// b
+		print(i)
+	case 2:
+		// c
+	case 3: /* d */
+	}
+	select {
+	case <-c: // e
+	case c <- i:
+		// f
+	}
+}`,
+			expect: `FuncDecl [Empty line before]
+SwitchStmt [New line before] [New line after]
+CaseClause [New line before] [Colon "// a"] [New line after]
+ExprStmt [New line before] [Start "// b"]
+CaseClause [New line before] [End "\n" "// c"] [New line after]
+CaseClause [New line before] [End "/* d */"] [New line after]
+SelectStmt [New line before] [New line after]
+CommClause [New line before] [End "// e"] [New line after]
+CommClause [New line before] [End "\n" "// f"] [New line after]`,
+		},
+		{
+			name: "select-clause-order",
+			code: `package a
+
+func a(c, d chan int, e chan<- int) {
+	select /* a */ {
+	// b
+	case v := <-d: // c
+		print(v)
+
+	// d
+	case /* e */ e <- 1 /* f */ :
+	default: // g
+	}
+}`,
+			expect: `FuncDecl [Empty line before]
+SelectStmt [New line before] [Select "/* a */"] [New line after]
+CommClause [New line before] [Start "// b"] [Colon "// c"] [Empty line after]
+ExprStmt [New line before]
+CommClause [Empty line before] [Start "// d"] [Case "/* e */"] [Comm "/* f */"] [New line after]
+CommClause [New line before] [End "// g"] [New line after]`,
+		},
+		{
+			name: "composite-lit-trailing-comments",
+			code: `package a
+
+var c = Config{
+	Foo: 1, // a
+	Bar: 2,
+	Baz: []int{
+		3, // b
+	}, // c
+}`,
+			expect: `GenDecl [Empty line before]
+KeyValueExpr [New line before] [End "// a"] [New line after]
+KeyValueExpr [New line before] [New line after]
+KeyValueExpr [New line before] [End "// c"] [New line after]
+BasicLit [New line before] [End "// b"] [New line after]`,
+		},
+		{
+			name: "labels-and-gotos",
+			code: `package a
+
+func a(n int) {
+	// a
+outer: // b
+	for {
+		break /* c */ outer // d
+	}
+loop:
+	/* e */
+	n++
+	goto loop
+}`,
+			expect: `FuncDecl [Empty line before]
+LabeledStmt [New line before] [Start "// a"] [Colon "// b"] [New line after]
+ForStmt [New line before]
+BranchStmt [New line before] [Tok "/* c */"] [End "// d"] [New line after]
+LabeledStmt [New line before] [New line after]
+IncDecStmt [New line before] [Start "/* e */" "\n"]
+BranchStmt [New line before] [New line after]`,
+		},
+		{
+			name: "chan-comments",
+			code: `package a
+
+var a <-chan /* a */ int
+var b chan /* b */ <- /* c */ int
+
+func d(ch chan int, v int) {
+	ch /* e */ <- /* f */ v
+	v = <- /* g */ ch
+}`,
+			expect: `GenDecl [Empty line before] [New line after]
+ChanType [Begin "/* a */"]
+GenDecl [New line before] [Empty line after]
+ChanType [Begin "/* b */"] [Arrow "/* c */"]
+FuncDecl [Empty line before]
+SendStmt [New line before] [Chan "/* e */"] [Arrow "/* f */"] [New line after]
+AssignStmt [New line before] [New line after]
+UnaryExpr [Op "/* g */"]`,
+		},
+		{
+			name: "variadic-spread",
+			code: `package a
+
+func a(a, b []int) {
+	a = append(a, b...) // c
+	a = append(a, b /* d */ ... /* e */)
+}`,
+			expect: `FuncDecl [Empty line before]
+AssignStmt [New line before] [End "// c"] [New line after]
+AssignStmt [New line before] [New line after]
+CallExpr [Ellipsis "/* e */"]
+Ident [End "/* d */"]`,
+		},
+		{
+			name: "interface-type-sets",
+			code: `package a
+
+type A interface {
+	~int /* a */ | /* b */ ~ /* c */ int64 | // d
+		float64 // e
+}`,
+			expect: `GenDecl [Empty line before]
+Field [New line before] [End "// e"] [New line after]
+BinaryExpr [Op "// d"]
+BinaryExpr [X "/* a */"] [Op "/* b */"]
+UnaryExpr [Op "/* c */"]
+Ident [New line before]`,
+		},
+		{
+			name: "package-clause-comment",
+			code: `// a
+package main // build: special
+
+func main() {}`,
+			expect: `File [Start "// a"] [Name "// build: special"]
+FuncDecl [Empty line before]`,
+		},
+		{
+			name: "array-type-forms",
+			code: `package main
+
+var a = [ /* a */ ...] /* b */ int{1, 2}
+var b [ /* c */ N] /* d */ int
+var c [] /* e */ int
+var d [2 * /* f */ N]int`,
+			expect: `GenDecl [Empty line before] [New line after]
+ArrayType [Lbrack "/* a */"] [Len "/* b */"]
+GenDecl [New line before] [New line after]
+ArrayType [Lbrack "/* c */"] [Len "/* d */"]
+GenDecl [New line before] [New line after]
+ArrayType [Len "/* e */"]
+GenDecl [New line before]
+BinaryExpr [Op "/* f */"]`,
+		},
+		{
+			name: "variadic-ellipsis",
+			code: `package main
+
+func a(b /* a */ ... /* b */ int) {}
+
+func c(d int, e /* c */ ... /* d */ []string /* e */) {}
+
+var f func( /* f */ ...interface{})`,
+			expect: `FuncDecl [Empty line before] [Empty line after]
+Ident [End "/* a */"]
+Ellipsis [Ellipsis "/* b */"]
+FuncDecl [Empty line before] [Empty line after]
+Field [End "/* e */"]
+Ident [End "/* c */"]
+Ellipsis [Ellipsis "/* d */"]
+GenDecl [Empty line before]
+FieldList [Opening "/* f */"]`,
+		},
 	}
 	var solo bool
 	for _, test := range tests {
@@ -499,7 +718,7 @@ func TestParseFile_Comments(t *testing.T) {
 
 func TestBad(t *testing.T) {
 	tests := []struct {
-		name, code string
+		name, code, expect string
 	}{
 		{
 			name: "decl",
@@ -507,8 +726,10 @@ func TestBad(t *testing.T) {
 
 				%BADDECL%
 			`,
+			expect: "package a\n\n%BADDECL%\n",
 		},
 		{
+			// the parser includes the closing brace of the block in the bad statement
 			name: "stmt",
 			code: `package a
 
@@ -516,6 +737,7 @@ func TestBad(t *testing.T) {
 					%BADSTMT%
 				}
 			`,
+			expect: "package a\n\nfunc a() {\n\t%BADSTMT%\n\t\t\t\t}\n}\n",
 		},
 		{
 			name: "expr",
@@ -525,6 +747,7 @@ func TestBad(t *testing.T) {
 					var a = %BADEXPR%
 				}
 			`,
+			expect: "package a\n\nfunc a() {\n\tvar a = %BADEXPR%\n\t\t\t\t}\n}\n",
 		},
 	}
 	for _, test := range tests {
@@ -544,16 +767,85 @@ func TestBad(t *testing.T) {
 				t.Fatal("expected error, found none")
 			}
 
+			// the source of the bad nodes is restored in place of the placeholders
 			dbuf := &bytes.Buffer{}
 			if err := Fprint(dbuf, df); err != nil {
 				t.Fatal(err)
 			}
+			compare(t, test.expect, dbuf.String())
 
+			// without the source, the placeholders are printed
+			df, err = DecorateFile(fset, af)
+			if err != nil {
+				t.Fatal(err)
+			}
+			dbuf.Reset()
+			if err := Fprint(dbuf, df); err != nil {
+				t.Fatal(err)
+			}
 			compare(t, abuf.String(), dbuf.String())
 		})
 	}
 }
 
+func TestBadSource(t *testing.T) {
+	code := "package a\n\nvar a = 1 + )\n\nvar b = 2\n"
+	f, err := Parse(code)
+	if err == nil {
+		t.Fatal("expected error, found none")
+	}
+	bad := f.Decls[0].(*dst.GenDecl).Specs[0].(*dst.ValueSpec).Values[0].(*dst.BinaryExpr).Y.(*dst.BadExpr)
+	if bad.Source != ")" {
+		t.Errorf("expected source %q, found %q", ")", bad.Source)
+	}
+	buf := &bytes.Buffer{}
+	if err := Fprint(buf, f); err != nil {
+		t.Fatal(err)
+	}
+	compare(t, code, buf.String())
+}
+
+func TestBadDeclSource(t *testing.T) {
+	code := "package a\n\nvar a = 1\n\n// b\n%b%\n\nvar c = 2\n"
+	d := NewDecorator(token.NewFileSet())
+	f, err := d.Parse(code)
+	if err == nil {
+		t.Fatal("expected error, found none")
+	}
+	if len(d.sources) != 0 {
+		t.Errorf("expected sources to be released, found %d", len(d.sources))
+	}
+	bad := f.Decls[1].(*dst.BadDecl)
+	if bad.Source != "%b%" {
+		t.Errorf("expected source %q, found %q", "%b%", bad.Source)
+	}
+	buf := &bytes.Buffer{}
+	if err := Fprint(buf, f); err != nil {
+		t.Fatal(err)
+	}
+	compare(t, code, buf.String())
+
+	// when the file is restored without printing, a *ast.BadDecl is used
+	_, af, err := RestoreFile(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := af.Decls[1].(*ast.BadDecl); !ok {
+		t.Errorf("expected *ast.BadDecl, found %T", af.Decls[1])
+	}
+
+	// text that looks like the markers is kept
+	code = "package a\n\n// dst_bad_0_0 dst_bad_1_0\nvar a = 1\n\n%b%\n"
+	if f, err = Parse(code); err == nil {
+		t.Fatal("expected error, found none")
+	}
+	buf.Reset()
+	if err := Fprint(buf, f); err != nil {
+		t.Fatal(err)
+	}
+	compare(t, code, buf.String())
+}
+
 func TestDecorator_ParseDir(t *testing.T) {
 
 	code := map[string]string{
@@ -600,3 +892,171 @@ func normalize(s string) string {
 	s = strings.TrimSpace(s)
 	return s
 }
+
+func TestParseExpr(t *testing.T) {
+	expr, err := ParseExpr("a + b*c")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := expr.(*dst.BinaryExpr); !ok {
+		t.Fatalf("expected *dst.BinaryExpr, found %T", expr)
+	}
+
+	f, err := Parse("package a\n\nvar v = 1\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Decls[0].(*dst.GenDecl).Specs[0].(*dst.ValueSpec).Values[0] = expr
+
+	buf := &bytes.Buffer{}
+	if err := Fprint(buf, f); err != nil {
+		t.Fatal(err)
+	}
+	compare(t, "package a\n\nvar v = a + b*c\n", buf.String())
+
+	if _, err := ParseExpr("a +"); err == nil {
+		t.Error("expected error, found none")
+	}
+}
+
+func TestParseStmts(t *testing.T) {
+	stmts, err := ParseStmts("a := 1 // a\n\n// b\nprintln(a)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stmts) != 2 {
+		t.Fatalf("expected 2 statements, found %d", len(stmts))
+	}
+
+	f, err := Parse("package a\n\nfunc main() {}\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Decls[0].(*dst.FuncDecl).Body.List = stmts
+
+	buf := &bytes.Buffer{}
+	if err := Fprint(buf, f); err != nil {
+		t.Fatal(err)
+	}
+	compare(t, "package a\n\nfunc main() {\n\ta := 1 // a\n\n\t// b\n\tprintln(a)\n}\n", buf.String())
+
+	_, err = ParseStmts("a := 1\nb := )")
+	if err == nil {
+		t.Fatal("expected error, found none")
+	}
+	if list, ok := err.(scanner.ErrorList); !ok || list[0].Pos.Line != 2 {
+		t.Errorf("expected error on line 2, found %v", err)
+	}
+
+	if _, err := ParseStmts("}\nfunc b() {"); err == nil {
+		t.Error("expected error, found none")
+	}
+}
+
+func TestSetBodyFromSource(t *testing.T) {
+	f, err := Parse("package a\n\n// A does things.\nfunc A(s string) {}\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fn := f.Decls[0].(*dst.FuncDecl)
+	if err := SetBodyFromSource(fn, "a := len(s) // a\nprintln(a)\n// done"); err != nil {
+		t.Fatal(err)
+	}
+	buf := &bytes.Buffer{}
+	if err := Fprint(buf, f); err != nil {
+		t.Fatal(err)
+	}
+	compare(t, "package a\n\n// A does things.\nfunc A(s string) {\n\ta := len(s) // a\n\tprintln(a)\n\t// done\n}\n", buf.String())
+
+	for _, src := range []string{"a := )", "}\nfunc b() {"} {
+		if err := SetBodyFromSource(fn, src); err == nil {
+			t.Errorf("%q: expected error, found none", src)
+		}
+	}
+}
+
+func TestSkipObjectResolution(t *testing.T) {
+	code := `package a
+
+import "fmt"
+
+// A prints.
+func A(s string) {
+	// b
+	b := s
+	fmt.Println(b) // c
+}
+`
+	d := NewDecoratorWithImports(token.NewFileSet(), "a", goast.New())
+	d.SkipObjectResolution = true
+	f, err := d.Parse(code)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.Scope != nil {
+		t.Error("expected no file scope")
+	}
+	var paths []string
+	dst.Inspect(f, func(n dst.Node) bool {
+		if id, ok := n.(*dst.Ident); ok {
+			if id.Obj != nil {
+				t.Errorf("unexpected Obj for %s", id.Name)
+			}
+			if id.Path != "" {
+				paths = append(paths, id.Path+"."+id.Name)
+			}
+		}
+		return true
+	})
+	if len(paths) != 1 || paths[0] != "fmt.Println" {
+		t.Errorf("unexpected remote identifiers %q", paths)
+	}
+
+	buf := &bytes.Buffer{}
+	if err := NewRestorerWithImports("a", guess.New()).Fprint(buf, f); err != nil {
+		t.Fatal(err)
+	}
+	compare(t, code, buf.String())
+}
+
+func TestDetectDuplicateImports(t *testing.T) {
+	code := `package a
+
+import (
+	"fmt"
+	x "strings"
+	x "bytes"
+	_ "embed"
+	_ "embed"
+)
+
+import "fmt"
+
+var _ = fmt.Sprint
+`
+	d := NewDecorator(token.NewFileSet())
+	d.DetectDuplicateImports = true
+	if _, err := d.ParseFile("a.go", code, parser.ParseComments); err != nil {
+		t.Fatal(err)
+	}
+	var found []string
+	for _, w := range d.Warnings {
+		found = append(found, w.String())
+	}
+	expect := []string{
+		`a.go:6:2: import alias x used for "strings" and "bytes"`,
+		`a.go:8:2: duplicate import "embed" (previous import at a.go:7:2)`,
+		`a.go:11:8: duplicate import "fmt" (previous import at a.go:4:2)`,
+	}
+	if strings.Join(found, "\n") != strings.Join(expect, "\n") {
+		t.Errorf("\nexpect: %q\nfound : %q", expect, found)
+	}
+
+	d = NewDecorator(token.NewFileSet())
+	if _, err := d.Parse(code); err != nil {
+		t.Fatal(err)
+	}
+	if len(d.Warnings) != 0 {
+		t.Errorf("expected no warnings, found %v", d.Warnings)
+	}
+}
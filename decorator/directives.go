@@ -0,0 +1,123 @@
+package decorator
+
+import (
+	"strings"
+
+	"github.com/dave/dst"
+	"github.com/dave/dst/dstutil"
+)
+
+// exportDirective returns the function name if text is a cgo "//export" directive.
+func exportDirective(text string) (name string, ok bool) {
+	if !strings.HasPrefix(text, "//export ") {
+		return "", false
+	}
+	fields := strings.Fields(strings.TrimPrefix(text, "//export "))
+	if len(fields) == 0 {
+		return "", false
+	}
+	return fields[0], true
+}
+
+// isFuncDirective returns true if text is a cgo "//export" directive for fd, or (if pragmas is
+// set) a compiler directive for a function (see dstutil.IsPragma).
+func isFuncDirective(text string, fd *dst.FuncDecl, pragmas bool) bool {
+	if name, ok := exportDirective(text); ok {
+		return name == fd.Name.Name
+	}
+	return pragmas && dstutil.IsPragma(text)
+}
+
+// bindExportDirectives ensures cgo "//export" directives, and compiler directives (e.g.
+// "//go:noinline") if pragmas is set, are attached to the Start decorations of the function they
+// refer to. A directive with a hanging indent is attached to the End decorations of the previous
+// declaration by the decorator, so would become detached from its function when the declarations
+// are rearranged.
+func bindExportDirectives(file *dst.File, pragmas bool) {
+	for i := 1; i < len(file.Decls); i++ {
+		fd, ok := file.Decls[i].(*dst.FuncDecl)
+		if !ok {
+			continue
+		}
+		prev := file.Decls[i-1].Decorations()
+		end := len(prev.End)
+		for end > 0 && isFuncDirective(prev.End[end-1], fd, pragmas) {
+			end--
+		}
+		if end == len(prev.End) {
+			continue
+		}
+		directives := append(dst.Decorations{}, prev.End[end:]...)
+		// breaks is the number of line breaks between the previous declaration and the directives
+		var breaks int
+		for end > 0 && prev.End[end-1] == "\n" {
+			end--
+			breaks++
+		}
+		if end > 0 && strings.HasPrefix(prev.End[end-1], "//") {
+			// a line comment ends with a line break
+			breaks++
+		}
+		prev.End = prev.End[:end]
+		if breaks > 0 {
+			// keep the empty lines before and after the directives
+			if prev.After == dst.EmptyLine {
+				directives = append(directives, "\n")
+			}
+			prev.After = dst.NewLine
+			if breaks > 1 {
+				prev.After = dst.EmptyLine
+			}
+			fd.Decs.Before = prev.After
+		}
+		fd.Decs.Start.Prepend(directives...)
+		if prev.After == dst.None {
+			prev.After = dst.NewLine
+		}
+		if fd.Decs.Before == dst.None {
+			fd.Decs.Before = dst.NewLine
+		}
+	}
+}
+
+// pinGenerateDirectives moves "//go:generate" directives on their own line in the End decorations
+// of a declaration to the Start decorations of the following declaration. The decorator only
+// attaches a comment on its own line to the previous declaration when an empty line separates it
+// from the following declaration. Directives after the last declaration are moved to the End
+// decorations of the file, so they stay at the end of the file.
+func pinGenerateDirectives(file *dst.File) {
+	for i, decl := range file.Decls {
+		decs := decl.Decorations()
+		// line is the index of the line break that ends the line of the declaration
+		line := -1
+		for j, d := range decs.End {
+			if d == "\n" {
+				line = j
+				break
+			}
+		}
+		if line < 0 || !hasGenerateDirective(decs.End[line:]) {
+			continue
+		}
+		moved := append(dst.Decorations{}, decs.End[line:]...)
+		decs.End = decs.End[:line]
+		if i == len(file.Decls)-1 {
+			file.Decs.End.Append(moved...)
+			continue
+		}
+		next := file.Decls[i+1].Decorations()
+		start := append(moved[1:], "\n")
+		next.Start.Replace(append(start, next.Start...)...)
+		decs.After, next.Before = dst.NewLine, dst.NewLine
+	}
+}
+
+// hasGenerateDirective returns true if decs contains a "//go:generate" directive.
+func hasGenerateDirective(decs dst.Decorations) bool {
+	for _, d := range decs {
+		if strings.HasPrefix(d, "//go:generate ") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,98 @@
+package decorator
+
+import (
+	"strings"
+
+	"github.com/dave/dst"
+)
+
+// formatDocComments reformats the package doc and the doc comments of the top-level declarations
+// in the same way as gofmt. The result maps each node with a reformatted doc comment to its new
+// Start decorations, which are used by the restorer. file is not modified.
+func formatDocComments(file *dst.File) map[dst.Node]dst.Decorations {
+	formatted := map[dst.Node]dst.Decorations{}
+	nodes := []dst.Node{file}
+	for _, decl := range file.Decls {
+		nodes = append(nodes, decl)
+	}
+	for _, n := range nodes {
+		if decs, ok := formatDocComment(n.Decorations().Start); ok {
+			formatted[n] = decs
+		}
+	}
+	return formatted
+}
+
+// formatDocComment reformats the doc comment at the end of the Start decorations of a node. The
+// doc comment is the group of line comments directly preceding the node with no empty line
+// between them. Directives (e.g. "//go:generate") are moved to the end of the comment. The
+// decorations are returned in a new slice, and ok is false if there is no doc comment to reformat.
+func formatDocComment(decs dst.Decorations) (out dst.Decorations, ok bool) {
+	start := len(decs)
+	for start > 0 && strings.HasPrefix(decs[start-1], "//") {
+		start--
+	}
+	if start == len(decs) {
+		return nil, false
+	}
+
+	var text strings.Builder
+	var directives []string
+	for _, c := range decs[start:] {
+		c = strings.TrimPrefix(c, "//")
+		if isDirective(c) {
+			directives = append(directives, "//"+c)
+			continue
+		}
+		text.WriteString(strings.TrimPrefix(c, " "))
+		text.WriteString("\n")
+	}
+	if text.Len() == 0 {
+		return nil, false
+	}
+	formatted, ok := formatDocText(text.String())
+	if !ok {
+		return nil, false
+	}
+
+	out = append(dst.Decorations{}, decs[:start]...)
+	for _, line := range strings.Split(formatted, "\n") {
+		switch {
+		case line == "":
+			out = append(out, "//")
+		case strings.HasPrefix(line, "\t"):
+			out = append(out, "//"+line)
+		default:
+			out = append(out, "// "+line)
+		}
+	}
+	if len(directives) > 0 {
+		out = append(out, "//")
+		out = append(out, directives...)
+	}
+	return out, true
+}
+
+// isDirective reports whether c is a comment directive, with the leading "//" removed. See
+// go/ast.isDirective.
+func isDirective(c string) bool {
+	// "//line " is a line directive, "//extern " is for gccgo and "//export " is for cgo.
+	if strings.HasPrefix(c, "line ") || strings.HasPrefix(c, "extern ") || strings.HasPrefix(c, "export ") {
+		return true
+	}
+	// "//[a-z0-9]+:[a-z0-9]"
+	colon := strings.Index(c, ":")
+	if colon <= 0 || colon+1 >= len(c) {
+		return false
+	}
+	for i := 0; i <= colon+1; i++ {
+		if i == colon {
+			continue
+		}
+		b := c[i]
+		if !('a' <= b && b <= 'z' || '0' <= b && b <= '9') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,16 @@
+//go:build go1.19
+// +build go1.19
+
+package decorator
+
+import (
+	"go/doc/comment"
+	"strings"
+)
+
+// formatDocText reformats the text of a doc comment with the go/doc/comment formatter.
+func formatDocText(text string) (string, bool) {
+	var p comment.Parser
+	var pr comment.Printer
+	return strings.TrimSuffix(string(pr.Comment(p.Parse(text))), "\n"), true
+}
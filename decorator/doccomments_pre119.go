@@ -0,0 +1,10 @@
+//go:build !go1.19
+// +build !go1.19
+
+package decorator
+
+// formatDocText returns false, because go/doc/comment (and the reformatting of doc comments by
+// gofmt) is only available in Go 1.19 and later.
+func formatDocText(text string) (string, bool) {
+	return "", false
+}
@@ -0,0 +1,173 @@
+package decorator
+
+import (
+	"go/ast"
+	"go/token"
+	"reflect"
+
+	"github.com/dave/dst"
+	"golang.org/x/tools/go/ast/astutil"
+)
+
+// EmptyInterfaceStyle controls how empty interface types are restored.
+type EmptyInterfaceStyle int
+
+const (
+	// EmptyInterfacePreserve restores "interface{}" and "any" exactly as they are in the tree.
+	EmptyInterfacePreserve EmptyInterfaceStyle = iota
+	// EmptyInterfaceAny restores empty interface types as "any", which is only predeclared from go
+	// 1.18, so the restored file needs go 1.18 or later. Interface types with comments inside the
+	// braces are left unchanged, and nothing is converted in a file that declares a name "any"
+	// (excluding fields and methods), which would shadow the predeclared identifier. Declarations in
+	// other files of the package can't be detected.
+	EmptyInterfaceAny
+	// EmptyInterfaceInterface restores the predeclared identifier "any" as "interface{}". An
+	// identifier is assumed to be the predeclared "any" if it has no Path and no Obj, and isn't a
+	// declared name (including fields, parameters and methods), a selector, a key in a composite
+	// literal or a label. The identifiers of a file decorated without object resolution have no Obj,
+	// and identifiers declared in other files of the package can't be detected, so a reference to
+	// a local declaration named "any" may be converted.
+	EmptyInterfaceInterface
+)
+
+// applyEmptyInterfaceStyle updates the empty interface types in a restored file. The comments are
+// needed to detect interface types with comments inside the braces.
+func (r *FileRestorer) applyEmptyInterfaceStyle(f *ast.File) {
+	switch r.EmptyInterfaceStyle {
+	case EmptyInterfaceAny:
+		if declaresAny(f) {
+			return
+		}
+		astutil.Apply(f, nil, func(c *astutil.Cursor) bool {
+			it, ok := c.Node().(*ast.InterfaceType)
+			if !ok || it.Methods == nil || len(it.Methods.List) > 0 || r.hasComments(it) {
+				return true
+			}
+			c.Replace(&ast.Ident{NamePos: it.Interface, Name: "any"})
+			return true
+		})
+	case EmptyInterfaceInterface:
+		astutil.Apply(f, nil, func(c *astutil.Cursor) bool {
+			id, ok := c.Node().(*ast.Ident)
+			if !ok || id.Name != "any" {
+				return true
+			}
+			if d, ok := r.Dst.Nodes[id].(*dst.Ident); !ok || d.Path != "" || d.Obj != nil {
+				return true
+			}
+			if !isPredeclaredPosition(c) {
+				return true
+			}
+			c.Replace(&ast.InterfaceType{
+				Interface: id.NamePos,
+				Methods:   &ast.FieldList{Opening: id.NamePos + 9, Closing: id.NamePos + 10},
+			})
+			return true
+		})
+	}
+}
+
+// interfaceType is the type of the node that replaces an "any" identifier.
+var interfaceType = reflect.TypeOf(&ast.InterfaceType{})
+
+// isPredeclaredPosition returns true if the identifier at the cursor may refer to a predeclared
+// identifier, and can be replaced by an interface type: it isn't a declared name (including
+// fields, parameters and methods), a selector, a key in a composite literal or a label.
+func isPredeclaredPosition(c *astutil.Cursor) bool {
+	switch p := c.Parent().(type) {
+	case *ast.Field, *ast.ValueSpec:
+		if c.Name() == "Names" {
+			return false
+		}
+	case *ast.AssignStmt:
+		if p.Tok == token.DEFINE && c.Name() == "Lhs" {
+			return false
+		}
+	case *ast.RangeStmt:
+		if p.Tok == token.DEFINE && (c.Name() == "Key" || c.Name() == "Value") {
+			return false
+		}
+	case *ast.KeyValueExpr:
+		if c.Name() == "Key" {
+			return false
+		}
+	}
+	switch c.Name() {
+	case "Sel", "Name", "Label":
+		return false
+	}
+	// the field must accept an expression other than an identifier
+	field := reflect.ValueOf(c.Parent()).Elem().FieldByName(c.Name())
+	if !field.IsValid() {
+		return false
+	}
+	t := field.Type()
+	if c.Index() >= 0 {
+		t = t.Elem()
+	}
+	return interfaceType.AssignableTo(t)
+}
+
+// declaresAny returns true if f declares a name "any" in any scope. The names of fields and
+// methods are not in a scope, so are ignored.
+func declaresAny(f *ast.File) bool {
+	var found bool
+	names := func(ids ...*ast.Ident) {
+		for _, id := range ids {
+			if id != nil && id.Name == "any" {
+				found = true
+			}
+		}
+	}
+	fields := func(list *ast.FieldList) {
+		if list == nil {
+			return
+		}
+		for _, field := range list.List {
+			names(field.Names...)
+		}
+	}
+	ast.Inspect(f, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.ImportSpec:
+			names(n.Name)
+		case *ast.ValueSpec:
+			names(n.Names...)
+		case *ast.TypeSpec:
+			names(n.Name)
+		case *ast.FuncDecl:
+			if n.Recv == nil {
+				names(n.Name)
+			}
+			fields(n.Recv)
+		case *ast.FuncType:
+			fields(n.Params)
+			fields(n.Results)
+		case *ast.AssignStmt:
+			if n.Tok == token.DEFINE {
+				for _, e := range n.Lhs {
+					id, _ := e.(*ast.Ident)
+					names(id)
+				}
+			}
+		case *ast.RangeStmt:
+			if n.Tok == token.DEFINE {
+				key, _ := n.Key.(*ast.Ident)
+				value, _ := n.Value.(*ast.Ident)
+				names(key, value)
+			}
+		}
+		return !found
+	})
+	return found
+}
+
+// hasComments returns true if any of the comments of the file are inside n.
+func (r *FileRestorer) hasComments(n ast.Node) bool {
+	for _, cg := range r.comments {
+		if cg.Pos() >= n.Pos() && cg.End() <= n.End() {
+			return true
+		}
+	}
+	return false
+}
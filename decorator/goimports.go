@@ -0,0 +1,73 @@
+package decorator
+
+import (
+	"go/token"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/dave/dst"
+	"github.com/dave/dst/decorator/resolver"
+	"github.com/dave/dst/decorator/resolver/goast"
+	"github.com/dave/dst/decorator/resolver/guess"
+	"github.com/dave/dst/dstutil"
+)
+
+// FprintImports restores f and prints it to w in the same way as goimports: unused imports are
+// removed, imports are added for identifiers with Path set, and the specs of each import block are
+// sorted and grouped with standard library packages first, separated from other packages by an
+// empty line. Qualified identifiers (e.g. fmt.Println) are matched to the imports of the file, so
+// the file doesn't need to be decorated with import management. r resolves package names, and
+// guess.New() is used if r is nil. The package name is used as the local package path. f is not
+// modified.
+func FprintImports(w io.Writer, f *dst.File, r resolver.RestorerResolver) error {
+	if r == nil {
+		r = guess.New()
+	}
+	file := dst.Clone(f).(*dst.File)
+	if err := dstutil.CollapseQualifiedSelectors(file, goast.WithResolver(r)); err != nil {
+		return err
+	}
+	// the first restore updates the import specs of the file, so they can be grouped
+	if _, err := NewRestorerWithImports(file.Name.Name, r).RestoreFile(file); err != nil {
+		return err
+	}
+	groupImports(file)
+	return NewRestorerWithImports(file.Name.Name, r).Fprint(w, file)
+}
+
+// groupImports sorts the specs of each import block, with standard library packages (paths
+// without a period) first, and an empty line before the first other package.
+func groupImports(file *dst.File) {
+	for _, decl := range file.Decls {
+		gd, ok := decl.(*dst.GenDecl)
+		if !ok || gd.Tok != token.IMPORT {
+			continue
+		}
+		if len(gd.Specs) < 2 {
+			continue
+		}
+		path := func(i int) string {
+			return mustUnquote(gd.Specs[i].(*dst.ImportSpec).Path.Value)
+		}
+		if path(0) == "C" {
+			// the cgo import must stay directly below its preamble
+			continue
+		}
+		sort.SliceStable(gd.Specs, func(i, j int) bool {
+			return packagePathOrderLess(path(i), path(j))
+		})
+		var foundDomainImport bool
+		for i, spec := range gd.Specs {
+			decs := spec.Decorations()
+			decs.Before, decs.After = dst.NewLine, dst.NewLine
+			if strings.Contains(path(i), ".") && !foundDomainImport {
+				if i > 0 {
+					decs.Before = dst.EmptyLine
+				}
+				foundDomainImport = true
+			}
+		}
+		gd.Lparen, gd.Rparen = true, true
+	}
+}
@@ -0,0 +1,60 @@
+package decorator
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/dave/dst"
+	"github.com/dave/dst/decorator/resolver/guess"
+)
+
+func TestFprintImports(t *testing.T) {
+	src := `package main
+
+import (
+	"github.com/a/b"
+	"os"
+
+	"fmt"
+	"strings" // strings is used
+)
+
+func main() {
+	fmt.Println(strings.ToUpper("a"))
+	b.B()
+}
+`
+	expect := `package main
+
+import (
+	"fmt"
+	"strings" // strings is used
+
+	"github.com/c/d-go"
+)
+
+func main() {
+	fmt.Println(strings.ToUpper("a"))
+	d.D()
+}
+`
+	f, err := Parse(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// replace the use of package b with a use of package d: b is now unused, and d is missing
+	body := f.Decls[1].(*dst.FuncDecl).Body
+	body.List[1].(*dst.ExprStmt).X.(*dst.CallExpr).Fun = &dst.Ident{Name: "D", Path: "github.com/c/d-go"}
+
+	r := guess.WithMap(map[string]string{"github.com/c/d-go": "d"})
+	buf := &bytes.Buffer{}
+	if err := FprintImports(buf, f, r); err != nil {
+		t.Fatal(err)
+	}
+	compare(t, expect, buf.String())
+
+	// f is not modified
+	if specs := f.Decls[0].(*dst.GenDecl).Specs; len(specs) != 4 {
+		t.Errorf("expected 4 import specs in f, found %d", len(specs))
+	}
+}
@@ -2,7 +2,6 @@ package decorator
 
 import (
 	"go/ast"
-	"go/format"
 	"go/parser"
 	"go/token"
 	"io"
@@ -29,6 +28,21 @@ func ParseDir(fset *token.FileSet, dir string, filter func(os.FileInfo) bool, mo
 	return NewDecorator(fset).ParseDir(dir, filter, mode)
 }
 
+// ParseExpr parses and decorates a Go expression.
+func ParseExpr(src string) (dst.Expr, error) {
+	return NewDecorator(token.NewFileSet()).ParseExpr(src)
+}
+
+// ParseStmts parses and decorates a list of Go statements.
+func ParseStmts(src string) ([]dst.Stmt, error) {
+	return NewDecorator(token.NewFileSet()).ParseStmts(src)
+}
+
+// SetBodyFromSource parses and decorates a list of Go statements, and sets them as the body of fn.
+func SetBodyFromSource(fn *dst.FuncDecl, src string) error {
+	return NewDecorator(token.NewFileSet()).SetBodyFromSource(fn, src)
+}
+
 // Decorate decorates an ast.Node and returns a dst.Node.
 func Decorate(fset *token.FileSet, n ast.Node) (dst.Node, error) {
 	return NewDecorator(fset).DecorateNode(n)
@@ -46,11 +60,31 @@ func Print(f *dst.File) error {
 
 // Fprint uses format.Node to print a *dst.File to a writer
 func Fprint(w io.Writer, f *dst.File) error {
+	return NewRestorer().Fprint(w, f)
+}
+
+// Patch restores f and returns the edits that transform original into the restored source (see
+// Restorer.Patch).
+func Patch(original []byte, f *dst.File) ([]TextEdit, error) {
+	return NewRestorer().Patch(original, f)
+}
+
+// UnifiedDiff restores f and returns a unified diff that transforms original into the restored
+// source (see Restorer.UnifiedDiff).
+func UnifiedDiff(original []byte, f *dst.File, contextLines int) (string, error) {
+	return NewRestorer().UnifiedDiff(original, f, contextLines)
+}
+
+// ToAst restores a *dst.File to an *ast.File and a new *token.FileSet containing the file, so the
+// result can be used with packages that only accept go/ast (e.g. go/printer and go/types). The
+// comments are in the Comments field of the file, and the package doc comment is also in the Doc
+// field.
+func ToAst(f *dst.File) (*ast.File, *token.FileSet, error) {
 	fset, af, err := RestoreFile(f)
 	if err != nil {
-		return err
+		return nil, nil, err
 	}
-	return format.Node(w, fset, af)
+	return af, fset, nil
 }
 
 // RestoreFile restores a *dst.File to a *token.FileSet and a *ast.File
@@ -0,0 +1,89 @@
+package decorator
+
+import (
+	"github.com/dave/dst"
+)
+
+// IdentKind describes the role of an identifier. It is passed to Restorer.IdentFormatter.
+type IdentKind int
+
+const (
+	IdentOther    IdentKind = iota // Any other identifier, e.g. a reference to a declaration
+	IdentPackage                   // The package name in the package clause
+	IdentImport                    // An import alias
+	IdentType                      // The name in a type spec
+	IdentFunc                      // The name of a function or method declaration
+	IdentField                     // The name of a struct field
+	IdentMethod                    // The name of an interface method
+	IdentParam                     // The name of a receiver, parameter or result
+	IdentValue                     // The name in a const or var spec
+	IdentLabel                     // A label in a labeled statement or branch statement
+	IdentSelector                  // The selector in a selector expression
+
+	// identRemote is the kind of the package and selector idents of a qualified identifier
+	// created by restoreIdent. These are never passed to IdentFormatter.
+	identRemote IdentKind = -1
+)
+
+// identName returns the name of the ident in the restored code.
+func (r *FileRestorer) identName(n *dst.Ident) string {
+	if r.IdentFormatter == nil {
+		return n.Name
+	}
+	kind := r.identKinds[n]
+	if kind == identRemote || (n.Path != "" && n.Path != r.Path) {
+		return n.Name
+	}
+	return r.IdentFormatter(n.Name, kind)
+}
+
+// findIdentKinds returns the kind of every ident in the file that is not IdentOther.
+func findIdentKinds(file *dst.File) map[*dst.Ident]IdentKind {
+	kinds := map[*dst.Ident]IdentKind{}
+	fields := func(list *dst.FieldList, kind IdentKind) {
+		if list == nil {
+			return
+		}
+		for _, f := range list.List {
+			for _, id := range f.Names {
+				kinds[id] = kind
+			}
+		}
+	}
+	dst.Inspect(file, func(n dst.Node) bool {
+		switch n := n.(type) {
+		case *dst.File:
+			kinds[n.Name] = IdentPackage
+		case *dst.ImportSpec:
+			if n.Name != nil {
+				kinds[n.Name] = IdentImport
+			}
+		case *dst.TypeSpec:
+			kinds[n.Name] = IdentType
+		case *dst.FuncDecl:
+			kinds[n.Name] = IdentFunc
+			fields(n.Recv, IdentParam)
+		case *dst.FuncType:
+			fields(n.Params, IdentParam)
+			fields(n.Results, IdentParam)
+		case *dst.StructType:
+			fields(n.Fields, IdentField)
+		case *dst.InterfaceType:
+			fields(n.Methods, IdentMethod)
+		case *dst.ValueSpec:
+			for _, id := range n.Names {
+				kinds[id] = IdentValue
+			}
+		case *dst.LabeledStmt:
+			kinds[n.Label] = IdentLabel
+		case *dst.BranchStmt:
+			if n.Label != nil {
+				kinds[n.Label] = IdentLabel
+			}
+		case *dst.SelectorExpr:
+			kinds[n.Sel] = IdentSelector
+		}
+		return true
+	})
+	return kinds
+}
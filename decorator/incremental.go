@@ -0,0 +1,330 @@
+package decorator
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"reflect"
+	"sort"
+
+	"github.com/dave/dst"
+	"github.com/dave/dst/dstutil"
+)
+
+// Incremental decorates f, which is a new version of the file that was decorated by d to produce
+// prev. The declarations of f are compared with the declarations of the file that prev was
+// decorated from, and only the declarations that changed are decorated. Each unchanged declaration
+// (including the comments and spacing around it) is reused from prev, so node identity is
+// preserved for the unchanged regions of the file, and the Map refers to the reused nodes. The
+// result is otherwise identical to DecorateFile. prev is not modified, but the reused declarations
+// are shared with it, so prev should not be used afterwards. prev must not have been modified
+// since it was decorated.
+//
+// A declaration is only reused when the objects it refers to are declared in reused declarations,
+// and when no directive that the decorator moves between declarations (see PinFuncDirectives and
+// PinGenerateDirectives) is next to it. Nothing is reused when prev was not decorated by d, or
+// when a Resolver is set and the imports have changed.
+//
+// Incremental is experimental.
+func (d *Decorator) Incremental(prev *dst.File, f *ast.File) (*dst.File, error) {
+	if prev == nil {
+		return d.DecorateFile(f)
+	}
+	old, ok := d.Ast.Nodes[prev].(*ast.File)
+	if !ok || len(old.Decls) != len(prev.Decls) {
+		return d.DecorateFile(f)
+	}
+	for i, decl := range old.Decls {
+		if d.Dst.Nodes[decl] != prev.Decls[i] {
+			return d.DecorateFile(f)
+		}
+	}
+	reused, err := d.unchangedDecls(old, f)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range reused {
+		d.reuseNodes(r)
+	}
+	return d.DecorateFile(f)
+}
+
+// reusedDecl is a declaration that is unchanged from a declaration of the previous file. The nodes
+// of both declarations are listed in the same order.
+type reusedDecl struct {
+	old             int
+	nodes, oldNodes []ast.Node
+}
+
+// declSpan is a declaration with the source around it, from the end of the previous declaration
+// to the start of the next. The decorations of the declaration are derived from the span.
+type declSpan struct {
+	decl     ast.Decl
+	from, to token.Pos
+	line     int
+	comments []*ast.CommentGroup
+}
+
+// unchangedDecls returns the declarations of f that are unchanged from a declaration of old, keyed
+// by index in f.
+func (d *Decorator) unchangedDecls(old, f *ast.File) (map[int]*reusedDecl, error) {
+	type key struct {
+		typ  reflect.Type
+		size token.Pos
+	}
+	candidates := map[key][]int{}
+	for i, decl := range old.Decls {
+		k := key{reflect.TypeOf(decl), decl.End() - decl.Pos()}
+		candidates[k] = append(candidates[k], i)
+	}
+	oldSpans, spans := d.declSpans(old), d.declSpans(f)
+
+	reused := map[int]*reusedDecl{}
+	used := map[int]bool{}
+	for i, decl := range f.Decls {
+		if d.movesDirectives(spans[i]) {
+			continue
+		}
+		for _, j := range candidates[key{reflect.TypeOf(decl), decl.End() - decl.Pos()}] {
+			if used[j] {
+				continue
+			}
+			equal, err := d.equalSpans(spans[i], oldSpans[j])
+			if err != nil {
+				return nil, err
+			}
+			if equal {
+				used[j] = true
+				reused[i] = &reusedDecl{old: j, nodes: inspectNodes(decl), oldNodes: inspectNodes(old.Decls[j])}
+				break
+			}
+		}
+	}
+
+	// with a resolver, the paths of the idents depend on the imports
+	if d.Resolver != nil {
+		for i, decl := range f.Decls {
+			if gd, ok := decl.(*ast.GenDecl); ok && gd.Tok == token.IMPORT && reused[i] == nil {
+				return nil, nil
+			}
+		}
+		for j, decl := range old.Decls {
+			if gd, ok := decl.(*ast.GenDecl); ok && gd.Tok == token.IMPORT && !used[j] {
+				return nil, nil
+			}
+		}
+	}
+
+	// a declaration that refers to an object declared in a changed declaration is changed too, so
+	// repeat until no more declarations are removed
+	for changed := true; changed; {
+		changed = false
+		for i, r := range reused {
+			if !d.sameObjects(f, old, r, reused) {
+				delete(reused, i)
+				changed = true
+			}
+		}
+	}
+	return reused, nil
+}
+
+// sameObjects reports whether the idents of r refer to the same objects as the idents of the
+// previous declaration, and the objects are declared in reused declarations.
+func (d *Decorator) sameObjects(f, old *ast.File, r *reusedDecl, reused map[int]*reusedDecl) bool {
+	for k, n := range r.nodes {
+		id, ok := n.(*ast.Ident)
+		if !ok {
+			continue
+		}
+		oldIdent := r.oldNodes[k].(*ast.Ident)
+		if id.Obj == nil || oldIdent.Obj == nil {
+			if id.Obj != oldIdent.Obj {
+				return false
+			}
+			continue
+		}
+		if d.Dst.Objects[oldIdent.Obj] == nil {
+			return false
+		}
+		i, j := declIndex(f, id.Obj), declIndex(old, oldIdent.Obj)
+		if i < 0 || j < 0 || reused[i] == nil || reused[i].old != j {
+			return false
+		}
+	}
+	return true
+}
+
+// reuseNodes updates the Map so the nodes and objects of r refer to the nodes and objects of the
+// previous declaration, which are then used by the decorator instead of new nodes.
+func (d *Decorator) reuseNodes(r *reusedDecl) {
+	for k, n := range r.nodes {
+		o := r.oldNodes[k]
+		dn, ok := d.Dst.Nodes[o]
+		if !ok {
+			continue
+		}
+		d.Dst.Nodes[n] = dn
+		d.Ast.Nodes[dn] = n
+		if id, ok := n.(*ast.Ident); ok && id.Obj != nil {
+			do := d.Dst.Objects[o.(*ast.Ident).Obj]
+			d.Dst.Objects[id.Obj] = do
+			d.Ast.Objects[do] = id.Obj
+		}
+	}
+}
+
+// declSpans returns the spans of the declarations of f.
+func (d *Decorator) declSpans(f *ast.File) []*declSpan {
+	tf := d.Fset.File(f.Pos())
+	spans := make([]*declSpan, len(f.Decls))
+	for i, decl := range f.Decls {
+		s := &declSpan{decl: decl, from: f.Name.End(), to: token.Pos(tf.Base() + tf.Size())}
+		if i > 0 {
+			s.from = f.Decls[i-1].End()
+		}
+		if i < len(f.Decls)-1 {
+			s.to = f.Decls[i+1].Pos()
+		}
+		s.line = d.Fset.PositionFor(s.from, false).Line
+		first := sort.Search(len(f.Comments), func(i int) bool { return f.Comments[i].Pos() >= s.from })
+		last := sort.Search(len(f.Comments), func(i int) bool { return f.Comments[i].Pos() >= s.to })
+		s.comments = f.Comments[first:last]
+		spans[i] = s
+	}
+	return spans
+}
+
+// movesDirectives returns true if the span contains a directive that the decorator moves between
+// declarations.
+func (d *Decorator) movesDirectives(s *declSpan) bool {
+	for _, cg := range s.comments {
+		for _, c := range cg.List {
+			if _, ok := exportDirective(c.Text); ok {
+				return true
+			}
+			if d.PinFuncDirectives && dstutil.IsPragma(c.Text) {
+				return true
+			}
+			if d.PinGenerateDirectives && hasGenerateDirective(dst.Decorations{c.Text}) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// declIndex returns the index of the declaration of f that declares o, or -1.
+func declIndex(f *ast.File, o *ast.Object) int {
+	n, ok := o.Decl.(ast.Node)
+	if !ok {
+		return -1
+	}
+	for i, decl := range f.Decls {
+		if decl.Pos() <= n.Pos() && n.Pos() < decl.End() {
+			return i
+		}
+	}
+	return -1
+}
+
+func inspectNodes(n ast.Node) []ast.Node {
+	var nodes []ast.Node
+	ast.Inspect(n, func(n ast.Node) bool {
+		if n != nil {
+			nodes = append(nodes, n)
+		}
+		return true
+	})
+	return nodes
+}
+
+// equalSpans reports whether the declarations and the comments of a and b are identical. Positions
+// are compared relative to the start of each span.
+func (d *Decorator) equalSpans(a, b *declSpan) (bool, error) {
+	if !d.equalPos(a, b, a.from, b.from) || !d.equalPos(a, b, a.to, b.to) || len(a.comments) != len(b.comments) {
+		return false, nil
+	}
+	for i := range a.comments {
+		equal, err := d.equalValues(a, b, reflect.ValueOf(a.comments[i]), reflect.ValueOf(b.comments[i]))
+		if err != nil || !equal {
+			return false, err
+		}
+	}
+	return d.equalValues(a, b, reflect.ValueOf(a.decl), reflect.ValueOf(b.decl))
+}
+
+func (d *Decorator) equalPos(a, b *declSpan, pa, pb token.Pos) bool {
+	if !pa.IsValid() || !pb.IsValid() {
+		return pa.IsValid() == pb.IsValid()
+	}
+	posA, posB := d.Fset.PositionFor(pa, false), d.Fset.PositionFor(pb, false)
+	return posA.Line-a.line == posB.Line-b.line && posA.Column == posB.Column
+}
+
+var (
+	posType     = reflect.TypeOf(token.NoPos)
+	objectType  = reflect.TypeOf((*ast.Object)(nil))
+	scopeType   = reflect.TypeOf((*ast.Scope)(nil))
+	badExprType = reflect.TypeOf((*ast.BadExpr)(nil))
+	badStmtType = reflect.TypeOf((*ast.BadStmt)(nil))
+	badDeclType = reflect.TypeOf((*ast.BadDecl)(nil))
+)
+
+// equalValues compares two ast values from the spans a and b. Objects and scopes are ignored. Bad
+// nodes are never equal, because their source is not part of the ast.
+func (d *Decorator) equalValues(a, b *declSpan, va, vb reflect.Value) (bool, error) {
+	if va.Type() != vb.Type() {
+		return false, nil
+	}
+	switch va.Kind() {
+	case reflect.Ptr:
+		switch va.Type() {
+		case objectType, scopeType:
+			return true, nil
+		case badExprType, badStmtType, badDeclType:
+			return false, nil
+		}
+		if va.IsNil() || vb.IsNil() {
+			return va.IsNil() == vb.IsNil(), nil
+		}
+		return d.equalValues(a, b, va.Elem(), vb.Elem())
+	case reflect.Interface:
+		if va.IsNil() || vb.IsNil() {
+			return va.IsNil() == vb.IsNil(), nil
+		}
+		return d.equalValues(a, b, va.Elem(), vb.Elem())
+	case reflect.Struct:
+		for i := 0; i < va.NumField(); i++ {
+			equal, err := d.equalValues(a, b, va.Field(i), vb.Field(i))
+			if err != nil || !equal {
+				return false, err
+			}
+		}
+		return true, nil
+	case reflect.Slice:
+		if va.Len() != vb.Len() {
+			return false, nil
+		}
+		for i := 0; i < va.Len(); i++ {
+			equal, err := d.equalValues(a, b, va.Index(i), vb.Index(i))
+			if err != nil || !equal {
+				return false, err
+			}
+		}
+		return true, nil
+	case reflect.String:
+		return va.String() == vb.String(), nil
+	case reflect.Bool:
+		return va.Bool() == vb.Bool(), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if va.Type() == posType {
+			return d.equalPos(a, b, token.Pos(va.Int()), token.Pos(vb.Int())), nil
+		}
+		return va.Int() == vb.Int(), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return va.Uint() == vb.Uint(), nil
+	default:
+		return false, fmt.Errorf("unsupported kind %s in %s", va.Kind(), va.Type())
+	}
+}
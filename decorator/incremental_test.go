@@ -0,0 +1,138 @@
+package decorator
+
+import (
+	"bytes"
+	"go/parser"
+	"go/token"
+	"testing"
+
+	"github.com/dave/dst"
+)
+
+func TestIncremental(t *testing.T) {
+	before := `package a
+
+// a
+func a() {
+	println("a")
+}
+
+func b() {
+	println("b")
+}
+
+var c = a
+`
+	after := `package a
+
+// a
+func a() {
+	println("a")
+}
+
+func b() {
+	println("b", "c") // c
+}
+
+var c = a
+`
+	d := NewDecorator(token.NewFileSet())
+	prev, err := d.Parse(before)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decls := append([]dst.Decl{}, prev.Decls...)
+
+	af, err := parser.ParseFile(d.Fset, "", after, parser.ParseComments)
+	if err != nil {
+		t.Fatal(err)
+	}
+	file, err := d.Incremental(prev, af)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if file.Decls[0] != decls[0] || file.Decls[2] != decls[2] {
+		t.Error("expected unchanged declarations to be reused")
+	}
+	if file.Decls[1] == decls[1] {
+		t.Error("expected changed declaration not to be reused")
+	}
+	if d.Dst.Nodes[af.Decls[0]] != decls[0] {
+		t.Error("expected Map to refer to the reused declaration")
+	}
+	ref := file.Decls[2].(*dst.GenDecl).Specs[0].(*dst.ValueSpec).Values[0].(*dst.Ident)
+	if ref.Obj == nil || ref.Obj.Decl != file.Decls[0] {
+		t.Error("expected object to refer to the reused declaration")
+	}
+
+	buf := &bytes.Buffer{}
+	if err := Fprint(buf, file); err != nil {
+		t.Fatal(err)
+	}
+	compare(t, after, buf.String())
+
+	buf.Reset()
+	if err := Fprint(buf, prev); err != nil {
+		t.Fatal(err)
+	}
+	compare(t, before, buf.String())
+}
+
+func TestIncrementalObjects(t *testing.T) {
+	before := `package a
+
+func a() {}
+
+var c = a
+`
+	after := `package a
+
+func a(int) {}
+
+var c = a
+`
+	d := NewDecorator(token.NewFileSet())
+	prev, err := d.Parse(before)
+	if err != nil {
+		t.Fatal(err)
+	}
+	af, err := parser.ParseFile(d.Fset, "", after, parser.ParseComments)
+	if err != nil {
+		t.Fatal(err)
+	}
+	file, err := d.Incremental(prev, af)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if file.Decls[1] == prev.Decls[1] {
+		t.Error("expected declaration referring to a changed declaration not to be reused")
+	}
+	ref := file.Decls[1].(*dst.GenDecl).Specs[0].(*dst.ValueSpec).Values[0].(*dst.Ident)
+	if ref.Obj == nil || ref.Obj.Decl != file.Decls[0] {
+		t.Error("expected object to refer to the new declaration")
+	}
+}
+
+func TestIncrementalDirectives(t *testing.T) {
+	before := "package a\n\nvar a = 1\n\t//export B\nfunc B() {}\n"
+	after := "package a\n\nvar a = 1\n\t//export B\nfunc B() { println() }\n"
+	d := NewDecorator(token.NewFileSet())
+	prev, err := d.Parse(before)
+	if err != nil {
+		t.Fatal(err)
+	}
+	af, err := parser.ParseFile(d.Fset, "", after, parser.ParseComments)
+	if err != nil {
+		t.Fatal(err)
+	}
+	file, err := d.Incremental(prev, af)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if file.Decls[0] == prev.Decls[0] {
+		t.Error("expected declaration next to a directive not to be reused")
+	}
+	if start := file.Decls[1].Decorations().Start.All(); len(start) == 0 || start[0] != "//export B" {
+		t.Errorf("expected directive to be attached to the function, got %q", start)
+	}
+}
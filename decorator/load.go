@@ -3,12 +3,18 @@ package decorator
 import (
 	"bytes"
 	"errors"
+	"fmt"
+	"go/build"
+	"go/parser"
+	"go/token"
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"sort"
 
 	"github.com/dave/dst"
 	"github.com/dave/dst/decorator/resolver"
+	"github.com/dave/dst/decorator/resolver/goast"
 	"github.com/dave/dst/decorator/resolver/gopackages"
 	"golang.org/x/tools/go/packages"
 )
@@ -38,6 +44,7 @@ func Load(cfg *packages.Config, patterns ...string) ([]*Package, error) {
 		p := &Package{
 			Package: pkg,
 			Imports: map[string]*Package{},
+			Files:   map[string]*dst.File{},
 		}
 		dpkgs[pkg] = p
 		if len(pkg.Syntax) > 0 {
@@ -60,6 +67,7 @@ func Load(cfg *packages.Config, patterns ...string) ([]*Package, error) {
 					return nil, err
 				}
 				p.Syntax = append(p.Syntax, file)
+				p.Files[fpath] = file
 			}
 
 			dir, _ := filepath.Split(pkg.Fset.File(pkg.Syntax[0].Pos()).Name())
@@ -88,12 +96,76 @@ func Load(cfg *packages.Config, patterns ...string) ([]*Package, error) {
 	return out, nil
 }
 
+// DecoratePackage parses and decorates the Go files of the package in dir, without loading type
+// information. The files are chosen with go/build, so files excluded by build constraints are
+// skipped, and an error is returned if dir contains files of more than one package. The _test.go
+// files of the package are included if tests is set (the files of an external test package, with
+// the "_test" suffix, are never included).
+//
+// The files are decorated with import management, using the goast resolver and the package path
+// reported by the go command, and the returned Package is saved with the gopackages resolver. The
+// Decorator holds the shared FileSet, the Map between the ast and dst nodes, and the file names.
+// Only the Name, PkgPath, GoFiles and Fset fields of the embedded packages.Package are set.
+func DecoratePackage(dir string, tests bool) (*Package, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	bp, err := build.ImportDir(dir, 0)
+	if err != nil {
+		return nil, err
+	}
+	names := bp.GoFiles
+	if tests {
+		names = append(names, bp.TestGoFiles...)
+	}
+	sort.Strings(names)
+
+	pkgs, err := packages.Load(&packages.Config{Mode: packages.NeedName, Dir: dir}, ".")
+	if err != nil {
+		return nil, err
+	}
+	if len(pkgs) != 1 || pkgs[0].PkgPath == "" {
+		return nil, fmt.Errorf("can't find the package path of %s", dir)
+	}
+
+	fset := token.NewFileSet()
+	p := &Package{
+		Package: &packages.Package{
+			Name:    bp.Name,
+			PkgPath: pkgs[0].PkgPath,
+			Fset:    fset,
+		},
+		Dir:       dir,
+		Decorator: NewDecoratorWithImports(fset, pkgs[0].PkgPath, goast.New()),
+		Imports:   map[string]*Package{},
+		Files:     map[string]*dst.File{},
+	}
+	for _, name := range names {
+		fpath := filepath.Join(dir, name)
+		file, err := p.Decorator.ParseFile(fpath, nil, parser.ParseComments)
+		if err != nil {
+			return nil, err
+		}
+		p.GoFiles = append(p.GoFiles, fpath)
+		p.Syntax = append(p.Syntax, file)
+		p.Files[fpath] = file
+	}
+	return p, nil
+}
+
 type Package struct {
 	*packages.Package
 	Dir       string
 	Decorator *Decorator
 	Imports   map[string]*Package
 	Syntax    []*dst.File
+	Files     map[string]*dst.File // Decorated files by file name
+
+	// If Changed is set, Save and SaveWithResolver only restore and write the files for which
+	// Changed returns true. Use (*dstutil.Changes).Changed to only write files modified by
+	// dstutil.ApplyWithChanges.
+	Changed func(f *dst.File) bool
 }
 
 func (p *Package) Save() error {
@@ -107,6 +179,9 @@ func (p *Package) SaveWithResolver(resolver resolver.RestorerResolver) error {
 func (p *Package) save(resolver resolver.RestorerResolver, writeFile func(filename string, data []byte, perm os.FileMode) error) error {
 	r := NewRestorerWithImports(p.PkgPath, resolver)
 	for _, file := range p.Syntax {
+		if p.Changed != nil && !p.Changed(file) {
+			continue
+		}
 		buf := &bytes.Buffer{}
 		if err := r.Fprint(buf, file); err != nil {
 			return err
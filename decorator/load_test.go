@@ -1,10 +1,16 @@
 package decorator
 
 import (
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/dave/dst"
+	"github.com/dave/dst/decorator/resolver/guess"
 	"github.com/dave/dst/decorator/resolver/simple"
+	"github.com/dave/dst/dstutil"
 	"golang.org/x/tools/go/packages"
 )
 
@@ -103,3 +109,106 @@ func TestPackage_SaveWithResolver(t *testing.T) {
 	}
 	compareDir(t, dir, expect)
 }
+
+func TestPackage_SaveChanged(t *testing.T) {
+	d := NewDecorator(token.NewFileSet())
+	p := &Package{
+		Package:   &packages.Package{PkgPath: "root"},
+		Decorator: d,
+	}
+	for _, name := range []string{"a.go", "b.go", "c.go"} {
+		file, err := d.ParseFile(name, "package a\n\nfunc f() {\n\tprintln()\n}\n", parser.ParseComments)
+		if err != nil {
+			t.Fatal(err)
+		}
+		p.Syntax = append(p.Syntax, file)
+	}
+
+	changes := &dstutil.Changes{}
+	dstutil.ApplyWithChanges(p.Syntax[1], func(c *dstutil.Cursor) bool {
+		if _, ok := c.Node().(*dst.ExprStmt); ok {
+			c.Delete()
+		}
+		return true
+	}, nil, changes)
+	p.Changed = changes.Changed
+
+	written := map[string]string{}
+	writeFile := func(filename string, data []byte, perm os.FileMode) error {
+		written[filename] = string(data)
+		return nil
+	}
+	if err := p.save(guess.New(), writeFile); err != nil {
+		t.Fatal(err)
+	}
+	if len(written) != 1 {
+		t.Fatalf("expected 1 file to be written, found %d", len(written))
+	}
+	compare(t, "package a\n\nfunc f() {}\n", written["b.go"])
+}
+
+func TestDecoratePackage(t *testing.T) {
+	code := map[string]string{
+		"a.go": `package a
+
+			import "fmt"
+
+			func a() {
+				fmt.Println(b)
+			}
+		`,
+		"b.go":      "package a\n\nvar b = 1\n",
+		"a_test.go": "package a\n\nvar c = 2\n",
+		"x_test.go": "package a_test\n\nvar d = 3\n",
+		"ignored.go": `//go:build ignore
+
+			package main
+		`,
+		"go.mod": "module root\n\ngo 1.14",
+	}
+	dir, err := tempDir(code)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	for _, tests := range []bool{false, true} {
+		p, err := DecoratePackage(dir, tests)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expect := []string{"a.go", "b.go"}
+		if tests {
+			expect = append(expect, "a_test.go")
+		}
+		if len(p.Files) != len(expect) || len(p.Syntax) != len(expect) {
+			t.Fatalf("expected %d files, found %d", len(expect), len(p.Files))
+		}
+		for _, name := range expect {
+			if p.Files[filepath.Join(dir, name)] == nil {
+				t.Errorf("expected file %s", name)
+			}
+		}
+		if p.Name != "a" || p.PkgPath != "root" {
+			t.Errorf("unexpected package %s %s", p.Name, p.PkgPath)
+		}
+		// the files are decorated with import management
+		call := p.Files[filepath.Join(dir, "a.go")].Decls[1].(*dst.FuncDecl).Body.List[0].(*dst.ExprStmt).X.(*dst.CallExpr)
+		if id, ok := call.Fun.(*dst.Ident); !ok || id.Path != "fmt" {
+			t.Errorf("expected a qualified identifier, found %#v", call.Fun)
+		}
+	}
+
+	dir, err = tempDir(map[string]string{
+		"a.go":   "package a\n",
+		"b.go":   "package b\n",
+		"go.mod": "module root\n\ngo 1.14",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	if _, err := DecoratePackage(dir, false); err == nil {
+		t.Error("expected error for multiple packages, found none")
+	}
+}
@@ -0,0 +1,124 @@
+package decorator
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"strings"
+)
+
+// minimalReformat returns the restored source out, with the text of each unchanged declaration
+// replaced by the text of the declaration in MinimalReformat. A declaration is unchanged if it's
+// printed (including its doc comment) in the same way as a declaration of the original source,
+// which is matched in order. The text between two declarations (and before the first and after the
+// last) is taken from the original source if the declarations on both sides are unchanged and
+// adjacent in the original source, and the text has the same comments.
+func (pr *Restorer) minimalReformat(out []byte) ([]byte, error) {
+	orig := pr.MinimalReformat
+	ofset, rfset := token.NewFileSet(), token.NewFileSet()
+	of, err := parser.ParseFile(ofset, "", orig, parser.ParseComments)
+	if err != nil {
+		return nil, fmt.Errorf("parsing MinimalReformat: %v", err)
+	}
+	rf, err := parser.ParseFile(rfset, "", out, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	if len(rf.Decls) == 0 || len(of.Decls) == 0 {
+		return out, nil
+	}
+
+	okeys, rkeys := declKeys(ofset, of), declKeys(rfset, rf)
+
+	// match is the index of the original declaration that each restored declaration is unchanged
+	// from, or -1 if it's changed.
+	match := make([]int, len(rf.Decls))
+	var next int
+	for i := range rf.Decls {
+		match[i] = -1
+		for j := next; j < len(of.Decls); j++ {
+			if okeys[j] == rkeys[i] {
+				match[i] = j
+				next = j + 1
+				break
+			}
+		}
+	}
+
+	ostart := func(j int) int { return ofset.Position(of.Decls[j].Pos()).Offset }
+	oend := func(j int) int { return ofset.Position(of.Decls[j].End()).Offset }
+	rstart := func(i int) int { return rfset.Position(rf.Decls[i].Pos()).Offset }
+	rend := func(i int) int { return rfset.Position(rf.Decls[i].End()).Offset }
+
+	// gap writes the original text if it has the same comments as the restored text, or the
+	// restored text otherwise.
+	buf := &bytes.Buffer{}
+	gap := func(original bool, o, r []byte) {
+		if original && sameComments(o, r) {
+			buf.Write(o)
+			return
+		}
+		buf.Write(r)
+	}
+
+	last := len(rf.Decls) - 1
+	gap(match[0] == 0, orig[:ostart(0)], out[:rstart(0)])
+	for i := range rf.Decls {
+		if j := match[i]; j >= 0 {
+			buf.Write(orig[ostart(j):oend(j)])
+		} else {
+			buf.Write(out[rstart(i):rend(i)])
+		}
+		if i == last {
+			break
+		}
+		if j := match[i]; j >= 0 && match[i+1] == j+1 {
+			gap(true, orig[oend(j):ostart(j+1)], out[rend(i):rstart(i+1)])
+		} else {
+			buf.Write(out[rend(i):rstart(i+1)])
+		}
+	}
+	if j := match[last]; j >= 0 {
+		gap(j == len(of.Decls)-1, orig[oend(j):], out[rend(last):])
+	} else {
+		buf.Write(out[rend(last):])
+	}
+	return buf.Bytes(), nil
+}
+
+// declKeys returns the declarations of f printed in the same way as gofmt, including the comments
+// inside them.
+func declKeys(fset *token.FileSet, f *ast.File) []string {
+	config := printer.Config{Mode: printer.UseSpaces | printer.TabIndent, Tabwidth: 8}
+	keys := make([]string, len(f.Decls))
+	for i, decl := range f.Decls {
+		buf := &bytes.Buffer{}
+		if err := config.Fprint(buf, fset, &printer.CommentedNode{Node: decl, Comments: f.Comments}); err != nil {
+			// never matched
+			keys[i] = fmt.Sprintf("\x00%d", i)
+			continue
+		}
+		keys[i] = buf.String()
+	}
+	return keys
+}
+
+// sameComments returns true if the text between two declarations a and b has the same comments,
+// ignoring the indentation, trailing white space and the number of consecutive empty lines.
+func sameComments(a, b []byte) bool {
+	normalize := func(text []byte) string {
+		var lines []string
+		for _, line := range strings.Split(string(text), "\n") {
+			line = strings.TrimSpace(line)
+			if line == "" && len(lines) > 0 && lines[len(lines)-1] == "" {
+				continue
+			}
+			lines = append(lines, line)
+		}
+		return strings.Join(lines, "\n")
+	}
+	return normalize(a) == normalize(b)
+}
@@ -0,0 +1,122 @@
+package decorator
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/dave/dst"
+)
+
+func TestRestorerMinimalReformat(t *testing.T) {
+	src := `package main
+
+var a=1 // a
+
+func A() {
+    b:=a
+	_ = b
+}
+func B() int { return  1 }
+
+
+// C is misformatted.
+func C( ) {}
+`
+	tests := []struct {
+		name   string
+		fn     func(f *dst.File)
+		expect string
+	}{
+		{
+			name:   "unchanged",
+			fn:     func(f *dst.File) {},
+			expect: src,
+		},
+		{
+			name: "change",
+			fn: func(f *dst.File) {
+				ret := f.Decls[2].(*dst.FuncDecl).Body.List[0].(*dst.ReturnStmt)
+				ret.Results[0].(*dst.BasicLit).Value = "2"
+			},
+			expect: `package main
+
+var a=1 // a
+
+func A() {
+    b:=a
+	_ = b
+}
+func B() int { return 2 }
+
+// C is misformatted.
+func C( ) {}
+`,
+		},
+		{
+			name: "comment",
+			fn: func(f *dst.File) {
+				f.Decls[3].Decorations().Start.Replace("// C is changed.")
+			},
+			expect: `package main
+
+var a=1 // a
+
+func A() {
+    b:=a
+	_ = b
+}
+func B() int { return  1 }
+
+// C is changed.
+func C() {}
+`,
+		},
+		{
+			name: "add",
+			fn: func(f *dst.File) {
+				fn := &dst.FuncDecl{
+					Name: dst.NewIdent("D"),
+					Type: &dst.FuncType{},
+					Body: &dst.BlockStmt{},
+				}
+				fn.Decs.Before, fn.Decs.After = dst.EmptyLine, dst.EmptyLine
+				f.Decls = append(f.Decls[:2], append([]dst.Decl{fn}, f.Decls[2:]...)...)
+			},
+			expect: `package main
+
+var a=1 // a
+
+func A() {
+    b:=a
+	_ = b
+}
+
+func D() {}
+
+func B() int { return  1 }
+
+
+// C is misformatted.
+func C( ) {}
+`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			f, err := Parse(src)
+			if err != nil {
+				t.Fatal(err)
+			}
+			test.fn(f)
+			r := NewRestorer()
+			r.MinimalReformat = []byte(src)
+			buf := &bytes.Buffer{}
+			if err := r.Fprint(buf, f); err != nil {
+				t.Fatal(err)
+			}
+			if buf.String() != test.expect {
+				t.Errorf("diff:\n%s", diff(test.expect, buf.String()))
+			}
+		})
+	}
+}
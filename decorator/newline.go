@@ -0,0 +1,76 @@
+package decorator
+
+import (
+	"bytes"
+	"go/ast"
+	"go/token"
+
+	"github.com/dave/dst"
+)
+
+// FinalNewline controls the newlines at the end of the output of Print and Fprint.
+type FinalNewline int
+
+const (
+	// FinalNewlineSingle ends the output with a single newline, as the printer does.
+	FinalNewlineSingle FinalNewline = iota
+	// FinalNewlineNone removes the newline from the end of the output.
+	FinalNewlineNone
+	// FinalNewlinePreserve ends the output with the number of newlines at the end of the file when
+	// it was decorated, recorded in Restorer.FinalNewlines. A file that isn't in FinalNewlines ends
+	// with a single newline.
+	FinalNewlinePreserve
+)
+
+// finalNewlines returns the number of newlines after the last token or comment of f, from the line
+// information in tf. White space other than newlines isn't recorded in the FileSet, so any bytes
+// after the last recorded line start are counted as a single newline.
+func finalNewlines(tf *token.File, f *ast.File) int {
+	end := f.End()
+	if len(f.Comments) > 0 {
+		if ce := f.Comments[len(f.Comments)-1].End(); ce > end {
+			end = ce
+		}
+	}
+	if !end.IsValid() {
+		return 0
+	}
+	offset := tf.Offset(end)
+	last := offset
+	var count int
+	for line := tf.LineCount(); line > 1; line-- {
+		start := tf.Offset(tf.LineStart(line))
+		if start <= offset {
+			break
+		}
+		// each line that starts after the code follows a newline
+		if count == 0 {
+			last = start
+		}
+		count++
+	}
+	if tf.Size() > last {
+		// the scanner doesn't record the line start after a newline at the very end of the file
+		count++
+	}
+	return count
+}
+
+// setFinalNewline replaces the newlines at the end of src with n newlines.
+func setFinalNewline(src []byte, n int) []byte {
+	src = bytes.TrimRight(src, "\n")
+	return append(src, bytes.Repeat([]byte("\n"), n)...)
+}
+
+// applyFinalNewline returns src with the newlines at the end set according to FinalNewline.
+func (pr *Restorer) applyFinalNewline(src []byte, file *dst.File) []byte {
+	switch pr.FinalNewline {
+	case FinalNewlineNone:
+		return setFinalNewline(src, 0)
+	case FinalNewlinePreserve:
+		if n, ok := pr.FinalNewlines[file]; ok {
+			return setFinalNewline(src, n)
+		}
+	}
+	return src
+}
@@ -0,0 +1,110 @@
+package decorator
+
+import (
+	"go/ast"
+
+	"golang.org/x/tools/go/ast/astutil"
+)
+
+// ParenMode controls how parenthesized expressions are restored.
+type ParenMode int
+
+const (
+	// ParenPreserve restores the ParenExpr nodes exactly as they are in the tree.
+	ParenPreserve ParenMode = iota
+	// ParenMinimal drops the parentheses that are not needed to preserve the meaning of the code.
+	ParenMinimal
+	// ParenExplicit keeps the ParenExpr nodes in the tree, and adds parentheses around binary
+	// expressions that are operands of a binary expression with a different precedence, so the
+	// precedence is visible (e.g. "a + b*c" is restored as "a + (b * c)").
+	ParenExplicit
+)
+
+// applyParenMode updates the parenthesized expressions in a restored file.
+func applyParenMode(f *ast.File, mode ParenMode) {
+	switch mode {
+	case ParenMinimal:
+		astutil.Apply(f, nil, func(c *astutil.Cursor) bool {
+			p, ok := c.Node().(*ast.ParenExpr)
+			if !ok {
+				return true
+			}
+			if x := unparenAst(p.X); !needsParens(c.Parent(), c.Name(), x) {
+				c.Replace(x)
+			}
+			return true
+		})
+	case ParenExplicit:
+		astutil.Apply(f, nil, func(c *astutil.Cursor) bool {
+			b, ok := c.Node().(*ast.BinaryExpr)
+			if !ok {
+				return true
+			}
+			b.X = explicitOperand(b, b.X)
+			b.Y = explicitOperand(b, b.Y)
+			return true
+		})
+	}
+}
+
+// explicitOperand wraps x in parentheses if it's a binary expression with a different precedence
+// to its parent.
+func explicitOperand(parent *ast.BinaryExpr, x ast.Expr) ast.Expr {
+	b, ok := x.(*ast.BinaryExpr)
+	if !ok || b.Op.Precedence() == parent.Op.Precedence() {
+		return x
+	}
+	return &ast.ParenExpr{Lparen: b.Pos(), X: b, Rparen: b.End()}
+}
+
+func unparenAst(x ast.Expr) ast.Expr {
+	for {
+		p, ok := x.(*ast.ParenExpr)
+		if !ok {
+			return x
+		}
+		x = p.X
+	}
+}
+
+// needsParens reports whether parentheses are needed around x, in the field of parent. This is
+// conservative: parentheses are only dropped where this is known to be safe.
+func needsParens(parent ast.Node, field string, x ast.Expr) bool {
+	switch x := x.(type) {
+	case *ast.Ident, *ast.BasicLit, *ast.SelectorExpr, *ast.IndexExpr, *ast.SliceExpr, *ast.CallExpr, *ast.TypeAssertExpr:
+		// primary expressions never need parentheses
+		return false
+	case *ast.BinaryExpr:
+		if p, ok := parent.(*ast.BinaryExpr); ok {
+			// binary operators are left associative
+			if field == "X" {
+				return x.Op.Precedence() < p.Op.Precedence()
+			}
+			return x.Op.Precedence() <= p.Op.Precedence()
+		}
+		return !valueContext(parent, field)
+	case *ast.UnaryExpr, *ast.StarExpr:
+		if _, ok := parent.(*ast.BinaryExpr); ok {
+			// unary operators have the highest precedence
+			return false
+		}
+		return !valueContext(parent, field)
+	}
+	return true
+}
+
+// valueContext reports whether any expression can be used in the field of parent without
+// parentheses.
+func valueContext(parent ast.Node, field string) bool {
+	switch parent.(type) {
+	case *ast.ExprStmt, *ast.ReturnStmt, *ast.ValueSpec, *ast.CompositeLit:
+		return field != "Type"
+	case *ast.AssignStmt:
+		return field == "Rhs"
+	case *ast.CallExpr:
+		return field == "Args"
+	case *ast.KeyValueExpr:
+		return field == "Value"
+	}
+	return false
+}
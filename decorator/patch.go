@@ -0,0 +1,206 @@
+package decorator
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/dave/dst"
+	"github.com/sergi/go-diff/diffmatchpatch"
+)
+
+// TextEdit replaces Length bytes at byte Offset in the original source with NewText. An insertion
+// has a zero Length, and a deletion has an empty NewText.
+type TextEdit struct {
+	Offset  int
+	Length  int
+	NewText string
+}
+
+// ApplyEdits returns src with the edits applied. The edits must be sorted by Offset and must not
+// overlap, as returned by Patch.
+func ApplyEdits(src []byte, edits []TextEdit) []byte {
+	out := &bytes.Buffer{}
+	var offset int
+	for _, e := range edits {
+		out.Write(src[offset:e.Offset])
+		out.WriteString(e.NewText)
+		offset = e.Offset + e.Length
+	}
+	out.Write(src[offset:])
+	return out.Bytes()
+}
+
+// Patch restores f and returns the edits that transform original into the restored source, so an
+// editor can apply a transformation without replacing the whole file. The edits are sorted by
+// Offset and don't overlap, and each edit is minimal: unchanged text at the start and end of a
+// changed region is not included. Applying the edits to original (e.g. with ApplyEdits) produces
+// exactly the output of Fprint. Offsets are in bytes; LSP clients need them converted to line and
+// character positions.
+func (pr *Restorer) Patch(original []byte, f *dst.File) ([]TextEdit, error) {
+	buf := &bytes.Buffer{}
+	if err := pr.Fprint(buf, f); err != nil {
+		return nil, err
+	}
+	return textEdits(string(original), buf.String()), nil
+}
+
+// UnifiedDiff restores f and returns a unified diff (as printed by "diff -u") that transforms
+// original into the restored source, with contextLines lines of unchanged context around each
+// change. The file names in the header are "original" and "restored". An empty string is returned
+// if the restored source is identical to original.
+func (pr *Restorer) UnifiedDiff(original []byte, f *dst.File, contextLines int) (string, error) {
+	buf := &bytes.Buffer{}
+	if err := pr.Fprint(buf, f); err != nil {
+		return "", err
+	}
+	return unifiedDiff(string(original), buf.String(), contextLines), nil
+}
+
+// diffLine is a line of a line-based diff: Type is the diff operation, and Text is the line
+// including the line break (which is missing from the last line of a file without a final
+// newline).
+type diffLine struct {
+	Type diffmatchpatch.Operation
+	Text string
+}
+
+// unifiedDiff returns the unified diff that transforms a into b.
+func unifiedDiff(a, b string, contextLines int) string {
+	if a == b {
+		return ""
+	}
+	if contextLines < 0 {
+		contextLines = 0
+	}
+	dmp := diffmatchpatch.New()
+	dmp.DiffTimeout = 0
+	ca, cb, lines := dmp.DiffLinesToChars(a, b)
+	diffs := dmp.DiffCharsToLines(dmp.DiffMain(ca, cb, false), lines)
+
+	var all []diffLine
+	for _, d := range diffs {
+		text := d.Text
+		for text != "" {
+			i := strings.Index(text, "\n") + 1
+			if i == 0 {
+				i = len(text)
+			}
+			all = append(all, diffLine{Type: d.Type, Text: text[:i]})
+			text = text[i:]
+		}
+	}
+
+	out := &bytes.Buffer{}
+	out.WriteString("--- original\n+++ restored\n")
+	// lineA and lineB are the line numbers in a and b of all[i]
+	var lineA, lineB int
+	for i := 0; i < len(all); {
+		if all[i].Type == diffmatchpatch.DiffEqual {
+			i++
+			lineA++
+			lineB++
+			continue
+		}
+		// find the end of the hunk: the first run of more than 2*contextLines unchanged lines
+		// after a change, or the end of the file
+		end := i
+		for j := i; j < len(all); j++ {
+			if all[j].Type != diffmatchpatch.DiffEqual {
+				end = j + 1
+				continue
+			}
+			if j-end >= 2*contextLines {
+				break
+			}
+		}
+		start := i - contextLines
+		if start < 0 {
+			start = 0
+		}
+		if end += contextLines; end > len(all) {
+			end = len(all)
+		}
+		startA, startB := lineA-(i-start), lineB-(i-start)
+		var countA, countB int
+		hunk := &bytes.Buffer{}
+		for _, l := range all[start:end] {
+			switch l.Type {
+			case diffmatchpatch.DiffEqual:
+				hunk.WriteString(" ")
+				countA++
+				countB++
+			case diffmatchpatch.DiffDelete:
+				hunk.WriteString("-")
+				countA++
+			case diffmatchpatch.DiffInsert:
+				hunk.WriteString("+")
+				countB++
+			}
+			hunk.WriteString(l.Text)
+			if !strings.HasSuffix(l.Text, "\n") {
+				hunk.WriteString("\n\\ No newline at end of file\n")
+			}
+		}
+		fmt.Fprintf(out, "@@ -%s +%s @@\n", hunkRange(startA, countA), hunkRange(startB, countB))
+		out.Write(hunk.Bytes())
+		for _, l := range all[i:end] {
+			if l.Type != diffmatchpatch.DiffInsert {
+				lineA++
+			}
+			if l.Type != diffmatchpatch.DiffDelete {
+				lineB++
+			}
+		}
+		i = end
+	}
+	return out.String()
+}
+
+// hunkRange formats the range of a hunk header: start is the zero based index of the first line,
+// and count is the number of lines. An empty range is given the line number before it, as in
+// "diff -u".
+func hunkRange(start, count int) string {
+	switch count {
+	case 0:
+		return fmt.Sprintf("%d,0", start)
+	case 1:
+		return fmt.Sprintf("%d", start+1)
+	}
+	return fmt.Sprintf("%d,%d", start+1, count)
+}
+
+// textEdits returns the edits that transform a into b.
+func textEdits(a, b string) []TextEdit {
+	dmp := diffmatchpatch.New()
+	dmp.DiffTimeout = 0
+	diffs := dmp.DiffMain(a, b, true)
+
+	var edits []TextEdit
+	var offset int
+	for _, d := range diffs {
+		// a deletion followed by an insertion (or the reverse) are merged into one edit
+		var last *TextEdit
+		if len(edits) > 0 && edits[len(edits)-1].Offset+edits[len(edits)-1].Length == offset {
+			last = &edits[len(edits)-1]
+		}
+		switch d.Type {
+		case diffmatchpatch.DiffEqual:
+			offset += len(d.Text)
+		case diffmatchpatch.DiffDelete:
+			if last != nil {
+				last.Length += len(d.Text)
+			} else {
+				edits = append(edits, TextEdit{Offset: offset, Length: len(d.Text)})
+			}
+			offset += len(d.Text)
+		case diffmatchpatch.DiffInsert:
+			if last != nil {
+				last.NewText += d.Text
+			} else {
+				edits = append(edits, TextEdit{Offset: offset, NewText: d.Text})
+			}
+		}
+	}
+	return edits
+}
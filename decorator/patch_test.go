@@ -0,0 +1,177 @@
+package decorator
+
+import (
+	"go/token"
+	"testing"
+
+	"github.com/dave/dst"
+)
+
+func TestPatch(t *testing.T) {
+	src := `package main
+
+import "fmt"
+
+func main() {
+	a := 1
+	fmt.Println(a)
+
+	// b
+	fmt.Println(a + 1)
+}
+`
+	tests := []struct {
+		name   string
+		fn     func(f *dst.File)
+		expect string
+		edits  []TextEdit
+	}{
+		{
+			name: "rename",
+			fn: func(f *dst.File) {
+				dst.Inspect(f, func(n dst.Node) bool {
+					if id, ok := n.(*dst.Ident); ok && id.Name == "a" {
+						id.Name = "count"
+					}
+					return true
+				})
+			},
+			expect: "package main\n\nimport \"fmt\"\n\nfunc main() {\n\tcount := 1\n\tfmt.Println(count)\n\n\t// b\n\tfmt.Println(count + 1)\n}\n",
+			edits: []TextEdit{
+				{Offset: 43, Length: 1, NewText: "count"},
+				{Offset: 63, Length: 1, NewText: "count"},
+				{Offset: 86, Length: 1, NewText: "count"},
+			},
+		},
+		{
+			name: "insert",
+			fn: func(f *dst.File) {
+				body := f.Decls[1].(*dst.FuncDecl).Body
+				stmt := &dst.IncDecStmt{X: dst.NewIdent("a"), Tok: token.INC}
+				stmt.Decs.Before, stmt.Decs.After = dst.NewLine, dst.NewLine
+				body.List = append(body.List[:1], append([]dst.Stmt{stmt}, body.List[1:]...)...)
+			},
+			expect: "package main\n\nimport \"fmt\"\n\nfunc main() {\n\ta := 1\n\ta++\n\tfmt.Println(a)\n\n\t// b\n\tfmt.Println(a + 1)\n}\n",
+			edits: []TextEdit{
+				{Offset: 51, Length: 0, NewText: "a++\n\t"},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			f, err := Parse(src)
+			if err != nil {
+				t.Fatal(err)
+			}
+			test.fn(f)
+			edits, err := Patch([]byte(src), f)
+			if err != nil {
+				t.Fatal(err)
+			}
+			compare(t, test.expect, string(ApplyEdits([]byte(src), edits)))
+			if len(edits) != len(test.edits) {
+				t.Fatalf("expected %d edits, found %#v", len(test.edits), edits)
+			}
+			for i := range edits {
+				if edits[i] != test.edits[i] {
+					t.Errorf("edit %d: expected %#v, found %#v", i, test.edits[i], edits[i])
+				}
+			}
+		})
+	}
+}
+
+func TestUnifiedDiff(t *testing.T) {
+	src := `package main
+
+import "fmt"
+
+func main() {
+	a := 1
+	fmt.Println(a)
+
+	// b
+	fmt.Println(a + 1)
+}
+`
+	tests := []struct {
+		name    string
+		fn      func(f *dst.File)
+		context int
+		expect  string
+	}{
+		{
+			name:   "unchanged",
+			fn:     func(f *dst.File) {},
+			expect: "",
+		},
+		{
+			name: "insert",
+			fn: func(f *dst.File) {
+				body := f.Decls[1].(*dst.FuncDecl).Body
+				stmt := &dst.IncDecStmt{X: dst.NewIdent("a"), Tok: token.INC}
+				stmt.Decs.Before, stmt.Decs.After = dst.NewLine, dst.NewLine
+				body.List = append(body.List[:1], append([]dst.Stmt{stmt}, body.List[1:]...)...)
+			},
+			context: 2,
+			expect: `--- original
++++ restored
+@@ -5,4 +5,5 @@
+ func main() {
+ 	a := 1
++	a++
+ 	fmt.Println(a)
+ 
+`,
+		},
+		{
+			name: "insert-no-context",
+			fn: func(f *dst.File) {
+				body := f.Decls[1].(*dst.FuncDecl).Body
+				stmt := &dst.IncDecStmt{X: dst.NewIdent("a"), Tok: token.INC}
+				stmt.Decs.Before, stmt.Decs.After = dst.NewLine, dst.NewLine
+				body.List = append(body.List[:1], append([]dst.Stmt{stmt}, body.List[1:]...)...)
+			},
+			expect: `--- original
++++ restored
+@@ -6,0 +7 @@
++	a++
+`,
+		},
+		{
+			name: "two-hunks",
+			fn: func(f *dst.File) {
+				f.Name.Name = "foo"
+				body := f.Decls[1].(*dst.FuncDecl).Body
+				body.List[2].(*dst.ExprStmt).X.(*dst.CallExpr).Args[0].(*dst.BinaryExpr).Y.(*dst.BasicLit).Value = "2"
+			},
+			context: 1,
+			expect: `--- original
++++ restored
+@@ -1,2 +1,2 @@
+-package main
++package foo
+ 
+@@ -9,3 +9,3 @@
+ 	// b
+-	fmt.Println(a + 1)
++	fmt.Println(a + 2)
+ }
+`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			f, err := Parse(src)
+			if err != nil {
+				t.Fatal(err)
+			}
+			test.fn(f)
+			diff, err := UnifiedDiff([]byte(src), f, test.context)
+			if err != nil {
+				t.Fatal(err)
+			}
+			compare(t, test.expect, diff)
+		})
+	}
+}
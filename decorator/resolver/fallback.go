@@ -0,0 +1,32 @@
+package resolver
+
+import "go/ast"
+
+// WithFallback returns a DecoratorResolver that resolves identifiers with primary, and uses
+// fallback for the identifiers that primary returns an empty path or an error for. It's intended
+// for pairing gotypes.New (primary) with goast.New (fallback) on partially type-checked code: when
+// the type information is incomplete (e.g. the file has unresolved dependencies) the gotypes
+// resolver can't resolve some identifiers, but the import block of the file can. If both resolvers
+// fail, the error from primary is returned.
+func WithFallback(primary, fallback DecoratorResolver) DecoratorResolver {
+	return &fallbackResolver{primary: primary, fallback: fallback}
+}
+
+type fallbackResolver struct {
+	primary, fallback DecoratorResolver
+}
+
+func (r *fallbackResolver) ResolveIdent(file *ast.File, parent ast.Node, parentField string, id *ast.Ident) (string, error) {
+	path, err := r.primary.ResolveIdent(file, parent, parentField, id)
+	if err == nil && path != "" {
+		return path, nil
+	}
+	fpath, ferr := r.fallback.ResolveIdent(file, parent, parentField, id)
+	if ferr != nil {
+		if err != nil {
+			return "", err
+		}
+		return "", ferr
+	}
+	return fpath, nil
+}
@@ -0,0 +1,100 @@
+package resolver_test
+
+import (
+	"errors"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"testing"
+
+	"github.com/dave/dst"
+	"github.com/dave/dst/decorator"
+	"github.com/dave/dst/decorator/resolver"
+	"github.com/dave/dst/decorator/resolver/goast"
+	"github.com/dave/dst/decorator/resolver/gotypes"
+)
+
+func TestWithFallback(t *testing.T) {
+	src := `package main
+
+import (
+	"root/a"
+	b "root/b"
+)
+
+func main() {
+	a.A()
+	b.B()
+}
+`
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "main.go", src, parser.ParseComments)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// type-check with the b package missing, so there's no type information for b.B
+	info := &types.Info{Uses: map[*ast.Ident]types.Object{}}
+	conf := types.Config{
+		Importer: importerFunc(func(path string) (*types.Package, error) {
+			if path == "root/a" {
+				pkg := types.NewPackage("root/a", "a")
+				sig := types.NewSignature(nil, nil, nil, false)
+				pkg.Scope().Insert(types.NewFunc(token.NoPos, pkg, "A", sig))
+				pkg.MarkComplete()
+				return pkg, nil
+			}
+			return nil, errors.New("not found")
+		}),
+		Error: func(error) {},
+	}
+	conf.Check("main", fset, []*ast.File{f}, info)
+	for id := range info.Uses {
+		if id.Name == "b" {
+			// remove the placeholder package created for the failed import
+			delete(info.Uses, id)
+		}
+	}
+
+	paths := func(r resolver.DecoratorResolver) []string {
+		t.Helper()
+		d := decorator.NewDecoratorWithImports(fset, "main", r)
+		file, err := d.DecorateFile(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var found []string
+		dst.Inspect(file, func(n dst.Node) bool {
+			if call, ok := n.(*dst.CallExpr); ok {
+				var path string
+				if id, ok := call.Fun.(*dst.Ident); ok {
+					path = id.Path
+				}
+				found = append(found, path)
+			}
+			return true
+		})
+		return found
+	}
+
+	// without the fallback, b.B isn't resolved
+	found := paths(gotypes.New(info.Uses))
+	if len(found) != 2 || found[0] != "root/a" || found[1] != "" {
+		t.Errorf("unexpected paths %q", found)
+	}
+
+	fallback, set := resolver.Recording(goast.New())
+	found = paths(resolver.WithFallback(gotypes.New(info.Uses), fallback))
+	if len(found) != 2 || found[0] != "root/a" || found[1] != "root/b" {
+		t.Errorf("unexpected paths %q", found)
+	}
+	// the fallback is only used for the identifiers the primary can't resolve
+	if records := set.Records(); len(records) != 1 || records[0].Ident.Name != "B" {
+		t.Errorf("unexpected records %v", records)
+	}
+}
+
+type importerFunc func(path string) (*types.Package, error)
+
+func (f importerFunc) Import(path string) (*types.Package, error) { return f(path) }
@@ -52,7 +52,9 @@ func (r *DecoratorResolver) ResolveIdent(file *ast.File, parent ast.Node, parent
 	}
 
 	if xid.Obj != nil {
-		// Obj != nil -> not a qualified ident
+		// Obj != nil -> not a qualified ident. If the file was parsed with
+		// parser.SkipObjectResolution, Obj is always nil so local identifiers that shadow an
+		// import can't be detected.
 		return "", nil
 	}
 
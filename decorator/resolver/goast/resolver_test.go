@@ -1,4 +1,4 @@
-package goast
+package goast_test
 
 import (
 	"go/token"
@@ -6,6 +6,7 @@ import (
 
 	"github.com/dave/dst"
 	"github.com/dave/dst/decorator"
+	"github.com/dave/dst/decorator/resolver/goast"
 )
 
 func TestGoAstDecoratorResolver(t *testing.T) {
@@ -66,7 +67,7 @@ func TestGoAstDecoratorResolver(t *testing.T) {
 				t.Skip()
 			}
 
-			d := decorator.NewDecoratorWithImports(token.NewFileSet(), "main", New())
+			d := decorator.NewDecoratorWithImports(token.NewFileSet(), "main", goast.New())
 
 			f, err := d.Parse(test.src)
 			if err != nil {
@@ -1,7 +1,11 @@
 package gopackages
 
 import (
+	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/dave/dst/decorator/resolver"
 	"golang.org/x/tools/go/packages"
@@ -25,10 +29,24 @@ type RestorerResolver struct {
 
 	// Hints (package path -> name) is first checked before asking the packages package
 	Hints map[string]string
+
+	// CheckInternal returns an error wrapping resolver.ErrInternalImport when resolving an internal
+	// package that can't be imported by the importing package (the package in Dir, or Importer if
+	// set), according to the Go internal package rules.
+	CheckInternal bool
+	// Importer is the path of the importing package used by CheckInternal. If empty, the path of
+	// the package in Dir is loaded the first time it's needed.
+	Importer string
+
+	loadedImporter, loadedDir string // the path of the package in Dir loaded for CheckInternal
 }
 
 func (r *RestorerResolver) ResolvePackage(path string) (string, error) {
 
+	if err := r.checkInternal(path); err != nil {
+		return "", err
+	}
+
 	if name, ok := r.Hints[path]; ok {
 		return name, nil
 	}
@@ -41,21 +59,218 @@ func (r *RestorerResolver) ResolvePackage(path string) (string, error) {
 
 	pkgs, err := packages.Load(&r.Config, "pattern="+path)
 	if err != nil {
-		return "", err
+		return "", r.error(path, err)
 	}
 
 	if len(pkgs) > 1 {
-		return "", fmt.Errorf("%d packages found for %s, %s", len(pkgs), path, r.Config.Dir)
+		var ids []string
+		for _, p := range pkgs {
+			ids = append(ids, p.ID)
+		}
+		return "", r.error(path, fmt.Errorf("%w: %d packages found (%s)", resolver.ErrPackageAmbiguous, len(pkgs), strings.Join(ids, ", ")))
 	}
 	if len(pkgs) == 0 {
-		return "", resolver.ErrPackageNotFound
+		return "", r.error(path, resolver.ErrPackageNotFound)
 	}
 
 	p := pkgs[0]
 
 	if len(p.Errors) > 0 {
-		return "", p.Errors[0]
+		if p.Name == "" {
+			// no name means the package wasn't found
+			return "", r.error(path, fmt.Errorf("%w: %v", resolver.ErrPackageNotFound, p.Errors[0]))
+		}
+		return "", r.error(path, p.Errors[0])
 	}
 
 	return p.Name, nil
 }
+
+// ResolvePackages resolves several package paths with a single call to packages.Load. Only the
+// package names are loaded, so this is much faster than calling ResolvePackage for each path.
+func (r *RestorerResolver) ResolvePackages(paths []string) (map[string]string, error) {
+
+	names := map[string]string{}
+	var patterns []string
+	for _, path := range paths {
+		if err := r.checkInternal(path); err != nil {
+			return nil, err
+		}
+		if name, ok := r.Hints[path]; ok {
+			names[path] = name
+			continue
+		}
+		patterns = append(patterns, "pattern="+path)
+	}
+	if len(patterns) == 0 {
+		return names, nil
+	}
+
+	if r.Dir != "" {
+		r.Config.Dir = r.Dir
+	}
+	config := r.Config
+	config.Mode = packages.NeedName
+	config.Tests = false
+
+	pkgs, err := packages.Load(&config, patterns...)
+	if err != nil {
+		return nil, r.error(strings.Join(paths, ", "), err)
+	}
+
+	found := map[string][]*packages.Package{}
+	for _, p := range pkgs {
+		found[p.PkgPath] = append(found[p.PkgPath], p)
+	}
+	for _, path := range paths {
+		if _, ok := names[path]; ok {
+			continue
+		}
+		ps := found[path]
+		if len(ps) > 1 {
+			var ids []string
+			for _, p := range ps {
+				ids = append(ids, p.ID)
+			}
+			return nil, r.error(path, fmt.Errorf("%w: %d packages found (%s)", resolver.ErrPackageAmbiguous, len(ps), strings.Join(ids, ", ")))
+		}
+		if len(ps) == 0 || ps[0].Name == "" {
+			if len(ps) > 0 && len(ps[0].Errors) > 0 {
+				return nil, r.error(path, fmt.Errorf("%w: %v", resolver.ErrPackageNotFound, ps[0].Errors[0]))
+			}
+			return nil, r.error(path, resolver.ErrPackageNotFound)
+		}
+		names[path] = ps[0].Name
+	}
+	return names, nil
+}
+
+// checkInternal returns an error if CheckInternal is set and path is an internal package that
+// can't be imported by the importing package.
+func (r *RestorerResolver) checkInternal(path string) error {
+	if !r.CheckInternal {
+		return nil
+	}
+	i := findInternal(path)
+	if i < 0 {
+		return nil
+	}
+	importer, err := r.importer()
+	if err != nil {
+		return r.error(path, err)
+	}
+	// the internal package can be imported by packages rooted at the parent of "internal"
+	parent := strings.TrimSuffix(path[:i], "/")
+	if parent == "" {
+		// standard library internal packages can only be imported by the standard library
+		if !strings.Contains(strings.Split(importer, "/")[0], ".") {
+			return nil
+		}
+	} else if importer == parent || strings.HasPrefix(importer, parent+"/") {
+		return nil
+	}
+	return r.error(path, fmt.Errorf("%w: imported by %s", resolver.ErrInternalImport, importer))
+}
+
+// importer returns Importer, or the path of the package in Dir. The package is only loaded once for
+// each Dir.
+func (r *RestorerResolver) importer() (string, error) {
+	if r.Importer != "" {
+		return r.Importer, nil
+	}
+	if r.Dir != "" {
+		r.Config.Dir = r.Dir
+	}
+	if r.loadedImporter != "" && r.loadedDir == r.Config.Dir {
+		return r.loadedImporter, nil
+	}
+	config := r.Config
+	config.Mode = packages.NeedName
+	config.Tests = false
+	pkgs, err := packages.Load(&config, ".")
+	if err != nil {
+		return "", err
+	}
+	if len(pkgs) != 1 || pkgs[0].PkgPath == "" {
+		return "", errors.New("importing package not found")
+	}
+	r.loadedImporter, r.loadedDir = pkgs[0].PkgPath, r.Config.Dir
+	return r.loadedImporter, nil
+}
+
+// findInternal returns the index of the last "internal" element of path, or -1 if there is none.
+func findInternal(path string) int {
+	switch {
+	case strings.HasSuffix(path, "/internal"):
+		return len(path) - len("internal")
+	case strings.Contains(path, "/internal/"):
+		return strings.LastIndex(path, "/internal/") + 1
+	case path == "internal", strings.HasPrefix(path, "internal/"):
+		return 0
+	}
+	return -1
+}
+
+func (r *RestorerResolver) error(path string, err error) error {
+	return &ResolveError{
+		Path:       path,
+		Dir:        r.Config.Dir,
+		ModuleRoot: moduleRoot(r.Config.Dir),
+		BuildFlags: r.Config.BuildFlags,
+		Env:        r.Config.Env,
+		Err:        err,
+	}
+}
+
+// ResolveError is returned by RestorerResolver when a package can't be resolved. Use errors.Is
+// with resolver.ErrPackageNotFound or resolver.ErrPackageAmbiguous to check the cause.
+type ResolveError struct {
+	Path       string   // The package path being resolved
+	Dir        string   // The directory packages were loaded from
+	ModuleRoot string   // The directory containing the go.mod file for Dir, if found
+	BuildFlags []string // The BuildFlags in the packages.Config
+	Env        []string // The Env in the packages.Config (nil means the current environment)
+	Err        error
+}
+
+func (e *ResolveError) Error() string {
+	s := fmt.Sprintf("resolving package %s (dir %q", e.Path, e.Dir)
+	if e.ModuleRoot != "" {
+		s += fmt.Sprintf(", module root %q", e.ModuleRoot)
+	}
+	if len(e.BuildFlags) > 0 {
+		s += fmt.Sprintf(", build flags %q", e.BuildFlags)
+	}
+	if len(e.Env) > 0 {
+		s += fmt.Sprintf(", env %q", e.Env)
+	}
+	return s + "): " + e.Err.Error()
+}
+
+func (e *ResolveError) Unwrap() error {
+	return e.Err
+}
+
+// moduleRoot returns the closest directory at or above dir that contains a go.mod file.
+func moduleRoot(dir string) string {
+	if dir == "" {
+		var err error
+		if dir, err = os.Getwd(); err != nil {
+			return ""
+		}
+	}
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return ""
+	}
+	for {
+		if fi, err := os.Stat(filepath.Join(dir, "go.mod")); err == nil && !fi.IsDir() {
+			return dir
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
@@ -1,14 +1,51 @@
 package gopackages_test
 
 import (
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/dave/dst/decorator/resolver"
 	"github.com/dave/dst/decorator/resolver/gopackages"
 )
 
+func TestRestorerResolverError(t *testing.T) {
+	root, err := tempDir(map[string]string{
+		"main/main.go": "package main \n\n func main(){}",
+		"go.mod":       "module root",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	r := gopackages.New(filepath.Join(root, "main"))
+	r.Config.BuildFlags = []string{"-tags=foo"}
+	_, err = r.ResolvePackage("root/bar")
+	if err == nil {
+		t.Fatal("expected error, found none")
+	}
+	if !errors.Is(err, resolver.ErrPackageNotFound) {
+		t.Errorf("expected ErrPackageNotFound, found %v", err)
+	}
+	if errors.Is(err, resolver.ErrPackageAmbiguous) {
+		t.Errorf("unexpected ErrPackageAmbiguous: %v", err)
+	}
+	var rerr *gopackages.ResolveError
+	if !errors.As(err, &rerr) {
+		t.Fatalf("expected *gopackages.ResolveError, found %T", err)
+	}
+	for _, s := range []string{"root/bar", root, "-tags=foo"} {
+		if !strings.Contains(err.Error(), s) {
+			t.Errorf("expected error to contain %q, found %q", s, err.Error())
+		}
+	}
+}
+
 func TestRestorerResolver(t *testing.T) {
 	type tc struct{ importPath, fromDir, expectName string }
 	tests := []struct {
@@ -36,6 +73,7 @@ func TestRestorerResolver(t *testing.T) {
 			},
 			cases: []tc{
 				{"root/foo", "/main", "foo"},
+				{"root/bar", "/main", ""},
 			},
 		},
 	}
@@ -62,7 +100,7 @@ func TestRestorerResolver(t *testing.T) {
 				if end != nil {
 					end() // delete temp dir if created
 				}
-				if err == resolver.ErrPackageNotFound {
+				if errors.Is(err, resolver.ErrPackageNotFound) {
 					name = ""
 				} else if err != nil {
 					t.Errorf("error resolving path %s from dir %s: %v", c.importPath, fromDir, err)
@@ -74,3 +112,134 @@ func TestRestorerResolver(t *testing.T) {
 		})
 	}
 }
+
+func TestRestorerResolverBulk(t *testing.T) {
+	root, err := tempDir(map[string]string{
+		"main/main.go": "package main \n\n func main(){}",
+		"foo/foo.go":   "package foo \n\n func A(){}",
+		"bar/bar.go":   "package baz \n\n func B(){}",
+		"go.mod":       "module root",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	r := gopackages.WithHints(filepath.Join(root, "main"), map[string]string{"root/qux": "qux"})
+	names, err := r.ResolvePackages([]string{"root/foo", "root/bar", "root/qux"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := map[string]string{"root/foo": "foo", "root/bar": "baz", "root/qux": "qux"}
+	if !reflect.DeepEqual(expect, names) {
+		t.Errorf("expected %v, found %v", expect, names)
+	}
+
+	_, err = r.ResolvePackages([]string{"root/foo", "root/missing"})
+	if !errors.Is(err, resolver.ErrPackageNotFound) {
+		t.Errorf("expected ErrPackageNotFound, found %v", err)
+	}
+}
+
+func benchmarkResolve(b *testing.B, resolve func(r *gopackages.RestorerResolver, paths []string) error) {
+	src := map[string]string{"go.mod": "module root", "main/main.go": "package main \n\n func main(){}"}
+	var paths []string
+	for i := 0; i < 10; i++ {
+		src[fmt.Sprintf("p%d/p.go", i)] = fmt.Sprintf("package p%d", i)
+		paths = append(paths, fmt.Sprintf("root/p%d", i))
+	}
+	root, err := tempDir(src)
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+	r := gopackages.New(filepath.Join(root, "main"))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := resolve(r, paths); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkResolvePackage(b *testing.B) {
+	benchmarkResolve(b, func(r *gopackages.RestorerResolver, paths []string) error {
+		for _, path := range paths {
+			if _, err := r.ResolvePackage(path); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func BenchmarkResolvePackages(b *testing.B) {
+	benchmarkResolve(b, func(r *gopackages.RestorerResolver, paths []string) error {
+		_, err := r.ResolvePackages(paths)
+		return err
+	})
+}
+
+func TestCheckInternal(t *testing.T) {
+	root, err := tempDir(map[string]string{
+		"a/a.go":                "package a",
+		"a/b/b.go":              "package b",
+		"a/internal/foo/foo.go": "package foo",
+		"c/c.go":                "package c",
+		"go.mod":                "module root",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	tests := []struct {
+		dir, importer string
+		allowed       bool
+	}{
+		{dir: "a", allowed: true},
+		{dir: "a/b", allowed: true},
+		{dir: "c", allowed: false},
+		{dir: "c", importer: "root/a/b", allowed: true},
+		{dir: "a", importer: "root/ab", allowed: false},
+	}
+	for _, test := range tests {
+		r := gopackages.New(filepath.Join(root, test.dir))
+		r.CheckInternal = true
+		r.Importer = test.importer
+		names, err := r.ResolvePackages([]string{"root/a/internal/foo"})
+		if test.allowed {
+			if err != nil {
+				t.Errorf("%s %s: %v", test.dir, test.importer, err)
+			} else if names["root/a/internal/foo"] != "foo" {
+				t.Errorf("%s %s: unexpected names %v", test.dir, test.importer, names)
+			}
+			continue
+		}
+		if !errors.Is(err, resolver.ErrInternalImport) {
+			t.Errorf("%s %s: expected ErrInternalImport, found %v", test.dir, test.importer, err)
+		}
+		if _, err := r.ResolvePackage("root/a/internal/foo"); !errors.Is(err, resolver.ErrInternalImport) {
+			t.Errorf("%s %s: expected ErrInternalImport, found %v", test.dir, test.importer, err)
+		}
+	}
+
+	// the importing package is only loaded once
+	r := gopackages.New(filepath.Join(root, "c"))
+	r.CheckInternal = true
+	if _, err := r.ResolvePackage("root/a/internal/foo"); !errors.Is(err, resolver.ErrInternalImport) {
+		t.Errorf("expected ErrInternalImport, found %v", err)
+	}
+	if err := os.Remove(filepath.Join(root, "c", "c.go")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.ResolvePackages([]string{"root/a/internal/foo"}); !errors.Is(err, resolver.ErrInternalImport) {
+		t.Errorf("expected ErrInternalImport, found %v", err)
+	}
+
+	// without CheckInternal, the package is resolved
+	names, err := gopackages.New(filepath.Join(root, "c")).ResolvePackages([]string{"root/a/internal/foo"})
+	if err != nil || names["root/a/internal/foo"] != "foo" {
+		t.Errorf("unexpected result %v, %v", names, err)
+	}
+}
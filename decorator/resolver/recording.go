@@ -0,0 +1,101 @@
+package resolver
+
+import (
+	"go/ast"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+// Recording wraps a DecoratorResolver so that every remote identifier resolved during decoration is
+// recorded in the returned RecordSet. The wrapped resolver returns exactly the same results as
+// inner. The paths are recorded as the decorator keeps them: vendor prefixes are removed (see
+// StripVendor), and identifiers in the package being decorated are not recorded.
+func Recording(inner DecoratorResolver) (DecoratorResolver, *RecordSet) {
+	set := &RecordSet{}
+	return &recordingResolver{inner: inner, set: set}, set
+}
+
+type recordingResolver struct {
+	inner DecoratorResolver
+	set   *RecordSet
+}
+
+func (r *recordingResolver) ResolveIdent(file *ast.File, parent ast.Node, parentField string, id *ast.Ident) (string, error) {
+	path, err := r.inner.ResolveIdent(file, parent, parentField, id)
+	if err != nil {
+		return "", err
+	}
+	if kept := StripVendor(path); kept != "" && !local(file, parent, parentField, kept) {
+		r.set.add(Record{Ident: id, Path: kept})
+	}
+	return path, nil
+}
+
+// local returns true if an identifier resolved to path is in the package being decorated. A
+// package can't import itself, so the identifier is in the package if it isn't qualified, unless
+// path is dot-imported by file.
+func local(file *ast.File, parent ast.Node, parentField string, path string) bool {
+	if _, ok := parent.(*ast.SelectorExpr); ok && parentField == "Sel" {
+		return false
+	}
+	for _, imp := range file.Imports {
+		if imp.Name == nil || imp.Name.Name != "." {
+			continue
+		}
+		if p, err := strconv.Unquote(imp.Path.Value); err == nil && StripVendor(p) == path {
+			return false
+		}
+	}
+	return true
+}
+
+// Record is an identifier that was resolved to a package path.
+type Record struct {
+	Ident *ast.Ident
+	Path  string
+}
+
+// RecordSet accumulates the identifiers resolved by a resolver created with Recording. It is safe
+// for concurrent use.
+type RecordSet struct {
+	m       sync.Mutex
+	records []Record
+}
+
+func (s *RecordSet) add(r Record) {
+	s.m.Lock()
+	defer s.m.Unlock()
+	s.records = append(s.records, r)
+}
+
+// Records returns all recorded identifiers in the order they were resolved.
+func (s *RecordSet) Records() []Record {
+	s.m.Lock()
+	defer s.m.Unlock()
+	return append([]Record(nil), s.records...)
+}
+
+// Paths returns the sorted, de-duplicated list of package paths that were resolved.
+func (s *RecordSet) Paths() []string {
+	s.m.Lock()
+	defer s.m.Unlock()
+	found := map[string]bool{}
+	var paths []string
+	for _, r := range s.records {
+		if found[r.Path] {
+			continue
+		}
+		found[r.Path] = true
+		paths = append(paths, r.Path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// Reset clears all recorded identifiers.
+func (s *RecordSet) Reset() {
+	s.m.Lock()
+	defer s.m.Unlock()
+	s.records = nil
+}
@@ -0,0 +1,75 @@
+package resolver_test
+
+import (
+	"go/ast"
+	"go/token"
+	"strings"
+	"testing"
+
+	"github.com/dave/dst/decorator"
+	"github.com/dave/dst/decorator/resolver"
+	"github.com/dave/dst/decorator/resolver/goast"
+)
+
+func TestRecording(t *testing.T) {
+	src := `package main
+
+		import (
+			"fmt"
+			"root/a"
+			b "root/b"
+			_ "root/c"
+			"root/vendor/v"
+		)
+
+		func main() {
+			fmt.Println(a.A, b.B())
+			fmt.Printf("%v", a.AA, v.V)
+		}`
+
+	res, set := resolver.Recording(goast.New())
+	d := decorator.NewDecoratorWithImports(token.NewFileSet(), "main", res)
+	if _, err := d.Parse(src); err != nil {
+		t.Fatal(err)
+	}
+
+	if found, expect := strings.Join(set.Paths(), " "), "fmt root/a root/b v"; found != expect {
+		t.Errorf("\nexpect: %q\nfound : %q", expect, found)
+	}
+
+	var idents []string
+	for _, r := range set.Records() {
+		idents = append(idents, r.Path+"."+r.Ident.Name)
+	}
+	if found, expect := strings.Join(idents, " "), "fmt.Println root/a.A root/b.B fmt.Printf root/a.AA v.V"; found != expect {
+		t.Errorf("\nexpect: %q\nfound : %q", expect, found)
+	}
+
+	// the identifiers in the package being decorated are not recorded, as for the decorator
+	res, set = resolver.Recording(localResolver{goast.New()})
+	d = decorator.NewDecoratorWithImports(token.NewFileSet(), "root/x", res)
+	if _, err := d.Parse(src + "\n\nfunc local() {}\n\nvar _ = local"); err != nil {
+		t.Fatal(err)
+	}
+	if found, expect := strings.Join(set.Paths(), " "), "fmt root/a root/b v"; found != expect {
+		t.Errorf("\nexpect: %q\nfound : %q", expect, found)
+	}
+
+	set.Reset()
+	if len(set.Records()) != 0 {
+		t.Error("expected no records after Reset")
+	}
+}
+
+// localResolver resolves the unqualified identifier "local" to "root/x", the path of the package
+// being decorated, as resolvers using type information do.
+type localResolver struct {
+	resolver.DecoratorResolver
+}
+
+func (r localResolver) ResolveIdent(file *ast.File, parent ast.Node, parentField string, id *ast.Ident) (string, error) {
+	if id.Name == "local" {
+		return "root/x", nil
+	}
+	return r.DecoratorResolver.ResolveIdent(file, parent, parentField, id)
+}
@@ -3,6 +3,7 @@ package resolver
 import (
 	"errors"
 	"go/ast"
+	"strings"
 )
 
 // RestorerResolver resolves a package path to a package name.
@@ -10,6 +11,14 @@ type RestorerResolver interface {
 	ResolvePackage(path string) (string, error)
 }
 
+// BulkPackageResolver is an optional interface for a RestorerResolver that can resolve many
+// package paths at once more efficiently than one at a time. The returned map (package path ->
+// name) must contain every path, or an error must be returned. Callers should detect and use this
+// interface when resolving more than one path.
+type BulkPackageResolver interface {
+	ResolvePackages(paths []string) (map[string]string, error)
+}
+
 // DecoratorResolver resolves an identifier to a local or remote reference.
 //
 // Returns path == "" if the node is not a local or remote reference (e.g. a field in a composite
@@ -22,5 +31,34 @@ type DecoratorResolver interface {
 	ResolveIdent(file *ast.File, parent ast.Node, parentField string, id *ast.Ident) (path string, err error)
 }
 
+// StripVendor removes the vendor prefix of a path returned by a DecoratorResolver (e.g. "a/vendor/b"
+// becomes "b"), as the decorator does before keeping the path.
+func StripVendor(path string) string {
+	findVendor := func(path string) (index int, ok bool) {
+		// Two cases, depending on internal at start of string or not.
+		// The order matters: we must return the index of the final element,
+		// because the final one is where the effective import path starts.
+		switch {
+		case strings.Contains(path, "/vendor/"):
+			return strings.LastIndex(path, "/vendor/") + 1, true
+		case strings.HasPrefix(path, "vendor/"):
+			return 0, true
+		}
+		return 0, false
+	}
+	i, ok := findVendor(path)
+	if !ok {
+		return path
+	}
+	return path[i+len("vendor/"):]
+}
+
 // ErrPackageNotFound means the package is not found
 var ErrPackageNotFound = errors.New("package not found")
+
+// ErrPackageAmbiguous means more than one package was found for the path
+var ErrPackageAmbiguous = errors.New("package ambiguous")
+
+// ErrInternalImport means the package is an internal package that can't be imported by the
+// importing package
+var ErrInternalImport = errors.New("use of internal package not allowed")
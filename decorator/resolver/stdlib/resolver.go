@@ -0,0 +1,19 @@
+package stdlib
+
+import "github.com/dave/dst/decorator/resolver"
+
+func New() PackageResolver {
+	return PackageResolver{}
+}
+
+// PackageResolver resolves the package names of standard library packages from a table generated
+// from GOROOT, so no packages are loaded. For any other path resolver.ErrPackageNotFound is
+// returned, so it can be used in front of a resolver for the remaining packages.
+type PackageResolver struct{}
+
+func (PackageResolver) ResolvePackage(importPath string) (string, error) {
+	if n, ok := packages[importPath]; ok {
+		return n, nil
+	}
+	return "", resolver.ErrPackageNotFound
+}
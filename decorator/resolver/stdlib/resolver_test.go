@@ -0,0 +1,121 @@
+package stdlib_test
+
+import (
+	"go/build/constraint"
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"unicode"
+
+	"github.com/dave/dst/decorator/resolver"
+	"github.com/dave/dst/decorator/resolver/stdlib"
+)
+
+func TestRestorerResolver(t *testing.T) {
+	type tc struct{ importPath, expectName string }
+	cases := []tc{
+		{"fmt", "fmt"},
+		{"math/rand/v2", "rand"},
+		{"go/build/constraint", "constraint"},
+		{"syscall/js", "js"},
+		{"internal/abi", ""},
+		{"vendor/golang.org/x/net/dns/dnsmessage", ""},
+		{"github.com/dave/dst", ""},
+	}
+	r := stdlib.New()
+	for _, c := range cases {
+		name, err := r.ResolvePackage(c.importPath)
+		if err == resolver.ErrPackageNotFound {
+			name = ""
+		} else if err != nil {
+			t.Errorf("error resolving path %s: %v", c.importPath, err)
+		}
+		if name != c.expectName {
+			t.Errorf("package %s - expected %s, got %s", c.importPath, c.expectName, name)
+		}
+	}
+}
+
+// TestGoroot checks the table matches the standard library of the current GOROOT, for all
+// platforms. Regenerate the table with "go run ./gendst" if it fails after a Go upgrade.
+func TestGoroot(t *testing.T) {
+	src := filepath.Join(runtime.GOROOT(), "src")
+	if _, err := os.Stat(src); err != nil {
+		t.Skipf("GOROOT source: %v", err)
+	}
+	r := stdlib.New()
+	err := filepath.Walk(src, func(dir string, info os.FileInfo, err error) error {
+		if err != nil || !info.IsDir() {
+			return err
+		}
+		path, err := filepath.Rel(src, dir)
+		if err != nil {
+			return err
+		}
+		path = filepath.ToSlash(path)
+		switch {
+		case path == "." || path == "builtin":
+			return nil
+		case path == "cmd", path == "vendor", info.Name() == "testdata", strings.Contains("/"+path+"/", "/internal/"),
+			strings.HasPrefix(info.Name(), "_"), strings.HasPrefix(info.Name(), "."):
+			return filepath.SkipDir
+		}
+		name := goPackageName(t, dir)
+		if name == "" {
+			return nil
+		}
+		found, err := r.ResolvePackage(path)
+		if err != nil {
+			t.Errorf("package %s - %v", path, err)
+			return nil
+		}
+		if found != name {
+			t.Errorf("package %s - expected %s, got %s", path, name, found)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// goPackageName returns the package name of the first Go file in dir for any platform, excluding
+// test files and files with the "ignore" build tag.
+func goPackageName(t *testing.T, dir string) string {
+	t.Helper()
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range files {
+		if file.IsDir() || !strings.HasSuffix(file.Name(), ".go") || strings.HasSuffix(file.Name(), "_test.go") {
+			continue
+		}
+		f, err := parser.ParseFile(token.NewFileSet(), filepath.Join(dir, file.Name()), nil, parser.PackageClauseOnly|parser.ParseComments)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var ignore bool
+		for _, group := range f.Comments {
+			for _, c := range group.List {
+				if !constraint.IsGoBuild(c.Text) && !constraint.IsPlusBuild(c.Text) {
+					continue
+				}
+				for _, field := range strings.FieldsFunc(c.Text, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' }) {
+					if field == "ignore" {
+						ignore = true
+					}
+				}
+			}
+		}
+		if !ignore {
+			return f.Name.Name
+		}
+	}
+	return ""
+}
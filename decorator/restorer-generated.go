@@ -86,6 +86,13 @@ func (r *FileRestorer) restoreNode(n dst.Node, parentName, parentField, parentFi
 
 		return out
 	case *dst.BadDecl:
+
+		// Special case for bad declarations - restore the original source if available and
+		// the file is being printed
+		if n.Source != "" && r.printing {
+			return r.restoreBadDeclSource(n)
+		}
+
 		out := &ast.BadDecl{}
 		r.Ast.Nodes[n] = out
 		r.Dst.Nodes[out] = n
@@ -105,6 +112,13 @@ func (r *FileRestorer) restoreNode(n dst.Node, parentName, parentField, parentFi
 
 		return out
 	case *dst.BadExpr:
+
+		// Special case for bad nodes - restore the original source if available and the
+		// file is being printed
+		if n.Source != "" && r.printing {
+			return r.restoreBadSource(n, n.Source)
+		}
+
 		out := &ast.BadExpr{}
 		r.Ast.Nodes[n] = out
 		r.Dst.Nodes[out] = n
@@ -124,6 +138,13 @@ func (r *FileRestorer) restoreNode(n dst.Node, parentName, parentField, parentFi
 
 		return out
 	case *dst.BadStmt:
+
+		// Special case for bad nodes - restore the original source if available and the
+		// file is being printed
+		if n.Source != "" && r.printing {
+			return r.restoreBadSource(n, n.Source)
+		}
+
 		out := &ast.BadStmt{}
 		r.Ast.Nodes[n] = out
 		r.Dst.Nodes[out] = n
@@ -989,8 +1010,8 @@ func (r *FileRestorer) restoreNode(n dst.Node, parentName, parentField, parentFi
 
 		// String: Name
 		out.NamePos = r.cursor
-		out.Name = n.Name
-		r.cursor += token.Pos(len(n.Name))
+		out.Name = r.identName(n)
+		r.cursor += token.Pos(len(out.Name))
 
 		// Decoration: End
 		r.applyDecorations(out, n.Decs.End, true)
@@ -1864,7 +1885,7 @@ func (r *FileRestorer) restoreNode(n dst.Node, parentName, parentField, parentFi
 		}
 
 		// Token: Assign
-		if n.Values != nil {
+		if len(n.Values) > 0 {
 			r.cursor += token.Pos(len(token.ASSIGN.String()))
 		}
 
@@ -1,18 +1,22 @@
 package decorator
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
 	"go/ast"
-	"go/format"
+	"go/printer"
 	"go/token"
 	"io"
 	"os"
+	"regexp"
 	"sort"
 	"strconv"
 	"strings"
 
 	"github.com/dave/dst"
 	"github.com/dave/dst/decorator/resolver"
+	"github.com/dave/dst/dstutil"
 )
 
 // NewRestorer returns a restorer.
@@ -37,6 +41,94 @@ type Restorer struct {
 	Fset   *token.FileSet // Fset is the *token.FileSet in use. Set this to use a pre-existing FileSet.
 	Extras bool           // Resore Objects, Scopes etc. Not needed for printing the resultant AST. If set to true, Objects and Scopes must be carefully managed to avoid duplicate nodes.
 
+	// BaseIndent is the number of tabs that every line is indented by when printing with Print or
+	// Fprint. The contents of multi-line raw string literals are not indented.
+	BaseIndent int
+
+	// FormatDocComments reformats the package doc and the doc comments of top-level declarations
+	// in the same way as gofmt (Go 1.19 and later; with earlier versions doc comments are left
+	// unchanged). The decorations of the file are not modified. Other comments are left unchanged.
+	FormatDocComments bool
+
+	// IdentFormatter, if set, is called for each identifier in the restored file, and the
+	// returned name is printed instead. Remote identifiers (those with Path set to a package other
+	// than the local package) and the package names that qualify them are not passed to
+	// IdentFormatter. The dst nodes are not modified.
+	IdentFormatter func(name string, kind IdentKind) string
+
+	// PreserveCommentWhitespace prints the text of multi-line block comments exactly as it is in
+	// the decorations when printing with Print or Fprint. By default the printer removes trailing
+	// white space from each line and may change the indentation of the lines.
+	PreserveCommentWhitespace bool
+
+	// WrapLongLines, if greater than zero, is the maximum line width when printing with Print or
+	// Fprint. The arguments of calls and the elements of composite literals on longer lines are
+	// wrapped onto separate lines by adding newline decorations to a copy of the file, so the dst
+	// nodes are unchanged (and the Map refers to the nodes of the copy). Nodes with any existing
+	// decorations on their arguments or elements are never wrapped. Tabs are counted as 8 columns.
+	WrapLongLines int
+
+	// SpacesOnly, if greater than zero, is the width of an indent when printing with Print or
+	// Fprint, and the output contains no tabs except inside raw string literals: the code is
+	// indented and aligned with spaces, tabs in comments are replaced with SpacesOnly spaces, and
+	// tabs in interpreted string and rune literals are replaced with the \t escape sequence. This
+	// is useful for output that is embedded in a format with strict indentation (e.g. YAML).
+	SpacesOnly int
+
+	// OmitFileDecorations suppresses the Start and End decorations of the *dst.File (e.g. the
+	// package doc and a license header), which is useful when restoring declarations that will be
+	// spliced into another file. The decorations of the declarations (including comments after the
+	// last declaration, which are attached to it) are restored as usual. The dst nodes are not
+	// modified.
+	OmitFileDecorations bool
+
+	// ParenMode controls how parenthesized expressions are restored (see ParenMode). The default
+	// ParenPreserve restores the ParenExpr nodes exactly as they are in the tree. The dst nodes are
+	// not modified.
+	ParenMode ParenMode
+
+	// EmptyInterfaceStyle controls whether empty interface types are converted between
+	// "interface{}" and "any" (see EmptyInterfaceStyle). The default EmptyInterfacePreserve
+	// restores them exactly as they are in the tree. EmptyInterfaceAny produces code that needs go
+	// 1.18 or later. The dst nodes are not modified.
+	EmptyInterfaceStyle EmptyInterfaceStyle
+
+	// CommentStyle controls whether comments are converted between line and block comments (see
+	// CommentStyle). The default CommentPreserve restores the comments exactly as they are in the
+	// decorations. The dst nodes are not modified.
+	CommentStyle CommentStyle
+
+	// MaxBlankLines, if greater than one, is the maximum number of consecutive empty lines printed
+	// when printing with Print or Fprint. The empty lines are taken from the Before spacing of the
+	// nodes (see dst.SpaceType) and consecutive "\n" decorations, which the decorator records when
+	// Decorator.MaxBlankLines is set. By default several empty lines are printed as one, as gofmt
+	// does.
+	MaxBlankLines int
+
+	// NormalizeCommentSpacing adds a space after the "//" or "/*" of comments that have text
+	// directly after the comment marker (e.g. "//a" is restored as "// a", and "/*a*/" as
+	// "/* a */"), as many linters require. Directives (e.g. "//go:generate", "//line" and
+	// "//export") and "//nolint" comments are left unchanged, and the text of the comments is
+	// otherwise unchanged. The dst nodes are not modified.
+	NormalizeCommentSpacing bool
+
+	// FinalNewline controls the newlines at the end of the output of Print and Fprint (see
+	// FinalNewline). The default FinalNewlineSingle ends the output with a single newline.
+	FinalNewline FinalNewline
+	// FinalNewlines is the number of newlines at the end of each file when it was decorated, used
+	// when FinalNewline is FinalNewlinePreserve. Set this to Decorator.FinalNewlines.
+	FinalNewlines map[*dst.File]int
+
+	// MinimalReformat, if set, is the original source of the file printed with Print or Fprint. The
+	// declarations that are unchanged from the original source are printed exactly as they are in
+	// it, even if they are not formatted as gofmt would, and only the changed declarations are
+	// printed from the dst nodes. The same is done for the empty lines and comments between the
+	// unchanged declarations, so the output of a transformation differs from the original source only
+	// where the file was changed. Changes are found by comparing the printed declarations, so no
+	// tracking of the modified nodes is needed. This should not be combined with BaseIndent or
+	// SpacesOnly.
+	MinimalReformat []byte
+
 	// If a Resolver is provided, the names of all imported packages are resolved, and the imports
 	// block is updated. All remote identifiers are updated (sometimes this involves changing
 	// SelectorExpr.X.Name, or even swapping between Ident and SelectorExpr). To force specific
@@ -44,6 +136,17 @@ type Restorer struct {
 	Resolver resolver.RestorerResolver
 	// Local package path - required if Resolver is set.
 	Path string
+
+	// LocalPrefix is a comma separated list of import path prefixes, as in the goimports -local
+	// flag. When the Resolver adds imports, packages matching a prefix are grouped after the
+	// standard library and third party packages, separated by an empty line.
+	LocalPrefix string
+
+	// ImportComments maps import paths to comments that are added to the End decorations of the
+	// import specs added by the Resolver, so they are printed on the same line as the import (e.g.
+	// to record why a forked package is imported). The "// " prefix is added to comments that
+	// don't start with "//" or "/*". Existing import specs are not changed.
+	ImportComments map[string]string
 }
 
 // Print uses format.Node to print a *dst.File to stdout
@@ -53,11 +156,118 @@ func (pr *Restorer) Print(f *dst.File) error {
 
 // Fprint uses format.Node to print a *dst.File to a writer
 func (pr *Restorer) Fprint(w io.Writer, f *dst.File) error {
-	af, err := pr.RestoreFile(f)
+	return pr.FileRestorer().Fprint(w, f)
+}
+
+// FprintDecl prints decl, which must be a top-level declaration of f, as a standalone file to a
+// writer. The output contains the package clause of f, the imports that decl requires (see
+// RequiredImports) and decl with its decorations, so a Resolver is required. f is not modified.
+func (pr *Restorer) FprintDecl(w io.Writer, f *dst.File, decl dst.Decl) error {
+	return pr.FileRestorer().FprintDecl(w, f, decl)
+}
+
+// formatFile prints a restored *ast.File, with the markers of bad nodes replaced by their
+// source, and the newlines at the end of the output set according to FinalNewline.
+func (r *FileRestorer) formatFile(w io.Writer, af *ast.File, file *dst.File) error {
+	if r.FinalNewline == FinalNewlineSingle && r.MaxBlankLines <= 1 && r.MinimalReformat == nil && len(r.badSources) == 0 {
+		return r.format(w, af, r.nonce)
+	}
+	buf := &bytes.Buffer{}
+	if err := r.format(buf, af, r.nonce); err != nil {
+		return err
+	}
+	out := buf.Bytes()
+	for printed, source := range r.badSources {
+		out = bytes.Replace(out, []byte(printed), []byte(source), 1)
+	}
+	if r.MaxBlankLines > 1 {
+		out = r.blankLineMarkers().ReplaceAll(out, nil)
+	}
+	if r.MinimalReformat != nil {
+		var err error
+		if out, err = r.minimalReformat(out); err != nil {
+			return err
+		}
+	}
+	if r.FinalNewline != FinalNewlineSingle {
+		out = r.applyFinalNewline(out, file)
+	}
+	_, err := w.Write(out)
+	return err
+}
+
+// format prints a restored *ast.File. go/printer is used directly with the same configuration as
+// format.Node (including sorting the imports), except that number literals are printed exactly as
+// they are in the BasicLit values (format.Node normalizes them, e.g. 0Xff becomes 0xff). If
+// BaseIndent or SpacesOnly is set, the Indent option is set or tabs are replaced with spaces. nonce
+// is used for the placeholders added when PreserveCommentWhitespace is set (see newNonce).
+func (pr *Restorer) format(w io.Writer, af *ast.File, nonce string) error {
+	if pr.SpacesOnly > 0 {
+		buf := &bytes.Buffer{}
+		if err := pr.formatText(buf, af, nonce); err != nil {
+			return err
+		}
+		_, err := w.Write(replaceTabs(buf.Bytes(), pr.SpacesOnly))
+		return err
+	}
+	return pr.formatText(w, af, nonce)
+}
+
+func (pr *Restorer) formatText(w io.Writer, af *ast.File, nonce string) error {
+	if pr.PreserveCommentWhitespace {
+		return pr.formatPreservingComments(w, af, nonce)
+	}
+	return pr.formatNode(w, af)
+}
+
+func (pr *Restorer) formatNode(w io.Writer, af *ast.File) error {
+	ast.SortImports(pr.Fset, af)
+	config := printer.Config{Mode: printer.UseSpaces | printer.TabIndent, Tabwidth: 8, Indent: pr.BaseIndent}
+	if pr.SpacesOnly > 0 {
+		config.Mode, config.Tabwidth = printer.UseSpaces, pr.SpacesOnly
+	}
+	return config.Fprint(w, pr.Fset, af)
+}
+
+// formatPreservingComments prints a restored *ast.File, with the text of multi-line block comments
+// printed exactly as it is in the decorations. The printer removes trailing white space from each
+// line of a block comment and adjusts the indentation of the lines, so each comment is printed as
+// a placeholder containing nonce, which is replaced with the original text after printing.
+func (pr *Restorer) formatPreservingComments(w io.Writer, af *ast.File, nonce string) error {
+	var comments []*ast.Comment
+	var texts []string
+	for _, cg := range af.Comments {
+		for _, c := range cg.List {
+			if strings.HasPrefix(c.Text, "/*") && strings.Contains(c.Text, "\n") {
+				comments = append(comments, c)
+				texts = append(texts, c.Text)
+			}
+		}
+	}
+	if len(comments) == 0 {
+		return pr.formatNode(w, af)
+	}
+
+	for i, c := range comments {
+		// the placeholder has the same number of lines as the comment, so the layout is unchanged
+		c.Text = fmt.Sprintf("/*dst:preserve:%s:%d%s*/", nonce, i, strings.Repeat("\n", strings.Count(texts[i], "\n")))
+	}
+	buf := &bytes.Buffer{}
+	err := pr.formatNode(buf, af)
+	for i, c := range comments {
+		c.Text = texts[i]
+	}
 	if err != nil {
 		return err
 	}
-	return format.Node(w, pr.Fset, af)
+	// placeholders matches the placeholders printed for the comments
+	placeholders := regexp.MustCompile(`/\*dst:preserve:` + regexp.QuoteMeta(nonce) + `:(\d+)\s*\*/`)
+	out := placeholders.ReplaceAllFunc(buf.Bytes(), func(b []byte) []byte {
+		i, _ := strconv.Atoi(string(placeholders.FindSubmatch(b)[1]))
+		return []byte(texts[i])
+	})
+	_, err = w.Write(out)
+	return err
 }
 
 // RestoreFile restores a *dst.File to an *ast.File
@@ -65,6 +275,19 @@ func (pr *Restorer) RestoreFile(file *dst.File) (*ast.File, error) {
 	return pr.FileRestorer().RestoreFile(file)
 }
 
+// RestoreNamedFile restores a *dst.File to an *ast.File, and returns the *token.File registered
+// in Fset with the provided name. Each restored file occupies a distinct range of positions in
+// Fset, so several files can be restored into a shared FileSet.
+func (pr *Restorer) RestoreNamedFile(name string, file *dst.File) (*ast.File, *token.File, error) {
+	r := pr.FileRestorer()
+	r.Name = name
+	f, err := r.RestoreFile(file)
+	if err != nil {
+		return nil, nil, err
+	}
+	return f, r.tokenFile, nil
+}
+
 // FileRestorer restores a specific file with extra options
 func (pr *Restorer) FileRestorer() *FileRestorer {
 	return &FileRestorer{
@@ -76,17 +299,26 @@ func (pr *Restorer) FileRestorer() *FileRestorer {
 // FileRestorer restores a specific file with extra options
 type FileRestorer struct {
 	*Restorer
-	Alias           map[string]string // Map of package path -> package alias for imports
-	Name            string            // The name of the restored file in the FileSet. Can usually be left empty.
-	file            *dst.File
-	lines           []int
-	comments        []*ast.CommentGroup
-	base            int
-	cursor          token.Pos
-	nodeDecl        map[*ast.Object]dst.Node // Objects that have a ast.Node Decl (look up after file has been rendered)
-	nodeData        map[*ast.Object]dst.Node // Objects that have a ast.Node Data (look up after file has been rendered)
-	cursorAtNewLine token.Pos                // The cursor position directly after adding a newline decoration (or a line comment which ends in a "\n"). If we're still at this cursor position when we add a line space, reduce the "\n" by one.
-	packageNames    map[string]string        // names in the code of all imported packages ("." for dot-imports)
+	Alias            map[string]string // Map of package path -> package alias for imports
+	Name             string            // The name of the restored file in the FileSet. Can usually be left empty.
+	file             *dst.File
+	lines            []int
+	comments         []*ast.CommentGroup
+	base             int
+	cursor           token.Pos
+	nodeDecl         map[*ast.Object]dst.Node // Objects that have a ast.Node Decl (look up after file has been rendered)
+	nodeData         map[*ast.Object]dst.Node // Objects that have a ast.Node Data (look up after file has been rendered)
+	cursorAtNewLine  token.Pos                // The cursor position directly after adding a newline decoration (or a line comment which ends in a "\n"). If we're still at this cursor position when we add a line space, reduce the "\n" by one.
+	packageNames     map[string]string        // names in the code of all imported packages ("." for dot-imports)
+	tokenFile        *token.File              // The file registered in the FileSet by the most recent RestoreFile
+	identKinds       map[*dst.Ident]IdentKind // kinds of the idents in the file, if IdentFormatter is set
+	printing         bool                     // true if the file is being restored by Fprint, so blank line markers can be added
+	blankLines       int                      // number of consecutive empty lines directly before blankLinesCursor
+	blankLinesCursor token.Pos                // the cursor position directly after the last empty line
+	nonce            string                   // text for the markers added while printing, chosen so they can't match the code
+	badSources       map[string]string        // printed marker -> source of the bad nodes restored while printing
+
+	docComments map[dst.Node]dst.Decorations // reformatted Start decorations, if FormatDocComments is set
 }
 
 // Print uses format.Node to print a *dst.File to stdout
@@ -96,11 +328,74 @@ func (r *FileRestorer) Print(f *dst.File) error {
 
 // Fprint uses format.Node to print a *dst.File to a writer
 func (r *FileRestorer) Fprint(w io.Writer, f *dst.File) error {
-	af, err := r.RestoreFile(f)
+	restore := f
+	if r.WrapLongLines > 0 {
+		var err error
+		if restore, err = r.wrapLongLines(f); err != nil {
+			return err
+		}
+	}
+	r.printing = true
+	r.nonce = newNonce(restore)
+	af, err := r.RestoreFile(restore)
+	r.printing = false
 	if err != nil {
 		return err
 	}
-	return format.Node(w, r.Fset, af)
+	return r.formatFile(w, af, f)
+}
+
+// FprintDecl prints decl, which must be a top-level declaration of f, as a standalone file to a
+// writer. The output contains the package clause of f, the imports that decl requires given the
+// import specs of f, the Alias map and the Resolver, and decl with its decorations. Anonymous
+// imports are not included, and the cgo "C" import is only included if decl refers to it. f is not
+// modified.
+func (r *FileRestorer) FprintDecl(w io.Writer, f *dst.File, decl dst.Decl) error {
+	if r.Resolver == nil {
+		return errors.New("FprintDecl requires a Resolver")
+	}
+	var found bool
+	for _, d := range f.Decls {
+		if d == decl {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return errors.New("decl is not a declaration of the file")
+	}
+	var cgo bool
+	dst.Inspect(decl, func(n dst.Node) bool {
+		if id, ok := n.(*dst.Ident); ok && (id.Path == "C" || id.Name == "C" && id.Path == "" && id.Obj == nil) {
+			cgo = true
+		}
+		return !cgo
+	})
+	out := &dst.File{Name: dst.NewIdent(f.Name.Name)}
+	for _, d := range f.Decls {
+		gd, ok := d.(*dst.GenDecl)
+		if !ok || gd.Tok != token.IMPORT || gd == decl {
+			continue
+		}
+		// anonymous imports and an unused "C" import are not required by decl, but wouldn't be
+		// removed when the imports are updated
+		gd = dst.Clone(gd).(*dst.GenDecl)
+		var specs []dst.Spec
+		for _, spec := range gd.Specs {
+			spec := spec.(*dst.ImportSpec)
+			if mustUnquote(spec.Path.Value) == "C" && !cgo || spec.Name != nil && spec.Name.Name == "_" {
+				continue
+			}
+			specs = append(specs, spec)
+		}
+		if len(specs) == 0 {
+			continue
+		}
+		gd.Specs = specs
+		out.Decls = append(out.Decls, gd)
+	}
+	out.Decls = append(out.Decls, dst.Clone(decl).(dst.Decl))
+	return r.Fprint(w, out)
 }
 
 // RestoreFile restores a *dst.File to *ast.File
@@ -127,6 +422,8 @@ func (r *FileRestorer) RestoreFile(file *dst.File) (*ast.File, error) {
 	r.packageNames = map[string]string{}
 	r.comments = []*ast.CommentGroup{}
 	r.cursorAtNewLine = 0
+	r.blankLines, r.blankLinesCursor = 0, 0
+	r.badSources = nil
 	r.packageNames = map[string]string{}
 
 	r.base = r.Fset.Base() // base is the pos that the file will start at in the fset
@@ -136,9 +433,30 @@ func (r *FileRestorer) RestoreFile(file *dst.File) (*ast.File, error) {
 		return nil, err
 	}
 
+	r.docComments = nil
+	if r.FormatDocComments {
+		r.docComments = formatDocComments(r.file)
+	}
+
+	r.identKinds = nil
+	if r.IdentFormatter != nil {
+		r.identKinds = findIdentKinds(r.file)
+	}
+
+	if r.OmitFileDecorations {
+		start, end := r.file.Decs.Start, r.file.Decs.End
+		r.file.Decs.Start, r.file.Decs.End = nil, nil
+		defer func() { r.file.Decs.Start, r.file.Decs.End = start, end }()
+	}
+
 	// restore the file, populate comments and lines
 	f := r.restoreNode(r.file, "", "", "", false).(*ast.File)
 
+	r.restorePackageDoc(f)
+
+	applyParenMode(f, r.ParenMode)
+	r.applyEmptyInterfaceStyle(f)
+
 	for _, cg := range r.comments {
 		f.Comments = append(f.Comments, cg)
 	}
@@ -147,6 +465,7 @@ func (r *FileRestorer) RestoreFile(file *dst.File) (*ast.File, error) {
 	if !ff.SetLines(r.lines) {
 		panic("ff.SetLines failed")
 	}
+	r.tokenFile = ff
 
 	if r.Extras {
 		// Sometimes new nodes are created here (e.g. in RangeStmt the "Object" is an AssignStmt
@@ -163,12 +482,288 @@ func (r *FileRestorer) RestoreFile(file *dst.File) (*ast.File, error) {
 	return f, nil
 }
 
+// restorePackageDoc merges the comments of the package doc (see dstutil.PackageDoc) into a single
+// comment group and sets it as the Doc field of the file. The File Start decorations are rendered
+// first, so their comments are always at the start of the comments list.
+func (r *FileRestorer) restorePackageDoc(f *ast.File) {
+	count := func(decs dst.Decorations) int {
+		var n int
+		for _, d := range decs {
+			if strings.HasPrefix(d, "//") || strings.HasPrefix(d, "/*") {
+				n++
+			}
+		}
+		return n
+	}
+	file := *r.file
+	file.Decs.Start = r.startDecorations(r.file, r.file.Decs.Start)
+	total := count(file.Decs.Start)
+	doc := count(dstutil.PackageDoc(&file))
+	if doc == 0 || total > len(r.comments) {
+		return
+	}
+	cg := &ast.CommentGroup{}
+	for _, c := range r.comments[total-doc : total] {
+		cg.List = append(cg.List, c.List...)
+	}
+	comments := append([]*ast.CommentGroup{}, r.comments[:total-doc]...)
+	comments = append(comments, cg)
+	r.comments = append(comments, r.comments[total:]...)
+	f.Doc = cg
+}
+
+// ImportRequirement is an import that a file requires, as computed by RequiredImports.
+type ImportRequirement struct {
+	Path  string // Path is the package path.
+	Name  string // Name is the name used in the code, or empty for dot-imports and anonymous imports.
+	Alias string // Alias is the alias in the import spec ("_" or "." included), or empty if none.
+}
+
+// RequiredImports returns the imports that the restored file will contain, given the remote
+// identifiers in the file, the existing import specs and the Resolver. The file is not modified.
+func (pr *Restorer) RequiredImports(file *dst.File) ([]ImportRequirement, error) {
+	return pr.FileRestorer().RequiredImports(file)
+}
+
+// RequiredImports returns the imports that the restored file will contain, given the remote
+// identifiers in the file, the existing import specs, the Alias map and the Resolver. The file
+// is not modified.
+func (r *FileRestorer) RequiredImports(file *dst.File) ([]ImportRequirement, error) {
+	if r.Resolver == nil {
+		return nil, errors.New("RequiredImports requires a Resolver")
+	}
+	plan, err := r.planImports(file)
+	if err != nil {
+		return nil, err
+	}
+	required := make([]ImportRequirement, 0, len(plan.required))
+	for _, path := range plan.required {
+		required = append(required, ImportRequirement{Path: path, Name: plan.names[path], Alias: plan.aliases[path]})
+	}
+	return required, nil
+}
+
+// DryRunImports returns the changes that restoring the file will make to the import specs, given
+// the remote identifiers in the file, the existing import specs and the Resolver (see
+// RequiredImports). The file is not modified.
+func (pr *Restorer) DryRunImports(file *dst.File) (added, removed []ImportRequirement, err error) {
+	return pr.FileRestorer().DryRunImports(file)
+}
+
+// DryRunImports returns the changes that restoring the file will make to the import specs: added
+// lists the imports that will be added, and removed lists the import specs that will be removed, in
+// the order they are in the file. The Name of a removed import is empty. An import spec with an
+// alias that will change is in both lists. The file is not modified.
+func (r *FileRestorer) DryRunImports(file *dst.File) (added, removed []ImportRequirement, err error) {
+	if r.Resolver == nil {
+		return nil, nil, errors.New("DryRunImports requires a Resolver")
+	}
+	plan, err := r.planImports(file)
+	if err != nil {
+		return nil, nil, err
+	}
+	required := map[string]bool{}
+	for _, path := range plan.required {
+		required[path] = true
+		if alias, ok := plan.found[path]; !ok || alias != plan.aliases[path] {
+			added = append(added, ImportRequirement{Path: path, Name: plan.names[path], Alias: plan.aliases[path]})
+		}
+	}
+	for _, block := range plan.blocks {
+		for _, spec := range block.Specs {
+			spec := spec.(*dst.ImportSpec)
+			path := mustUnquote(spec.Path.Value)
+			var alias string
+			if spec.Name != nil {
+				alias = spec.Name.Name
+			}
+			if !required[path] || alias != plan.aliases[path] {
+				removed = append(removed, ImportRequirement{Path: path, Alias: alias})
+			}
+		}
+	}
+	return added, removed, nil
+}
+
+// importPlan is the result of the analysis stage of updateImports.
+type importPlan struct {
+	blocks      []*dst.GenDecl    // the import block(s), excluding a cgo "C" block
+	hasCgoBlock bool              // the "C" import is on it's own in a block
+	found       map[string]string // path -> alias of all packages currently in the imports block(s)
+	required    []string          // ordered paths of all imports required after the update
+	aliases     map[string]string // path -> alias in the imports block (alias, empty string, "_" or ".")
+	names       map[string]string // path -> name in the code (name or empty string for dot-imports)
+}
+
 func (r *FileRestorer) updateImports() error {
 
 	if r.Resolver == nil {
 		return nil
 	}
 
+	plan, err := r.planImports(r.file)
+	if err != nil {
+		return err
+	}
+
+	blocks, hasCgoBlock := plan.blocks, plan.hasCgoBlock
+	importsFound, importsRequiredOrdered, aliases := plan.found, plan.required, plan.aliases
+
+	importsRequired := map[string]bool{}
+	for _, path := range importsRequiredOrdered {
+		importsRequired[path] = true
+	}
+
+	// name in the code (name or empty string for dot imports). This is consumed later by the
+	// restoreIdent method, so is a field on FileRestorer.
+	r.packageNames = plan.names
+
+	// make any additions
+	var added bool
+	for _, path := range importsRequiredOrdered {
+
+		if _, ok := importsFound[path]; ok {
+			continue
+		}
+
+		added = true
+
+		// if there's currently no import blocks, we must create one
+		if len(blocks) == 0 {
+			gd := &dst.GenDecl{
+				Tok: token.IMPORT,
+				// make sure it has an empty line before and after
+				Decs: dst.GenDeclDecorations{
+					NodeDecs: dst.NodeDecs{Before: dst.EmptyLine, After: dst.EmptyLine},
+				},
+			}
+			if hasCgoBlock {
+				// special case for if we have the "C" import
+				r.file.Decls = append([]dst.Decl{r.file.Decls[0], gd}, r.file.Decls[1:]...)
+			} else {
+				r.file.Decls = append([]dst.Decl{gd}, r.file.Decls...)
+			}
+			blocks = append(blocks, gd)
+		}
+
+		is := &dst.ImportSpec{
+			Path: &dst.BasicLit{Kind: token.STRING, Value: fmt.Sprintf("%q", path)},
+		}
+		if aliases[path] != "" {
+			is.Name = &dst.Ident{
+				Name: aliases[path],
+			}
+		}
+		if c := r.ImportComments[path]; c != "" {
+			if !strings.HasPrefix(c, "//") && !strings.HasPrefix(c, "/*") {
+				c = "// " + c
+			}
+			is.Decs.End.Append(c)
+		}
+		blocks[0].Specs = append(blocks[0].Specs, is)
+	}
+
+	if added {
+		// rearrange import block
+		sort.Slice(blocks[0].Specs, func(i, j int) bool {
+			return importPathLess(
+				mustUnquote(blocks[0].Specs[i].(*dst.ImportSpec).Path.Value),
+				mustUnquote(blocks[0].Specs[j].(*dst.ImportSpec).Path.Value),
+				r.LocalPrefix,
+			)
+		})
+	}
+
+	// import blocks that are empty will be removed from the File Decls list later
+	deleteBlocks := map[dst.Decl]bool{}
+
+	// update / delete any import specs from all blocks
+	for _, block := range blocks {
+		specs := make([]dst.Spec, 0, len(block.Specs))
+		for _, spec := range block.Specs {
+			spec := spec.(*dst.ImportSpec)
+			path := mustUnquote(spec.Path.Value)
+			if importsRequired[path] {
+				if spec.Name == nil && aliases[path] != "" {
+					// missing alias
+					spec.Name = &dst.Ident{Name: aliases[path]}
+				} else if spec.Name != nil && aliases[path] == "" {
+					// alias needs to be removed
+					spec.Name = nil
+				} else if spec.Name != nil && aliases[path] != spec.Name.Name {
+					// alias wrong
+					spec.Name.Name = aliases[path]
+				}
+				specs = append(specs, spec)
+			}
+		}
+
+		count := len(specs)
+
+		if count != len(block.Specs) {
+
+			block.Specs = specs
+
+			if count == 0 {
+				deleteBlocks[block] = true
+			} else if count == 1 {
+				block.Lparen = false
+				block.Rparen = false
+			} else {
+				block.Lparen = true
+				block.Rparen = true
+			}
+		}
+	}
+
+	if added {
+		// imports with a period in the path are assumed to not be standard library packages, so
+		// get a newline separating them from standard library packages. Imports matching
+		// LocalPrefix get another newline separating them from the other packages. We remove any
+		// other newlines found in this block. We do this after the deletions because the first
+		// non-stdlib import might be deleted.
+		var group int
+		for _, spec := range blocks[0].Specs {
+			path := mustUnquote(spec.(*dst.ImportSpec).Path.Value)
+			if g := importGroup(path, r.LocalPrefix); g != group {
+				// first import in a group -> empty line above
+				spec.Decorations().Before = dst.EmptyLine
+				spec.Decorations().After = dst.NewLine
+				group = g
+				continue
+			}
+			// all other specs, just newlines
+			spec.Decorations().Before = dst.NewLine
+			spec.Decorations().After = dst.NewLine
+		}
+
+		if len(blocks[0].Specs) == 1 {
+			blocks[0].Lparen = false
+			blocks[0].Rparen = false
+		} else {
+			blocks[0].Lparen = true
+			blocks[0].Rparen = true
+		}
+	}
+
+	// finally remove any deleted blocks from the File Decls list
+	if len(deleteBlocks) > 0 {
+		decls := make([]dst.Decl, 0, len(r.file.Decls))
+		for _, decl := range r.file.Decls {
+			if deleteBlocks[decl] {
+				continue
+			}
+			decls = append(decls, decl)
+		}
+		r.file.Decls = decls
+	}
+
+	return nil
+}
+
+// planImports computes the imports required by file, without modifying it.
+func (r *FileRestorer) planImports(file *dst.File) (*importPlan, error) {
+
 	// list of the import block(s)
 	var blocks []*dst.GenDecl
 
@@ -187,7 +782,7 @@ func (r *FileRestorer) updateImports() error {
 	// a list of all the imports that will be in the imports block after the update
 	importsRequired := map[string]bool{}
 
-	dst.Inspect(r.file, func(n dst.Node) bool {
+	dst.Inspect(file, func(n dst.Node) bool {
 		switch n := n.(type) {
 		case *dst.Ident:
 			if n.Path == "" {
@@ -260,16 +855,36 @@ func (r *FileRestorer) updateImports() error {
 		}
 	}
 
+	var unresolved []string
 	for path := range packagesInUse {
 		if _, ok := effectiveAlias[path]; ok {
 			// no need to resolve the path of a package that has an alias
 			continue
 		}
-		name, err := r.Resolver.ResolvePackage(path)
+		unresolved = append(unresolved, path)
+	}
+	sort.Strings(unresolved)
+
+	if bulk, ok := r.Resolver.(resolver.BulkPackageResolver); ok && len(unresolved) > 1 {
+		names, err := bulk.ResolvePackages(unresolved)
 		if err != nil {
-			return err
+			return nil, err
+		}
+		for _, path := range unresolved {
+			name, ok := names[path]
+			if !ok {
+				return nil, fmt.Errorf("%w: %s", resolver.ErrPackageNotFound, path)
+			}
+			resolved[path] = name
+		}
+	} else {
+		for _, path := range unresolved {
+			name, err := r.Resolver.ResolvePackage(path)
+			if err != nil {
+				return nil, err
+			}
+			resolved[path] = name
 		}
-		resolved[path] = name
 	}
 
 	// We sort the required imports so that the order going into the alias conflict detection
@@ -286,13 +901,12 @@ func (r *FileRestorer) updateImports() error {
 	// alias in the imports block (alias, empty string, "_" or "."
 	aliases := map[string]string{}
 
-	// name in the code (name or empty string for dot imports). This is consumed later by the
-	// restoreIdent method, so is a field on FileRestorer.
-	r.packageNames = map[string]string{}
+	// name in the code (name or empty string for dot imports)
+	names := map[string]string{}
 
 	// conflict returns true if the provided name already exists in the packageNames list
 	conflict := func(name string) bool {
-		for _, n := range r.packageNames {
+		for _, n := range names {
 			if name == n {
 				return true
 			}
@@ -338,147 +952,22 @@ func (r *FileRestorer) updateImports() error {
 
 		if alias == "." || alias == "_" {
 			// no conflict checking for dot-imports or anonymous imports
-			r.packageNames[path], aliases[path] = "", alias
+			names[path], aliases[path] = "", alias
 			continue
 		}
 
 		// regular imports have a unique name chosen.
-		r.packageNames[path], aliases[path] = findAlias(path, alias)
-	}
-
-	// make any additions
-	var added bool
-	for _, path := range importsRequiredOrdered {
-
-		if _, ok := importsFound[path]; ok {
-			continue
-		}
-
-		added = true
-
-		// if there's currently no import blocks, we must create one
-		if len(blocks) == 0 {
-			gd := &dst.GenDecl{
-				Tok: token.IMPORT,
-				// make sure it has an empty line before and after
-				Decs: dst.GenDeclDecorations{
-					NodeDecs: dst.NodeDecs{Before: dst.EmptyLine, After: dst.EmptyLine},
-				},
-			}
-			if hasCgoBlock {
-				// special case for if we have the "C" import
-				r.file.Decls = append([]dst.Decl{r.file.Decls[0], gd}, r.file.Decls[1:]...)
-			} else {
-				r.file.Decls = append([]dst.Decl{gd}, r.file.Decls...)
-			}
-			blocks = append(blocks, gd)
-		}
-
-		is := &dst.ImportSpec{
-			Path: &dst.BasicLit{Kind: token.STRING, Value: fmt.Sprintf("%q", path)},
-		}
-		if aliases[path] != "" {
-			is.Name = &dst.Ident{
-				Name: aliases[path],
-			}
-		}
-		blocks[0].Specs = append(blocks[0].Specs, is)
-	}
-
-	if added {
-		// rearrange import block
-		sort.Slice(blocks[0].Specs, func(i, j int) bool {
-			return packagePathOrderLess(
-				mustUnquote(blocks[0].Specs[i].(*dst.ImportSpec).Path.Value),
-				mustUnquote(blocks[0].Specs[j].(*dst.ImportSpec).Path.Value),
-			)
-		})
-	}
-
-	// import blocks that are empty will be removed from the File Decls list later
-	deleteBlocks := map[dst.Decl]bool{}
-
-	// update / delete any import specs from all blocks
-	for _, block := range blocks {
-		specs := make([]dst.Spec, 0, len(block.Specs))
-		for _, spec := range block.Specs {
-			spec := spec.(*dst.ImportSpec)
-			path := mustUnquote(spec.Path.Value)
-			if importsRequired[path] {
-				if spec.Name == nil && aliases[path] != "" {
-					// missing alias
-					spec.Name = &dst.Ident{Name: aliases[path]}
-				} else if spec.Name != nil && aliases[path] == "" {
-					// alias needs to be removed
-					spec.Name = nil
-				} else if spec.Name != nil && aliases[path] != spec.Name.Name {
-					// alias wrong
-					spec.Name.Name = aliases[path]
-				}
-				specs = append(specs, spec)
-			}
-		}
-
-		count := len(specs)
-
-		if count != len(block.Specs) {
-
-			block.Specs = specs
-
-			if count == 0 {
-				deleteBlocks[block] = true
-			} else if count == 1 {
-				block.Lparen = false
-				block.Rparen = false
-			} else {
-				block.Lparen = true
-				block.Rparen = true
-			}
-		}
+		names[path], aliases[path] = findAlias(path, alias)
 	}
 
-	if added {
-		// imports with a period in the path are assumed to not be standard library packages, so
-		// get a newline separating them from standard library packages. We remove any other
-		// newlines found in this block. We do this after the deletions because the first non-stdlib
-		// import might be deleted.
-		var foundDomainImport bool
-		for _, spec := range blocks[0].Specs {
-			path := mustUnquote(spec.(*dst.ImportSpec).Path.Value)
-			if strings.Contains(path, ".") && !foundDomainImport {
-				// first non-std-lib import -> empty line above
-				spec.Decorations().Before = dst.EmptyLine
-				spec.Decorations().After = dst.NewLine
-				foundDomainImport = true
-				continue
-			}
-			// all other specs, just newlines
-			spec.Decorations().Before = dst.NewLine
-			spec.Decorations().After = dst.NewLine
-		}
-
-		if len(blocks[0].Specs) == 1 {
-			blocks[0].Lparen = false
-			blocks[0].Rparen = false
-		} else {
-			blocks[0].Lparen = true
-			blocks[0].Rparen = true
-		}
-	}
-
-	// finally remove any deleted blocks from the File Decls list
-	if len(deleteBlocks) > 0 {
-		decls := make([]dst.Decl, 0, len(r.file.Decls))
-		for _, decl := range r.file.Decls {
-			if deleteBlocks[decl] {
-				continue
-			}
-			decls = append(decls, decl)
-		}
-		r.file.Decls = decls
-	}
-
-	return nil
+	return &importPlan{
+		blocks:      blocks,
+		hasCgoBlock: hasCgoBlock,
+		found:       importsFound,
+		required:    importsRequiredOrdered,
+		aliases:     aliases,
+		names:       names,
+	}, nil
 }
 
 // restoreIdent is a special case for restoring an ident. If the ident has a path and the imported
@@ -522,8 +1011,13 @@ func (r *FileRestorer) restoreIdent(n *dst.Ident, parentName, parentField, paren
 	// Decoration: Start
 	r.applyDecorations(out, n.Decs.Start, false)
 
+	x, sel := dst.NewIdent(name), dst.NewIdent(n.Name)
+	if r.identKinds != nil {
+		r.identKinds[x], r.identKinds[sel] = identRemote, identRemote
+	}
+
 	// Node: X
-	out.X = r.restoreNode(dst.NewIdent(name), "SelectorExpr", "X", "Expr", allowDuplicate).(ast.Expr)
+	out.X = r.restoreNode(x, "SelectorExpr", "X", "Expr", allowDuplicate).(ast.Expr)
 
 	// Token: Period
 	r.cursor += token.Pos(len(token.PERIOD.String()))
@@ -532,7 +1026,7 @@ func (r *FileRestorer) restoreIdent(n *dst.Ident, parentName, parentField, paren
 	r.applyDecorations(out, n.Decs.X, false)
 
 	// Node: Sel
-	out.Sel = r.restoreNode(dst.NewIdent(n.Name), "SelectorExpr", "Sel", "Ident", allowDuplicate).(*ast.Ident)
+	out.Sel = r.restoreNode(sel, "SelectorExpr", "Sel", "Ident", allowDuplicate).(*ast.Ident)
 
 	// Decoration: End
 	r.applyDecorations(out, n.Decs.End, true)
@@ -543,15 +1037,35 @@ func (r *FileRestorer) restoreIdent(n *dst.Ident, parentName, parentField, paren
 }
 
 func packagePathOrderLess(pi, pj string) bool {
-	// package paths with a . should be ordered after those without
-	idot := strings.Contains(pi, ".")
-	jdot := strings.Contains(pj, ".")
-	if idot != jdot {
-		return jdot
+	return importPathLess(pi, pj, "")
+}
+
+// importPathLess orders package paths by import group (see importGroup), then by path.
+func importPathLess(pi, pj, localPrefix string) bool {
+	gi, gj := importGroup(pi, localPrefix), importGroup(pj, localPrefix)
+	if gi != gj {
+		return gi < gj
 	}
 	return pi < pj
 }
 
+// importGroup returns 0 for standard library packages (package paths without a .), 2 for packages
+// matching localPrefix (a comma separated list of path prefixes, as in the goimports -local flag)
+// and 1 for all other packages.
+func importGroup(path, localPrefix string) int {
+	if localPrefix != "" {
+		for _, prefix := range strings.Split(localPrefix, ",") {
+			if prefix != "" && (strings.HasPrefix(path, prefix) || strings.TrimSuffix(prefix, "/") == path) {
+				return 2
+			}
+		}
+	}
+	if strings.Contains(path, ".") {
+		return 1
+	}
+	return 0
+}
+
 func (r *FileRestorer) fileSize() int {
 
 	// If a comment is at the end of a file, it will extend past the current cursor position...
@@ -626,9 +1140,38 @@ func (r *FileRestorer) addCommentField(n ast.Node, slash token.Pos, text string)
 	}
 }
 
+// startDecorations returns the decorations that are restored for decorations of n: the reformatted
+// doc comment if decorations are the Start decorations of n and FormatDocComments is set.
+func (r *FileRestorer) startDecorations(n dst.Node, decorations dst.Decorations) dst.Decorations {
+	formatted, ok := r.docComments[n]
+	if !ok {
+		return decorations
+	}
+	start := n.Decorations().Start
+	if len(decorations) == 0 || len(decorations) != len(start) || &decorations[0] != &start[0] {
+		return decorations
+	}
+	return formatted
+}
+
 func (r *FileRestorer) applyDecorations(node ast.Node, decorations dst.Decorations, end bool) {
+	if n, ok := r.Dst.Nodes[node]; ok && !end && r.docComments != nil {
+		decorations = r.startDecorations(n, decorations)
+	}
 	firstLine := true
-	for _, d := range decorations {
+	var skip bool
+	for i := range decorations {
+
+		if skip {
+			skip = false
+			continue
+		}
+		var d string
+		var lineBreak bool
+		d, lineBreak, skip = convertComment(r.CommentStyle, decorations, i)
+		if r.NormalizeCommentSpacing {
+			d = normalizeCommentSpacing(d)
+		}
 
 		isNewline := d == "\n"
 		isLineComment := strings.HasPrefix(d, "//")
@@ -662,16 +1205,29 @@ func (r *FileRestorer) applyDecorations(node ast.Node, decorations dst.Decoratio
 			r.cursor += token.Pos(len(d))
 		}
 
+		// when printing several empty lines, a newline decoration directly after an empty line
+		// is printed as a marker comment
+		if isNewline && r.printing && r.cursor == r.blankLinesCursor && r.blankLines < r.MaxBlankLines {
+			r.addBlankLineMarkers(1)
+			firstLine = false
+			continue
+		}
+
 		// for newline decorations and also line-comments, add a newline
-		if isLineComment || isNewline {
+		if isLineComment || isNewline || lineBreak {
+			empty := isNewline && r.cursor == r.cursorAtNewLine
+
 			lineOffset := int(r.cursor) - r.base // remember lines are relative to the file base
 			r.lines = append(r.lines, lineOffset)
 			r.cursor++
 
 			r.cursorAtNewLine = r.cursor
+			if empty {
+				r.blankLines, r.blankLinesCursor = 1, r.cursor
+			}
 		}
 
-		if isNewline || isLineComment {
+		if isNewline || isLineComment || lineBreak {
 			firstLine = false
 		}
 	}
@@ -686,10 +1242,10 @@ func (r *FileRestorer) applySpace(node dst.Node, position string, space dst.Spac
 		}
 	}
 	var newlines int
-	switch space {
-	case dst.NewLine:
+	switch {
+	case space == dst.NewLine:
 		newlines = 1
-	case dst.EmptyLine:
+	case space >= dst.EmptyLine:
 		newlines = 2
 	}
 	if r.cursor == r.cursorAtNewLine {
@@ -706,6 +1262,49 @@ func (r *FileRestorer) applySpace(node dst.Node, position string, space dst.Spac
 		r.cursor++
 		r.cursorAtNewLine = r.cursor
 	}
+	if space < dst.EmptyLine {
+		return
+	}
+	if r.cursor != r.blankLinesCursor {
+		r.blankLines, r.blankLinesCursor = 1, r.cursor
+	}
+	if r.printing && position == "Before" {
+		blank := int(space) - 1
+		if blank > r.MaxBlankLines {
+			blank = r.MaxBlankLines
+		}
+		r.addBlankLineMarkers(blank - r.blankLines)
+	}
+}
+
+// addBlankLineMarkers adds n empty lines after an empty line. The printer collapses consecutive
+// empty lines, so each one is printed as a marker comment on its own line, which is removed from
+// the output by formatFile.
+func (r *FileRestorer) addBlankLineMarkers(n int) {
+	for i := 0; i < n; i++ {
+		marker := r.blankLineMarker()
+		r.comments = append(r.comments, &ast.CommentGroup{List: []*ast.Comment{{Slash: r.cursor, Text: marker}}})
+		r.cursor += token.Pos(len(marker))
+
+		lineOffset := int(r.cursor) - r.base // remember lines are relative to the file base
+		r.lines = append(r.lines, lineOffset)
+		r.cursor++
+		r.cursorAtNewLine = r.cursor
+		r.blankLines++
+		r.blankLinesCursor = r.cursor
+	}
+}
+
+// blankLineMarker returns the comment printed for each extra empty line when MaxBlankLines is set.
+// The marker contains the nonce of the print, so it doesn't match any text in the file.
+func (r *FileRestorer) blankLineMarker() string {
+	return "/*dst:blank:" + r.nonce + "*/"
+}
+
+// blankLineMarkers matches the lines printed for the blankLineMarker comments, excluding the
+// newline.
+func (r *FileRestorer) blankLineMarkers() *regexp.Regexp {
+	return regexp.MustCompile(`(?m)^[ \t]*` + regexp.QuoteMeta(r.blankLineMarker()) + `[ \t]*$`)
 }
 
 func (r *FileRestorer) restoreObject(o *dst.Object) *ast.Object {
@@ -3,16 +3,24 @@ package decorator
 import (
 	"bytes"
 	"fmt"
+	"go/ast"
 	"go/format"
+	"go/importer"
+	"go/parser"
 	"go/token"
+	"go/types"
 	"os"
 	"path/filepath"
+	"reflect"
 	"sort"
 	"strconv"
+	"strings"
 	"testing"
 
 	"github.com/dave/dst"
+	"github.com/dave/dst/decorator/resolver/goast"
 	"github.com/dave/dst/decorator/resolver/gopackages"
+	"github.com/dave/dst/decorator/resolver/guess"
 	"golang.org/x/tools/go/packages"
 )
 
@@ -1532,3 +1540,427 @@ func TestRestorerDecorationResolver(t *testing.T) {
 		})
 	}
 }
+
+func TestRequiredImports(t *testing.T) {
+	code := `package main
+
+import (
+	"fmt"
+	. "strings"
+	_ "embed"
+	"os"
+)
+
+func main() {}
+`
+	file, err := Parse(code)
+	if err != nil {
+		t.Fatal(err)
+	}
+	call := func(name, path string) dst.Stmt {
+		return &dst.ExprStmt{X: &dst.CallExpr{Fun: &dst.Ident{Name: name, Path: path}}}
+	}
+	body := file.Decls[1].(*dst.FuncDecl).Body
+	body.List = []dst.Stmt{call("Println", "fmt"), call("ToUpper", "strings"), call("B", "github.com/a/b")}
+
+	r := NewRestorerWithImports("main", guess.New())
+	required, err := r.RequiredImports(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := []ImportRequirement{
+		{Path: "embed", Alias: "_"},
+		{Path: "fmt", Name: "fmt"},
+		{Path: "strings", Alias: "."},
+		{Path: "github.com/a/b", Name: "b"},
+	}
+	if !reflect.DeepEqual(expect, required) {
+		t.Fatalf("\nexpect: %#v\nfound : %#v", expect, required)
+	}
+
+	// the file is not modified
+	if n := len(file.Decls[0].(*dst.GenDecl).Specs); n != 4 {
+		t.Fatalf("expected 4 import specs, found %d", n)
+	}
+
+	af, err := r.RestoreFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var restored []ImportRequirement
+	for _, spec := range af.Decls[0].(*ast.GenDecl).Specs {
+		spec := spec.(*ast.ImportSpec)
+		var alias string
+		if spec.Name != nil {
+			alias = spec.Name.Name
+		}
+		restored = append(restored, ImportRequirement{Path: mustUnquote(spec.Path.Value), Alias: alias})
+	}
+	for i := range expect {
+		expect[i].Name = ""
+	}
+	sort.Slice(expect, func(i, j int) bool { return expect[i].Path < expect[j].Path })
+	sort.Slice(restored, func(i, j int) bool { return restored[i].Path < restored[j].Path })
+	if !reflect.DeepEqual(expect, restored) {
+		t.Errorf("\nexpect: %#v\nfound : %#v", expect, restored)
+	}
+
+	if _, err := NewRestorer().RequiredImports(file); err == nil {
+		t.Error("expected error, found none")
+	}
+}
+
+func TestDryRunImports(t *testing.T) {
+	code := `package main
+
+import (
+	"fmt"
+	"os"
+	z "strings"
+)
+
+func main() {
+	fmt.Println(os.Args)
+	z.ToUpper("a")
+}
+`
+	file, err := NewDecoratorWithImports(nil, "main", goast.New()).Parse(code)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// replace os.Args with path.Base("a"), and remove the alias of strings
+	call := file.Decls[1].(*dst.FuncDecl).Body.List[0].(*dst.ExprStmt).X.(*dst.CallExpr)
+	call.Args[0] = &dst.CallExpr{
+		Fun:  &dst.Ident{Name: "Base", Path: "path"},
+		Args: []dst.Expr{&dst.BasicLit{Kind: token.STRING, Value: `"a"`}},
+	}
+	tree := func() string {
+		buf := &bytes.Buffer{}
+		if err := dst.Fprint(buf, file, dst.NotNilFilter); err != nil {
+			t.Fatal(err)
+		}
+		return buf.String()
+	}
+	before := tree()
+
+	r := NewRestorerWithImports("main", guess.New()).FileRestorer()
+	r.Alias["strings"] = ""
+	added, removed, err := r.DryRunImports(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectAdded := []ImportRequirement{
+		{Path: "path", Name: "path"},
+		{Path: "strings", Name: "strings"},
+	}
+	expectRemoved := []ImportRequirement{
+		{Path: "os"},
+		{Path: "strings", Alias: "z"},
+	}
+	if !reflect.DeepEqual(expectAdded, added) {
+		t.Errorf("added:\nexpect: %#v\nfound : %#v", expectAdded, added)
+	}
+	if !reflect.DeepEqual(expectRemoved, removed) {
+		t.Errorf("removed:\nexpect: %#v\nfound : %#v", expectRemoved, removed)
+	}
+
+	// the file is not modified
+	if tree() != before {
+		t.Error("file was modified")
+	}
+
+	buf := &bytes.Buffer{}
+	if err := r.Fprint(buf, file); err != nil {
+		t.Fatal(err)
+	}
+	compare(t, `package main
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+func main() {
+	fmt.Println(path.Base("a"))
+	strings.ToUpper("a")
+}
+`, buf.String())
+
+	if _, _, err := NewRestorer().DryRunImports(file); err == nil {
+		t.Error("expected error, found none")
+	}
+}
+
+func TestRestorerIdentFormatter(t *testing.T) {
+	code := `package a
+
+import "fmt"
+
+type T struct {
+	Id   int
+	Url  string
+	name string
+}
+
+func (t T) String() string {
+	return fmt.Sprint(t.Id, t.Url, t.name)
+}
+`
+	expect := `package a
+
+import "fmt"
+
+type T struct {
+	ID   int
+	URL  string
+	name string
+}
+
+func (t T) String() string {
+	return fmt.Sprint(t.ID, t.URL, t.name)
+}
+`
+	d := NewDecoratorWithImports(token.NewFileSet(), "a", goast.New())
+	file, err := d.Parse(code)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	kinds := map[string]IdentKind{}
+	r := NewRestorerWithImports("a", guess.New())
+	r.IdentFormatter = func(name string, kind IdentKind) string {
+		if _, ok := kinds[name]; !ok {
+			// record the kind of the first occurrence of each name
+			kinds[name] = kind
+		}
+		if (kind == IdentField || kind == IdentSelector) && dst.IsExported(name) {
+			return strings.ToUpper(name)
+		}
+		return name
+	}
+	buf := &bytes.Buffer{}
+	if err := r.Fprint(buf, file); err != nil {
+		t.Fatal(err)
+	}
+	compare(t, expect, buf.String())
+
+	expectKinds := map[string]IdentKind{
+		"a":      IdentPackage,
+		"T":      IdentType,
+		"Id":     IdentField,
+		"Url":    IdentField,
+		"name":   IdentField,
+		"int":    IdentOther,
+		"string": IdentOther,
+		"t":      IdentParam,
+		"String": IdentFunc,
+	}
+	if !reflect.DeepEqual(expectKinds, kinds) {
+		t.Errorf("\nexpect: %v\nfound : %v", expectKinds, kinds)
+	}
+	if _, ok := kinds["Sprint"]; ok {
+		t.Error("remote ident passed to IdentFormatter")
+	}
+
+	// the dst nodes are not modified
+	buf.Reset()
+	if err := NewRestorerWithImports("a", guess.New()).Fprint(buf, file); err != nil {
+		t.Fatal(err)
+	}
+	compare(t, code, buf.String())
+}
+
+type bulkResolver struct {
+	single, bulk int
+	names        map[string]string
+}
+
+func (r *bulkResolver) ResolvePackage(path string) (string, error) {
+	r.single++
+	return r.names[path], nil
+}
+
+func (r *bulkResolver) ResolvePackages(paths []string) (map[string]string, error) {
+	r.bulk++
+	names := map[string]string{}
+	for _, path := range paths {
+		names[path] = r.names[path]
+	}
+	return names, nil
+}
+
+func TestRestorerBulkResolver(t *testing.T) {
+	file, err := Parse("package main\n\nfunc main() {}\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body := file.Decls[0].(*dst.FuncDecl).Body
+	for _, path := range []string{"github.com/a/b-go", "github.com/c/d.v1", "fmt"} {
+		body.List = append(body.List, &dst.ExprStmt{
+			X:    &dst.CallExpr{Fun: &dst.Ident{Name: "F", Path: path}},
+			Decs: dst.ExprStmtDecorations{NodeDecs: dst.NodeDecs{Before: dst.NewLine, After: dst.NewLine}},
+		})
+	}
+	r := &bulkResolver{names: map[string]string{"github.com/a/b-go": "b", "github.com/c/d.v1": "d", "fmt": "fmt"}}
+	buf := &bytes.Buffer{}
+	if err := NewRestorerWithImports("main", r).Fprint(buf, file); err != nil {
+		t.Fatal(err)
+	}
+	if r.bulk != 1 || r.single != 0 {
+		t.Errorf("expected 1 bulk call and no single calls, found %d and %d", r.bulk, r.single)
+	}
+	expect := "package main\n\nimport (\n\t\"fmt\"\n\n\t\"github.com/a/b-go\"\n\t\"github.com/c/d.v1\"\n)\n\nfunc main() {\n\tb.F()\n\td.F()\n\tfmt.F()\n}\n"
+	compare(t, expect, buf.String())
+}
+
+func TestRestorerLocalPrefix(t *testing.T) {
+	file, err := Parse("package main\n\nfunc main() {}\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body := file.Decls[0].(*dst.FuncDecl).Body
+	for _, path := range []string{"example.com/me/project/util", "github.com/a/b", "os", "fmt"} {
+		body.List = append(body.List, &dst.ExprStmt{
+			X:    &dst.CallExpr{Fun: &dst.Ident{Name: "F", Path: path}},
+			Decs: dst.ExprStmtDecorations{NodeDecs: dst.NodeDecs{Before: dst.NewLine, After: dst.NewLine}},
+		})
+	}
+	r := NewRestorerWithImports("main", guess.New())
+	r.LocalPrefix = "example.com/me/project"
+	buf := &bytes.Buffer{}
+	if err := r.Fprint(buf, file); err != nil {
+		t.Fatal(err)
+	}
+	expect := "package main\n\nimport (\n\t\"fmt\"\n\t\"os\"\n\n\t\"github.com/a/b\"\n\n\t\"example.com/me/project/util\"\n)\n\nfunc main() {\n\tutil.F()\n\tb.F()\n\tos.F()\n\tfmt.F()\n}\n"
+	compare(t, expect, buf.String())
+
+	paths := []string{"a.b/d", "local/x", "fmt", "local", "a.b/c"}
+	sort.Slice(paths, func(i, j int) bool {
+		return importPathLess(paths[i], paths[j], "a.b/d,local/")
+	})
+	if found := fmt.Sprint(paths); found != "[fmt a.b/c a.b/d local local/x]" {
+		t.Errorf("unexpected order %s", found)
+	}
+}
+
+func TestFprintDecl(t *testing.T) {
+	code := `package main
+
+import (
+	"fmt"
+	"os"
+	_ "net/http/pprof"
+)
+
+var name = os.Args[0]
+
+// Greet prints a greeting.
+func Greet(s string) {
+	fmt.Println("hello", s) // greet
+}
+
+func main() {
+	Greet(name)
+}
+`
+	expect := `package main
+
+import "fmt"
+
+// Greet prints a greeting.
+func Greet(s string) {
+	fmt.Println("hello", s) // greet
+}
+`
+	file, err := NewDecoratorWithImports(nil, "main", goast.New()).Parse(code)
+	if err != nil {
+		t.Fatal(err)
+	}
+	before := &bytes.Buffer{}
+	if err := dst.Fprint(before, file, dst.NotNilFilter); err != nil {
+		t.Fatal(err)
+	}
+
+	buf := &bytes.Buffer{}
+	if err := NewRestorerWithImports("main", guess.New()).FprintDecl(buf, file, file.Decls[2]); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != expect {
+		t.Errorf("diff:\n%s", diff(expect, buf.String()))
+	}
+
+	// the output is a standalone file that compiles
+	fset := token.NewFileSet()
+	af, err := parser.ParseFile(fset, "main.go", buf.String(), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conf := types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+	if _, err := conf.Check("main", fset, []*ast.File{af}, nil); err != nil {
+		t.Errorf("output doesn't compile: %v", err)
+	}
+
+	// the file is not modified
+	after := &bytes.Buffer{}
+	if err := dst.Fprint(after, file, dst.NotNilFilter); err != nil {
+		t.Fatal(err)
+	}
+	if before.String() != after.String() {
+		t.Error("file was modified")
+	}
+
+	if err := NewRestorer().FprintDecl(buf, file, file.Decls[2]); err == nil {
+		t.Error("expected error without a Resolver")
+	}
+}
+
+func TestRestorerImportComments(t *testing.T) {
+	code := `package main
+
+import "fmt"
+
+func main() {
+	fmt.Println()
+}
+`
+	expect := `package main
+
+import (
+	"fmt"
+	"os"      // forked: github.com/a/os
+	"strings" // for ToUpper
+)
+
+func main() {
+	fmt.Println(os.Args, strings.ToUpper("a"))
+}
+`
+	file, err := NewDecoratorWithImports(nil, "main", goast.New()).Parse(code)
+	if err != nil {
+		t.Fatal(err)
+	}
+	call := file.Decls[1].(*dst.FuncDecl).Body.List[0].(*dst.ExprStmt).X.(*dst.CallExpr)
+	call.Args = []dst.Expr{
+		&dst.Ident{Name: "Args", Path: "os"},
+		&dst.CallExpr{
+			Fun:  &dst.Ident{Name: "ToUpper", Path: "strings"},
+			Args: []dst.Expr{&dst.BasicLit{Kind: token.STRING, Value: `"a"`}},
+		},
+	}
+
+	r := NewRestorerWithImports("main", guess.New())
+	r.ImportComments = map[string]string{
+		"fmt":     "not added, so unchanged",
+		"os":      "forked: github.com/a/os",
+		"strings": "// for ToUpper",
+	}
+	buf := &bytes.Buffer{}
+	if err := r.Fprint(buf, file); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != expect {
+		t.Errorf("diff:\n%s", diff(expect, buf.String()))
+	}
+}
@@ -2,8 +2,15 @@ package decorator
 
 import (
 	"bytes"
+	"go/ast"
 	"go/format"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"strings"
 	"testing"
+
+	"github.com/dave/dst"
 )
 
 func TestRestorer(t *testing.T) {
@@ -630,6 +637,361 @@ const d = 1
            				A()
            		}`,
 		},
+		{
+			name: "range-int",
+			code: `package a
+
+				func main() {
+					for i := range /* a */ /* b */ 10 /* c */ {
+						println(i)
+					}
+					for range 3 {
+					}
+					for range /* d */ n + 1 /* e */ {
+					}
+				}`,
+		},
+		{
+			name: "range-func",
+			code: `package a
+
+				func seq(yield func(int, string) bool) {
+					yield(1, "a")
+				}
+
+				func main() {
+					for k, v := range seq {
+						println(k, v)
+					}
+					for k := range /* a */ func(yield func(int) bool) {} {
+						println(k)
+					}
+					for range seq {
+					}
+				}`,
+		},
+		{
+			name: "value-spec-initializer",
+			code: `package a
+
+var a int
+var b = 1
+var c int = 1
+var d = /* a */ 1
+var e int = /* a */ /* b */ 1
+var (
+	f int
+	g     = 1
+	h int = 1
+)`,
+		},
+		{
+			name: "type-switch-guard",
+			code: `package a
+
+func a(i interface{}) {
+	switch /* a */ x := /* b */ i. /* c */ (type /* d */) /* e */ {
+	/* f */ case /* g */ int /* h */, string: // i
+		print(x)
+	// j
+	case nil:
+	default: /* k */
+	}
+	switch /* l */ v := i; /* m */ v.(type) /* n */ {
+	}
+}`,
+		},
+		{
+			name: "const-iota",
+			code: `package a
+
+const (
+	A Kind = iota // a
+	B
+	_
+	// c
+	C
+	D = 10
+	E
+
+	F Kind = iota * 2
+	G
+)`,
+		},
+		{
+			name: "case-clause-comments",
+			code: `package a
+
+func a(i int, c chan int) {
+	switch i {
+	case 1: // a
+		// b
+		print(i)
+	case 2:
+		// c
+	case 3: /* d */
+	case 4:
+	// e
+	default:
+		/* f */ print(i) // g
+		// h
+	}
+	select {
+	case <-c: // i
+		// j
+		print(i)
+	case c <- i:
+		// k
+	case v := <-c: /* l */
+		print(v)
+	default:
+	// m
+	}
+}`,
+		},
+		{
+			name: "select-clause-order",
+			code: `package a
+
+func a(c, d chan int, e chan<- int) {
+	// a
+	select /* b */ {
+	// c
+	case v := <-d: // d
+		print(v)
+
+	// e
+	case /* f */ e <- 1 /* g */ :
+		// h
+	default: // i
+	case <-c:
+		return // j
+	}
+}`,
+		},
+		{
+			name: "composite-lit-trailing-comments",
+			code: `package a
+
+var c = Config{
+	Foo: 1,     // a
+	Bar: "bar", // b
+	Baz: []int{
+		3, // c
+	}, // d
+	Qux: 4, /* e */
+}`,
+		},
+		{
+			name: "labels-and-gotos",
+			code: `package a
+
+func a(n int) int {
+	// a
+outer: // b
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			if j == 2 {
+				continue outer // c
+			}
+			if i == 3 {
+				break /* d */ outer
+			}
+		}
+	}
+	i := 0
+loop:
+	/* e */
+	i++
+	if i < n {
+		goto loop // f
+	}
+	// g
+done:
+	return i
+}`,
+		},
+		{
+			name: "chan-comments",
+			code: `package a
+
+var a <-chan /* a */ int
+var b chan<- /* b */ int
+var c chan /* c */ <- /* d */ int
+
+func e(ch chan int, v int) {
+	ch /* f */ <- /* g */ v
+	v = <- /* h */ ch
+	v, ok := /* i */ <-ch /* j */
+	println(v, ok)
+	select {
+	case ch <- /* k */ v:
+	case x := <- /* l */ ch:
+		println(x)
+	}
+}`,
+		},
+		{
+			name: "variadic-spread",
+			code: `package a
+
+func a(a, b []int) {
+	a = append(a, b...) // c
+	a = append(a, b /* d */ ... /* e */)
+	a = append(a,
+		b..., // f
+	)
+	print(a)
+}`,
+		},
+		{
+			name: "blank-assign",
+			code: `package a
+
+func a() {
+	_ = b()
+	_, c := d() // e
+	_ /* f */, _ = c, g
+	var _, g = 1, 2
+	var _ int
+	_ = g
+}`,
+		},
+		{
+			name: "blank-range",
+			code: `package a
+
+func a(b []int) {
+	for _ = range b {
+	}
+	for _, c := range b {
+		_ = c
+	}
+	for i, _ := range b /* d */ {
+		_ = i
+	}
+}`,
+		},
+		{
+			name: "blank-result",
+			code: `package a
+
+func a() (_ int) { return }
+
+func b() (_ int, _ /* c */ error) { return }
+
+func d() (_, e int, _ error) { return }
+
+func (_ T) f(_ int, _, g string) {}`,
+		},
+		{
+			name: "blank-import",
+			code: `package a
+
+import (
+	_ "a" // b
+
+	_ /* c */ "d"
+)
+
+import _ "e"`,
+		},
+		{
+			name: "blank-type-switch",
+			code: `package a
+
+func a(b interface{}) {
+	switch _ := b.(type) {
+	case int:
+	}
+	switch _ = b; b.(type) {
+	}
+	switch /* c */ b.(type) {
+	case _:
+	}
+	if _, ok := b.(int); ok {
+	}
+}`,
+		},
+		{
+			name: "blank-decls",
+			code: `package a
+
+const _ = 1
+
+var _ = a
+
+type _ struct{ _ int }
+
+func _() {}
+
+var _ interface{ _() }`,
+		},
+		{
+			name: "interface-type-sets",
+			code: `package a
+
+type A interface {
+	~int | ~string
+}
+
+type B interface {
+	~int /* a */ | /* b */ ~ /* c */ int64 | // d
+		float64 // e
+	String() string
+}
+
+type C interface {
+	// f
+	A
+	comparable
+	int | B // g
+}`,
+		},
+		{
+			name: "positional-and-keyed-literals",
+			code: `package a
+
+var a = T{1 /* b */, 2}
+var c = T{A: 1, B /* d */ : 2}
+var e = T{
+	1, // f
+	2,
+}
+var g = T{
+	A: 1, // h
+	// i
+	B: 2,
+}`,
+		},
+		{
+			name: "package-clause-comment",
+			code: `package main // build: special
+
+func main() {}`,
+		},
+		{
+			name: "array-type-forms",
+			code: `package main
+
+var a = [ /* a */ ...] /* b */ int{1, 2}
+var b [ /* c */ N] /* d */ int
+var c [] /* e */ int
+var d [2 * /* f */ N]int
+var e = [...]string{ /* g */ "a"}
+
+const N = 1`,
+		},
+		{
+			name: "variadic-ellipsis",
+			code: `package main
+
+func a(b /* a */ ... /* b */ int) {}
+
+func c(d int, e /* c */ ... /* d */ []string /* e */) {}
+
+var f func( /* f */ ...interface{})`,
+		},
 	}
 	var solo bool
 	for _, test := range tests {
@@ -680,3 +1042,1507 @@ const d = 1
 		})
 	}
 }
+
+func TestExportDirectives(t *testing.T) {
+	code := `package main
+
+// #include <stdio.h>
+import "C"
+
+//export A
+func A() {
+}
+
+// B does things.
+//export B
+func B() {
+	println()
+	}
+	//export C
+func C() {}
+`
+	expect := `package main
+
+// #include <stdio.h>
+import "C"
+
+//export C
+func C() {}
+
+// B does things.
+//export B
+func B() {
+	println()
+}
+
+//export A
+func A() {
+}
+`
+	file, err := Parse(code)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// reverse the order of the functions
+	decls := file.Decls[1:]
+	for i, j := 0, len(decls)-1; i < j; i, j = i+1, j-1 {
+		decls[i], decls[j] = decls[j], decls[i]
+	}
+
+	buf := &bytes.Buffer{}
+	if err := Fprint(buf, file); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != expect {
+		t.Errorf("diff:\n%s", diff(expect, buf.String()))
+	}
+}
+
+func TestPragmaDirectives(t *testing.T) {
+	code := `package main
+
+//go:noinline
+func A() {
+}
+
+// B does things.
+//go:nosplit
+//go:norace
+func B() {
+	println()
+	}
+	//go:noinline
+func C() {}
+`
+	expect := `package main
+
+//go:noinline
+func C() {}
+
+// B does things.
+//go:nosplit
+//go:norace
+func B() {
+	println()
+}
+
+//go:noinline
+func A() {
+}
+`
+	d := NewDecorator(nil)
+	d.PinFuncDirectives = true
+	file, err := d.Parse(code)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// reverse the order of the functions
+	decls := file.Decls
+	for i, j := 0, len(decls)-1; i < j; i, j = i+1, j-1 {
+		decls[i], decls[j] = decls[j], decls[i]
+	}
+
+	buf := &bytes.Buffer{}
+	if err := Fprint(buf, file); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != expect {
+		t.Errorf("diff:\n%s", diff(expect, buf.String()))
+	}
+
+	// the empty lines around a directive are kept
+	code = "package main\n\nvar a = 1\n\n//go:noinline\n\nfunc A() {}\n"
+	for _, pin := range []bool{false, true} {
+		d := NewDecorator(nil)
+		d.PinFuncDirectives = pin
+		file, err := d.Parse(code)
+		if err != nil {
+			t.Fatal(err)
+		}
+		buf := &bytes.Buffer{}
+		if err := Fprint(buf, file); err != nil {
+			t.Fatal(err)
+		}
+		if buf.String() != code {
+			t.Errorf("diff:\n%s", diff(code, buf.String()))
+		}
+	}
+}
+
+func TestGenerateDirectives(t *testing.T) {
+	code := `package main
+
+var A = 1
+//go:generate stringer -type=B
+
+type B int
+
+//go:generate echo c
+
+func C() {}
+
+//go:generate echo done
+`
+	expect := `package main
+
+//go:generate echo c
+
+func C() {}
+
+//go:generate stringer -type=B
+
+type B int
+
+var A = 1
+
+//go:generate echo done
+`
+	d := NewDecorator(nil)
+	d.PinGenerateDirectives = true
+	file, err := d.Parse(code)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// reverse the order of the declarations
+	decls := file.Decls
+	decls[0], decls[2] = decls[2], decls[0]
+	decls[0].Decorations().Before = dst.NewLine
+	decls[2].Decorations().Before = dst.EmptyLine
+
+	buf := &bytes.Buffer{}
+	if err := Fprint(buf, file); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != expect {
+		t.Errorf("diff:\n%s", diff(expect, buf.String()))
+	}
+}
+
+func TestRestorerBaseIndent(t *testing.T) {
+	code := "package a\n\nfunc a() {\n\tb := `raw\nstring`\n\n\tprintln(b) // b\n}\n"
+	expect := "\t\tpackage a\n\n\t\tfunc a() {\n\t\t\tb := `raw\nstring`\n\n\t\t\tprintln(b) // b\n\t\t}\n"
+	file, err := Parse(code)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := NewRestorer()
+	r.BaseIndent = 2
+	buf := &bytes.Buffer{}
+	if err := r.Fprint(buf, file); err != nil {
+		t.Fatal(err)
+	}
+	compare(t, expect, buf.String())
+}
+
+func TestRestorerValueSpecEmptyValues(t *testing.T) {
+	file, err := Parse("package a\n\nvar a int = 1\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	spec := file.Decls[0].(*dst.GenDecl).Specs[0].(*dst.ValueSpec)
+	for _, values := range [][]dst.Expr{nil, {}} {
+		spec.Values = values
+		buf := &bytes.Buffer{}
+		if err := Fprint(buf, file); err != nil {
+			t.Fatal(err)
+		}
+		compare(t, "package a\n\nvar a int\n", buf.String())
+	}
+}
+
+func TestRestorerFormatDocComments(t *testing.T) {
+	tests := []struct {
+		name, code string
+	}{
+		{
+			name: "list",
+			code: "package a\n\n// F does things:\n//   - a\n//   - b\n// See [fmt.Println].\nfunc F() {}\n",
+		},
+		{
+			name: "heading",
+			code: "package a\n\n// T is a type.\n// # Usage\n// Use it.\ntype T int\n",
+		},
+		{
+			name: "code-block",
+			code: "package a\n\n// V is a value:\n//    v := V\n//Done.\nvar V = 1\n",
+		},
+		{
+			name: "directive",
+			code: "package a\n\n//go:noinline\n// F does things.\nfunc F() {}\n",
+		},
+		{
+			name: "package-doc",
+			code: "// Copyright 2026\n//   - not a list\n\n// Package a does:\n//  - a\n//  - b\npackage a\n",
+		},
+		{
+			name: "not-doc-comments",
+			code: "package a\n\n//   - detached\n\nfunc F() {\n\t//   - inline\n\tprintln() // trailing:\n\t//   - x\n}\n",
+		},
+		{
+			name: "grouped-specs",
+			code: "package a\n\nconst (\n\t// A is:\n\t//   - a\n\tA = 1\n)\n",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			expect, err := format.Source([]byte(test.code))
+			if err != nil {
+				t.Fatal(err)
+			}
+			file, err := Parse(test.code)
+			if err != nil {
+				t.Fatal(err)
+			}
+			buf := &bytes.Buffer{}
+			if err := NewRestorer().Fprint(buf, file); err != nil {
+				t.Fatal(err)
+			}
+			compare(t, test.code, buf.String())
+
+			r := NewRestorer()
+			r.FormatDocComments = true
+			buf.Reset()
+			if err := r.Fprint(buf, file); err != nil {
+				t.Fatal(err)
+			}
+			compare(t, string(expect), buf.String())
+
+			// the decorations of the file are not modified
+			buf.Reset()
+			if err := NewRestorer().Fprint(buf, file); err != nil {
+				t.Fatal(err)
+			}
+			compare(t, test.code, buf.String())
+		})
+	}
+}
+
+func TestRestoreNamedFile(t *testing.T) {
+	r := NewRestorer()
+	var files []*ast.File
+	var tokenFiles []*token.File
+	for _, name := range []string{"a.go", "b.go"} {
+		file, err := Parse("package a\n\nfunc " + name[:1] + "() {}\n")
+		if err != nil {
+			t.Fatal(err)
+		}
+		f, tf, err := r.RestoreNamedFile(name, file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tf.Name() != name {
+			t.Errorf("expected name %q, found %q", name, tf.Name())
+		}
+		if r.Fset.File(f.Pos()) != tf || r.Fset.File(f.End()) != tf {
+			t.Errorf("%s: positions not in the returned *token.File", name)
+		}
+		if p := r.Fset.Position(f.Decls[0].Pos()); p.Filename != name || p.Line != 3 {
+			t.Errorf("%s: unexpected position %s", name, p)
+		}
+		files = append(files, f)
+		tokenFiles = append(tokenFiles, tf)
+	}
+	if tokenFiles[1].Base() <= tokenFiles[0].Base()+tokenFiles[0].Size() {
+		t.Errorf("files overlap: %d+%d, %d", tokenFiles[0].Base(), tokenFiles[0].Size(), tokenFiles[1].Base())
+	}
+
+	// decorate the restored files from the shared FileSet
+	d := NewDecorator(r.Fset)
+	for i, f := range files {
+		file, err := d.DecorateFile(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		buf := &bytes.Buffer{}
+		if err := Fprint(buf, file); err != nil {
+			t.Fatal(err)
+		}
+		compare(t, "package a\n\nfunc "+string(rune('a'+i))+"() {}\n", buf.String())
+	}
+}
+
+func TestRestorerPreserveCommentWhitespace(t *testing.T) {
+	code := "/*\n *   ____  ____  _____   \n *  |  _ \\/ ___||_   _|  \n *  |_| \\_\\____/  |_|    \n *\t\n */\npackage a\n\nfunc a() {\n\t/* b   \n\t   c */\n\tprintln() /* d \n  e */\n}\n"
+	file, err := Parse(code)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	buf := &bytes.Buffer{}
+	if err := Fprint(buf, file); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() == code {
+		t.Fatal("expected comment white space to be trimmed by default")
+	}
+
+	r := NewRestorer()
+	r.PreserveCommentWhitespace = true
+	buf.Reset()
+	if err := r.Fprint(buf, file); err != nil {
+		t.Fatal(err)
+	}
+	compare(t, code, buf.String())
+
+	// text that looks like the placeholders is kept
+	code = "package a\n\nvar a = `/*dst:preserve:0:0 */`\n\n/*\n\tb   \n*/\nvar b int\n"
+	if file, err = Parse(code); err != nil {
+		t.Fatal(err)
+	}
+	buf.Reset()
+	if err := r.Fprint(buf, file); err != nil {
+		t.Fatal(err)
+	}
+	compare(t, code, buf.String())
+}
+
+func TestRestorerWrapLongLines(t *testing.T) {
+	tests := []struct {
+		name, code, expect string
+	}{
+		{
+			name:   "call",
+			code:   "package a\n\nfunc a() {\n\tprintln(\"aaaaaaaaaa\", \"bbbbbbbbbb\", \"cccccccccc\", \"dddddddddd\")\n\tprintln(\"a\")\n}\n",
+			expect: "package a\n\nfunc a() {\n\tprintln(\n\t\t\"aaaaaaaaaa\",\n\t\t\"bbbbbbbbbb\",\n\t\t\"cccccccccc\",\n\t\t\"dddddddddd\",\n\t)\n\tprintln(\"a\")\n}\n",
+		},
+		{
+			name:   "composite-literal",
+			code:   "package a\n\nvar a = []string{\"aaaaaaaaaa\", \"bbbbbbbbbb\", \"cccccccccc\", \"dddddddddd\", \"eeeeeeeeee\"}\n",
+			expect: "package a\n\nvar a = []string{\n\t\"aaaaaaaaaa\",\n\t\"bbbbbbbbbb\",\n\t\"cccccccccc\",\n\t\"dddddddddd\",\n\t\"eeeeeeeeee\",\n}\n",
+		},
+		{
+			name:   "nested",
+			code:   "package a\n\nvar a = f(g(\"aaaaaaaaaa\", \"bbbbbbbbbb\", \"cccccccccc\"), h(\"dddddddddd\", \"eeeeeeeeee\", \"ffffffffff\"))\n",
+			expect: "package a\n\nvar a = f(\n\tg(\"aaaaaaaaaa\", \"bbbbbbbbbb\", \"cccccccccc\"),\n\th(\"dddddddddd\", \"eeeeeeeeee\", \"ffffffffff\"),\n)\n",
+		},
+		{
+			name:   "decorated",
+			code:   "package a\n\nvar a = f(\"aaaaaaaaaa\", \"bbbbbbbbbb\", \"cccccccccc\" /* c */, \"dddddddddd\", \"eeeeeeeeee\")\n",
+			expect: "package a\n\nvar a = f(\"aaaaaaaaaa\", \"bbbbbbbbbb\", \"cccccccccc\" /* c */, \"dddddddddd\", \"eeeeeeeeee\")\n",
+		},
+		{
+			name:   "short",
+			code:   "package a\n\nvar a = f(\"a\", \"b\")\n",
+			expect: "package a\n\nvar a = f(\"a\", \"b\")\n",
+		},
+		{
+			name:   "lines",
+			code:   "package a\n\nvar a = f(\"aaaaaaaaaa\", \"bbbbbbbbbb\", \"cccccccccc\", \"dddddddddd\")\n\nvar b = []string{\"aaaaaaaaaa\", \"bbbbbbbbbb\", \"cccccccccc\", g(\"dddddddddd\", \"eeeeeeeeee\", \"ffffffffff\", \"gggggggggg\")}\n",
+			expect: "package a\n\nvar a = f(\n\t\"aaaaaaaaaa\",\n\t\"bbbbbbbbbb\",\n\t\"cccccccccc\",\n\t\"dddddddddd\",\n)\n\nvar b = []string{\n\t\"aaaaaaaaaa\",\n\t\"bbbbbbbbbb\",\n\t\"cccccccccc\",\n\tg(\n\t\t\"dddddddddd\",\n\t\t\"eeeeeeeeee\",\n\t\t\"ffffffffff\",\n\t\t\"gggggggggg\",\n\t),\n}\n",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			file, err := Parse(test.code)
+			if err != nil {
+				t.Fatal(err)
+			}
+			r := NewRestorer()
+			r.WrapLongLines = 60
+			buf := &bytes.Buffer{}
+			if err := r.Fprint(buf, file); err != nil {
+				t.Fatal(err)
+			}
+			compare(t, test.expect, buf.String())
+
+			// the lines are wrapped in a copy of the file
+			buf.Reset()
+			if err := Fprint(buf, file); err != nil {
+				t.Fatal(err)
+			}
+			compare(t, test.code, buf.String())
+		})
+	}
+}
+
+func TestRestorerOmitFileDecorations(t *testing.T) {
+	code := `// Copyright 2026 The Authors.
+
+// Package a does things.
+package a
+
+// A does things.
+func A() {} // A is empty
+`
+	file, err := Parse(code)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := NewRestorer()
+	r.OmitFileDecorations = true
+	af, err := r.RestoreFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if af.Doc != nil {
+		t.Errorf("expected no package doc, found %q", af.Doc.Text())
+	}
+	buf := &bytes.Buffer{}
+	r = NewRestorer()
+	r.OmitFileDecorations = true
+	if err := r.Fprint(buf, file); err != nil {
+		t.Fatal(err)
+	}
+	compare(t, "package a\n\n// A does things.\nfunc A() {} // A is empty\n", buf.String())
+
+	// the dst nodes are not modified
+	buf.Reset()
+	if err := Fprint(buf, file); err != nil {
+		t.Fatal(err)
+	}
+	compare(t, code, buf.String())
+}
+
+func TestRestorerAlignedSpecs(t *testing.T) {
+	spec := func(name, value string) *dst.ValueSpec {
+		return &dst.ValueSpec{
+			Names:  []*dst.Ident{dst.NewIdent(name)},
+			Values: []dst.Expr{&dst.BasicLit{Kind: token.INT, Value: value}},
+		}
+	}
+	a, b, c, d := spec("A", "1"), spec("Bbbb", "22"), spec("Cc", "3"), spec("Dddddd", "4")
+	a.Decs.End.Append("// a")
+	b.Decs.End.Append("// b")
+	c.Decs.Before = dst.EmptyLine
+
+	v := spec("E", "5")
+	v.Type = dst.NewIdent("int")
+	w := spec("Ffff", "6")
+
+	f := &dst.File{
+		Name: dst.NewIdent("a"),
+		Decls: []dst.Decl{
+			&dst.GenDecl{Tok: token.CONST, Lparen: true, Rparen: true, Specs: []dst.Spec{a, b, c, d}},
+			&dst.GenDecl{Tok: token.VAR, Lparen: true, Rparen: true, Specs: []dst.Spec{v, w}},
+		},
+	}
+	buf := &bytes.Buffer{}
+	if err := Fprint(buf, f); err != nil {
+		t.Fatal(err)
+	}
+	compare(t, `package a
+
+const (
+	A    = 1  // a
+	Bbbb = 22 // b
+
+	Cc     = 3
+	Dddddd = 4
+)
+
+var (
+	E    int = 5
+	Ffff     = 6
+)
+`, buf.String())
+}
+
+func TestRestorerParenMode(t *testing.T) {
+	code := `package a
+
+var a = ((b + c)) * d + (e * f) - (g - h) - (-i) + (j)
+var k = (l)(m, (n || o) && p || q)
+var r = (*s)(t)
+var u = *(v)
+`
+	tests := []struct {
+		mode   ParenMode
+		expect string
+	}{
+		{
+			mode: ParenPreserve,
+			expect: `package a
+
+var a = (b+c)*d + (e * f) - (g - h) - (-i) + (j)
+var k = (l)(m, (n || o) && p || q)
+var r = (*s)(t)
+var u = *(v)
+`,
+		},
+		{
+			mode: ParenMinimal,
+			expect: `package a
+
+var a = (b+c)*d + e*f - (g - h) - -i + j
+var k = l(m, (n || o) && p || q)
+var r = (*s)(t)
+var u = *v
+`,
+		},
+		{
+			mode: ParenExplicit,
+			expect: `package a
+
+var a = ((b + c) * d) + (e * f) - (g - h) - (-i) + (j)
+var k = (l)(m, ((n || o) && p) || q)
+var r = (*s)(t)
+var u = *(v)
+`,
+		},
+	}
+	for _, test := range tests {
+		file, err := Parse(code)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r := NewRestorer()
+		r.ParenMode = test.mode
+		buf := &bytes.Buffer{}
+		if err := r.Fprint(buf, file); err != nil {
+			t.Fatal(err)
+		}
+		compare(t, test.expect, buf.String())
+	}
+}
+
+func TestRestorerNumericLiterals(t *testing.T) {
+	literals := []string{
+		"0x1p-2", "1_000", "0o17", "0O17", "017", "00", "0b1010", "0B_1010", "0xFF", "0Xff_ff",
+		"1e10", "1E-3", ".5", "1.", "0x1.8P+3", "1_000.000_1", "1i", "0x1p0i", "0o1i", "'a'",
+		"'\\x00'",
+	}
+	for _, lit := range literals {
+		code := "package a\n\nconst a = " + lit + "\n"
+		file, err := Parse(code)
+		if err != nil {
+			t.Fatalf("%s: %v", lit, err)
+		}
+		value := file.Decls[0].(*dst.GenDecl).Specs[0].(*dst.ValueSpec).Values[0].(*dst.BasicLit).Value
+		if value != lit {
+			t.Errorf("%s: decorated as %s", lit, value)
+		}
+
+		// the restored ast has the exact text of the literal
+		_, af, err := RestoreFile(file)
+		if err != nil {
+			t.Fatalf("%s: %v", lit, err)
+		}
+		value = af.Decls[0].(*ast.GenDecl).Specs[0].(*ast.ValueSpec).Values[0].(*ast.BasicLit).Value
+		if value != lit {
+			t.Errorf("%s: restored as %s", lit, value)
+		}
+
+		// the literal is printed exactly, although gofmt would lower case the prefix
+		buf := &bytes.Buffer{}
+		if err := Fprint(buf, file); err != nil {
+			t.Fatal(err)
+		}
+		compare(t, code, buf.String())
+	}
+}
+
+func TestRestorerTrailingCommas(t *testing.T) {
+	code := `package a
+
+var a = f(
+	b, // b
+	c,
+)
+
+var d = []int{
+	1,
+	2,
+}
+
+var e = f(b, c)
+`
+	collapsed := `package a
+
+var a = f(b /* b */, c)
+
+var d = []int{1, 2}
+
+var e = f(b, c)
+`
+	expanded := `package a
+
+var a = f(
+	b /* b */, c,
+)
+
+var d = []int{
+	1, 2,
+}
+
+var e = f(
+	b, c,
+)
+`
+	file, err := Parse(code)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var lists [][]dst.Expr
+	dst.Inspect(file, func(n dst.Node) bool {
+		switch n := n.(type) {
+		case *dst.CallExpr:
+			lists = append(lists, n.Args)
+		case *dst.CompositeLit:
+			lists = append(lists, n.Elts)
+		}
+		return true
+	})
+	print := func(expect string) {
+		t.Helper()
+		buf := &bytes.Buffer{}
+		if err := Fprint(buf, file); err != nil {
+			t.Fatal(err)
+		}
+		compare(t, expect, buf.String())
+		if _, err := parser.ParseFile(token.NewFileSet(), "", buf.Bytes(), 0); err != nil {
+			t.Fatalf("output doesn't parse: %v", err)
+		}
+	}
+
+	// collapse the multi-line calls and composite literals by clearing the newlines (and replacing
+	// the line comment, which would force a line break)
+	for _, list := range lists {
+		for _, e := range list {
+			e.Decorations().Before, e.Decorations().After = dst.None, dst.None
+			if len(e.Decorations().End) > 0 {
+				e.Decorations().End.Replace("/* b */")
+			}
+		}
+	}
+	print(collapsed)
+
+	// a line break after the last element requires a trailing comma
+	for _, list := range lists {
+		list[0].Decorations().Before = dst.NewLine
+		list[len(list)-1].Decorations().After = dst.NewLine
+	}
+	print(expanded)
+}
+
+func TestRestorerCommentStyle(t *testing.T) {
+	code := `package a
+
+//go:generate echo a
+
+// A is a function.
+func A(b int) {
+	/* c */
+	print(b) // d
+	print( /* e */ b)
+	/*
+		f
+	*/
+	print(b) /* g */
+	// h */
+}
+`
+	tests := []struct {
+		style  CommentStyle
+		expect string
+	}{
+		{
+			style:  CommentPreserve,
+			expect: code,
+		},
+		{
+			style: CommentLine,
+			expect: `package a
+
+//go:generate echo a
+
+// A is a function.
+func A(b int) {
+	// c
+	print(b) // d
+	print( /* e */ b)
+	/*
+		f
+	*/
+	print(b) // g
+	// h */
+}
+`,
+		},
+		{
+			style: CommentBlock,
+			expect: `package a
+
+//go:generate echo a
+
+/* A is a function. */
+func A(b int) {
+	/* c */
+	print(b) /* d */
+	print( /* e */ b)
+	/*
+		f
+	*/
+	print(b) /* g */
+	// h */
+}
+`,
+		},
+	}
+	for _, test := range tests {
+		file, err := Parse(code)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r := NewRestorer()
+		r.CommentStyle = test.style
+		buf := &bytes.Buffer{}
+		if err := r.Fprint(buf, file); err != nil {
+			t.Fatal(err)
+		}
+		compare(t, test.expect, buf.String())
+		if _, err := parser.ParseFile(token.NewFileSet(), "", buf.Bytes(), parser.ParseComments); err != nil {
+			t.Fatalf("output doesn't parse: %v", err)
+		}
+	}
+}
+
+func TestRestorerNormalizeCommentSpacing(t *testing.T) {
+	code := `// Package a is a.
+package a
+
+//go:generate echo a
+
+//A is a.
+//
+//	code
+func A(b int) { //nolint
+	/*c*/
+	print(b) //d
+
+	print( /*e*/ b) /* f */
+	/*
+		g
+	*/
+	print(b) //nolint:errcheck
+	//export B
+	/**h*/
+	////
+}
+
+//line a.go:1
+//export C
+func C() {}
+`
+	expect := `// Package a is a.
+package a
+
+//go:generate echo a
+
+// A is a.
+//
+//	code
+func A(b int) { //nolint
+	/* c */
+	print(b) // d
+
+	print( /* e */ b) /* f */
+	/*
+		g
+	*/
+	print(b) //nolint:errcheck
+	//export B
+	/**h*/
+	////
+}
+
+//line a.go:1
+//export C
+func C() {}
+`
+	file, err := Parse(code)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := NewRestorer()
+	r.NormalizeCommentSpacing = true
+	buf := &bytes.Buffer{}
+	if err := r.Fprint(buf, file); err != nil {
+		t.Fatal(err)
+	}
+	compare(t, expect, buf.String())
+
+	// the decorations are not modified
+	buf.Reset()
+	if err := Fprint(buf, file); err != nil {
+		t.Fatal(err)
+	}
+	compare(t, code, buf.String())
+}
+
+func TestRestorerEmptyInterfaceStyle(t *testing.T) {
+	code := `package a
+
+type T struct{ any int }
+
+func (T) any() {}
+
+func A(a interface{}, b any) map[any]interface {
+	String() string
+} {
+	var c interface { /* c */
+	}
+	_ = T{any: 1}.any
+	return map[interface{}]interface{ String() string }{a: nil, b: nil, c: nil}
+}
+`
+	tests := []struct {
+		style  EmptyInterfaceStyle
+		expect string
+	}{
+		{
+			style:  EmptyInterfacePreserve,
+			expect: code,
+		},
+		{
+			style: EmptyInterfaceAny,
+			expect: `package a
+
+type T struct{ any int }
+
+func (T) any() {}
+
+func A(a any, b any) map[any]interface {
+	String() string
+} {
+	var c interface { /* c */
+	}
+	_ = T{any: 1}.any
+	return map[any]interface{ String() string }{a: nil, b: nil, c: nil}
+}
+`,
+		},
+		{
+			style: EmptyInterfaceInterface,
+			expect: `package a
+
+type T struct{ any int }
+
+func (T) any() {}
+
+func A(a interface{}, b interface{}) map[interface{}]interface {
+	String() string
+} {
+	var c interface { /* c */
+	}
+	_ = T{any: 1}.any
+	return map[interface{}]interface{ String() string }{a: nil, b: nil, c: nil}
+}
+`,
+		},
+	}
+	for _, skipObjects := range []bool{false, true} {
+		for _, test := range tests {
+			d := NewDecorator(nil)
+			d.SkipObjectResolution = skipObjects
+			file, err := d.Parse(code)
+			if err != nil {
+				t.Fatal(err)
+			}
+			r := NewRestorer()
+			r.EmptyInterfaceStyle = test.style
+			buf := &bytes.Buffer{}
+			if err := r.Fprint(buf, file); err != nil {
+				t.Fatal(err)
+			}
+			compare(t, test.expect, buf.String())
+		}
+	}
+}
+
+func TestRestorerEmptyInterfaceAnyDeclared(t *testing.T) {
+	// nothing is converted in a file that declares a name "any"
+	for _, decl := range []string{
+		"type any int",
+		"var any int",
+		"func any() {}",
+		"func f(any int) {}",
+		"func f() { for any := range []int{} { _ = any } }",
+		"func f() { any := 1; _ = any }",
+	} {
+		code := "package a\n\nvar a interface{}\n\n" + decl + "\n"
+		file, err := Parse(code)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r := NewRestorer()
+		r.EmptyInterfaceStyle = EmptyInterfaceAny
+		buf := &bytes.Buffer{}
+		if err := r.Fprint(buf, file); err != nil {
+			t.Fatal(err)
+		}
+		expect, err := format.Source([]byte(code))
+		if err != nil {
+			t.Fatal(err)
+		}
+		compare(t, string(expect), buf.String())
+	}
+}
+
+func TestRestorerCompositeLitComments(t *testing.T) {
+	expect := `package a
+
+var c = Config{
+	Foo: 1,     // a
+	Bar: "bar", // b
+	Baz: 3,
+}
+`
+	lit := &dst.CompositeLit{Type: dst.NewIdent("Config")}
+	for _, field := range []struct{ key, value, comment string }{{"Foo", "1", "// a"}, {"Bar", `"bar"`, "// b"}, {"Baz", "3", ""}} {
+		kind := token.INT
+		if strings.HasPrefix(field.value, `"`) {
+			kind = token.STRING
+		}
+		kv := &dst.KeyValueExpr{Key: dst.NewIdent(field.key), Value: &dst.BasicLit{Kind: kind, Value: field.value}}
+		kv.Decs.Before, kv.Decs.After = dst.NewLine, dst.NewLine
+		if field.comment != "" {
+			kv.Decs.End.Append(field.comment)
+		}
+		lit.Elts = append(lit.Elts, kv)
+	}
+	file := &dst.File{
+		Name: dst.NewIdent("a"),
+		Decls: []dst.Decl{&dst.GenDecl{
+			Tok:   token.VAR,
+			Specs: []dst.Spec{&dst.ValueSpec{Names: []*dst.Ident{dst.NewIdent("c")}, Values: []dst.Expr{lit}}},
+		}},
+	}
+	buf := &bytes.Buffer{}
+	if err := Fprint(buf, file); err != nil {
+		t.Fatal(err)
+	}
+	compare(t, expect, buf.String())
+}
+
+func TestRestorerSpacesOnly(t *testing.T) {
+	code := "package a\n\n/*\n\tdoc\n*/\nfunc A() {\n\tx := `raw\n\ttab`\n\ty := \"a\tb\" // c\td\n\tswitch x {\n\tcase \"a\":\n\t\tprint(x, y, '\t')\n\t}\n\tvar (\n\t\ta   = 1 // a\n\t\tbbb = 2 // b\n\t)\n}\n"
+	expect := "package a\n\n/*\n  doc\n*/\nfunc A() {\n  x := `raw\n\ttab`\n  y := \"a\\tb\" // c  d\n  switch x {\n  case \"a\":\n    print(x, y, '\\t')\n  }\n  var (\n    a   = 1 // a\n    bbb = 2 // b\n  )\n}\n"
+	file, err := Parse(code)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := NewRestorer()
+	r.SpacesOnly = 2
+	buf := &bytes.Buffer{}
+	if err := r.Fprint(buf, file); err != nil {
+		t.Fatal(err)
+	}
+	compare(t, expect, buf.String())
+
+	// the only tab is in the raw string
+	if strings.Count(buf.String(), "\t") != 1 || !strings.Contains(buf.String(), "`raw\n\ttab`") {
+		t.Errorf("unexpected tabs in %q", buf.String())
+	}
+}
+
+func TestToAst(t *testing.T) {
+	f, err := Parse(`// Package a is a package.
+package a
+
+// A returns b.
+func A(b int) int {
+	return b // c
+}
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fn := f.Decls[0].(*dst.FuncDecl)
+	fn.Type.Results.List[0].Type = dst.NewIdent("string")
+	fn.Body.List[0].(*dst.ReturnStmt).Results[0] = &dst.CallExpr{
+		Fun:  dst.NewIdent("string"),
+		Args: []dst.Expr{dst.NewIdent("b")},
+	}
+
+	af, fset, err := ToAst(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if af.Doc == nil || af.Doc.Text() != "Package a is a package.\n" {
+		t.Errorf("unexpected package doc %#v", af.Doc)
+	}
+	if len(af.Comments) != 3 {
+		t.Errorf("expected 3 comment groups, found %d", len(af.Comments))
+	}
+
+	info := &types.Info{Defs: map[*ast.Ident]types.Object{}}
+	if _, err := (&types.Config{}).Check("a", fset, []*ast.File{af}, info); err != nil {
+		t.Fatal(err)
+	}
+	var found string
+	for id, obj := range info.Defs {
+		if id.Name == "A" {
+			found = obj.Type().String()
+			if pos := fset.Position(id.Pos()); pos.Line != 5 {
+				t.Errorf("expected A on line 5, found %s", pos)
+			}
+		}
+	}
+	if expect := "func(b int) string"; found != expect {
+		t.Errorf("expected %q, found %q", expect, found)
+	}
+
+	buf := &bytes.Buffer{}
+	if err := format.Node(buf, fset, af); err != nil {
+		t.Fatal(err)
+	}
+	compare(t, `// Package a is a package.
+package a
+
+// A returns b.
+func A(b int) string {
+	return string(b) // c
+}
+`, buf.String())
+}
+
+func TestRestorerFinalNewline(t *testing.T) {
+	tests := []struct {
+		name, code string
+		newlines   int
+		expect     map[FinalNewline]string
+	}{
+		{
+			name:     "none",
+			code:     "package a\n\nvar a int",
+			newlines: 0,
+			expect: map[FinalNewline]string{
+				FinalNewlineSingle:   "package a\n\nvar a int\n",
+				FinalNewlineNone:     "package a\n\nvar a int",
+				FinalNewlinePreserve: "package a\n\nvar a int",
+			},
+		},
+		{
+			name:     "single",
+			code:     "package a\n\nvar a int\n",
+			newlines: 1,
+			expect: map[FinalNewline]string{
+				FinalNewlineSingle:   "package a\n\nvar a int\n",
+				FinalNewlineNone:     "package a\n\nvar a int",
+				FinalNewlinePreserve: "package a\n\nvar a int\n",
+			},
+		},
+		{
+			name:     "multiple",
+			code:     "package a\n\nvar a int // a\n\n\n",
+			newlines: 3,
+			expect: map[FinalNewline]string{
+				FinalNewlineSingle:   "package a\n\nvar a int // a\n",
+				FinalNewlineNone:     "package a\n\nvar a int // a",
+				FinalNewlinePreserve: "package a\n\nvar a int // a\n\n\n",
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			d := NewDecorator(nil)
+			f, err := d.Parse(test.code)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if d.FinalNewlines[f] != test.newlines {
+				t.Errorf("expected %d newlines, found %d", test.newlines, d.FinalNewlines[f])
+			}
+			for _, mode := range []FinalNewline{FinalNewlineSingle, FinalNewlineNone, FinalNewlinePreserve} {
+				r := NewRestorer()
+				r.FinalNewline = mode
+				r.FinalNewlines = d.FinalNewlines
+				buf := &bytes.Buffer{}
+				if err := r.Fprint(buf, f); err != nil {
+					t.Fatal(err)
+				}
+				if buf.String() != test.expect[mode] {
+					t.Errorf("mode %d:\nexpect: %q\nfound : %q", mode, test.expect[mode], buf.String())
+				}
+			}
+		})
+	}
+
+	// a file that wasn't decorated ends with a single newline
+	r := NewRestorer()
+	r.FinalNewline = FinalNewlinePreserve
+	buf := &bytes.Buffer{}
+	if err := r.Fprint(buf, &dst.File{Name: dst.NewIdent("a")}); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "package a\n" {
+		t.Errorf("\nexpect: %q\nfound : %q", "package a\n", buf.String())
+	}
+}
+
+func TestRestorerCommentBlocks(t *testing.T) {
+	// a run of contiguous line comments is attached to a single node, so inserting nodes next to
+	// it never splits the block
+	tests := []struct {
+		name, code, expect string
+		insert             func(f *dst.File)
+	}{
+		{
+			name: "doc",
+			code: `package a
+
+func A() {}
+
+// b
+// c
+// d
+func B() {}
+`,
+			expect: `package a
+
+func A() {}
+
+func C() {}
+
+// b
+// c
+// d
+func B() {}
+`,
+			insert: func(f *dst.File) {
+				c := &dst.FuncDecl{Name: dst.NewIdent("C"), Type: &dst.FuncType{}, Body: &dst.BlockStmt{}}
+				c.Decs.Before, c.Decs.After = dst.EmptyLine, dst.EmptyLine
+				f.Decls = []dst.Decl{f.Decls[0], c, f.Decls[1]}
+			},
+		},
+		{
+			name: "no-empty-line",
+			code: `package a
+
+func A() {}
+// b
+// c
+func B() {}
+`,
+			expect: `package a
+
+func A() {}
+func C() {}
+
+// b
+// c
+func B() {}
+`,
+			insert: func(f *dst.File) {
+				c := &dst.FuncDecl{Name: dst.NewIdent("C"), Type: &dst.FuncType{}, Body: &dst.BlockStmt{}}
+				c.Decs.Before, c.Decs.After = dst.NewLine, dst.NewLine
+				f.Decls = []dst.Decl{f.Decls[0], c, f.Decls[1]}
+			},
+		},
+		{
+			name: "after-statement",
+			code: `package a
+
+func A() {
+	x()
+	// b
+	// c
+}
+`,
+			expect: `package a
+
+func A() {
+	x()
+	// b
+	// c
+	y()
+}
+`,
+			insert: func(f *dst.File) {
+				body := f.Decls[0].(*dst.FuncDecl).Body
+				y := &dst.ExprStmt{X: &dst.CallExpr{Fun: dst.NewIdent("y")}}
+				y.Decs.Before, y.Decs.After = dst.NewLine, dst.NewLine
+				body.List = append(body.List, y)
+			},
+		},
+		{
+			name: "spec",
+			code: `package a
+
+var (
+	a int
+	// b
+	// c
+	b int
+)
+`,
+			expect: `package a
+
+var (
+	a int
+	c int
+	// b
+	// c
+	b int
+)
+`,
+			insert: func(f *dst.File) {
+				gd := f.Decls[0].(*dst.GenDecl)
+				c := &dst.ValueSpec{Names: []*dst.Ident{dst.NewIdent("c")}, Type: dst.NewIdent("int")}
+				c.Decs.Before, c.Decs.After = dst.NewLine, dst.NewLine
+				gd.Specs = []dst.Spec{gd.Specs[0], c, gd.Specs[1]}
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			f, err := Parse(test.code)
+			if err != nil {
+				t.Fatal(err)
+			}
+			// all the comments of the block are in the same decorations
+			var found int
+			dst.Inspect(f, func(n dst.Node) bool {
+				if n == nil {
+					return false
+				}
+				decs := n.Decorations()
+				for _, d := range [][]string{decs.Start, decs.End} {
+					var comments int
+					for _, s := range d {
+						if strings.HasPrefix(s, "//") {
+							comments++
+						}
+					}
+					if comments > 0 {
+						found++
+					}
+				}
+				return true
+			})
+			if found != 1 {
+				t.Errorf("expected the block in a single decoration, found %d", found)
+			}
+			test.insert(f)
+			buf := &bytes.Buffer{}
+			if err := Fprint(buf, f); err != nil {
+				t.Fatal(err)
+			}
+			compare(t, test.expect, buf.String())
+		})
+	}
+}
+
+func TestRestorerSeveralEmptyLines(t *testing.T) {
+	// several empty lines are printed as one empty line, as gofmt does
+	code := "package a\n\nfunc a() {\n\ta := 1\n\n\n\t_ = a\n\n\n\n\tb()\n}\n"
+	f, err := Parse(code)
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf := &bytes.Buffer{}
+	if err := Fprint(buf, f); err != nil {
+		t.Fatal(err)
+	}
+	expect, err := format.Source([]byte(code))
+	if err != nil {
+		t.Fatal(err)
+	}
+	compare(t, string(expect), buf.String())
+}
+
+func TestRestorerMaxBlankLines(t *testing.T) {
+	code := `package a
+
+// banner
+
+
+var a int
+
+
+
+// b
+func b() {
+	b := 1
+
+
+	_ = b
+}
+
+// c
+var c = "
+
+
+"
+`
+	collapsed := `package a
+
+// banner
+
+var a int
+
+// b
+func b() {
+	b := 1
+
+	_ = b
+}
+
+// c
+var c = "
+
+
+"
+`
+	tests := []struct {
+		name                string
+		decorate, restore   int
+		expect, decorations string
+	}{
+		{
+			name:   "default",
+			expect: collapsed,
+		},
+		{
+			name:     "decorate-only",
+			decorate: 2,
+			expect:   collapsed,
+		},
+		{
+			// the decorator records the newlines between comments and nodes in the decorations
+			// even when MaxBlankLines isn't set
+			name:    "restore-only",
+			restore: 2,
+			expect: `package a
+
+// banner
+
+
+var a int
+
+// b
+func b() {
+	b := 1
+
+	_ = b
+}
+
+// c
+var c = "
+
+
+"
+`,
+		},
+		{
+			name:     "two",
+			decorate: 2,
+			restore:  2,
+			expect: `package a
+
+// banner
+
+
+var a int
+
+
+// b
+func b() {
+	b := 1
+
+
+	_ = b
+}
+
+// c
+var c = "
+
+
+"
+`,
+		},
+		{
+			name:     "three",
+			decorate: 3,
+			restore:  3,
+			expect: `package a
+
+// banner
+
+
+var a int
+
+
+
+// b
+func b() {
+	b := 1
+
+
+	_ = b
+}
+
+// c
+var c = "
+
+
+"
+`,
+		},
+		{
+			name:     "restore-less",
+			decorate: 3,
+			restore:  2,
+			expect: `package a
+
+// banner
+
+
+var a int
+
+
+// b
+func b() {
+	b := 1
+
+
+	_ = b
+}
+
+// c
+var c = "
+
+
+"
+`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			d := NewDecorator(nil)
+			d.MaxBlankLines = test.decorate
+			f, err := d.Parse(strings.Replace(code, `"`, "`", -1))
+			if err != nil {
+				t.Fatal(err)
+			}
+			r := NewRestorer()
+			r.MaxBlankLines = test.restore
+			buf := &bytes.Buffer{}
+			if err := r.Fprint(buf, f); err != nil {
+				t.Fatal(err)
+			}
+			compare(t, strings.Replace(test.expect, `"`, "`", -1), buf.String())
+		})
+	}
+}
+
+func TestRestorerMaxBlankLinesMarkerText(t *testing.T) {
+	// text that looks like the blank line markers is kept
+	code := "package a\n\nvar a = `\n/*dst:blank*/\n`\n\n\n/*dst:blank*/\nvar b int\n"
+	d := NewDecorator(nil)
+	d.MaxBlankLines = 2
+	f, err := d.Parse(code)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := NewRestorer()
+	r.MaxBlankLines = 2
+	buf := &bytes.Buffer{}
+	if err := r.Fprint(buf, f); err != nil {
+		t.Fatal(err)
+	}
+	compare(t, code, buf.String())
+}
@@ -0,0 +1,53 @@
+package decorator
+
+import (
+	"bytes"
+	"go/scanner"
+	"go/token"
+)
+
+// replaceTabs replaces the tabs in printed source with spaces (width spaces per tab). Tabs in
+// interpreted string and rune literals are replaced with the \t escape sequence, and tabs in raw
+// string literals are left unchanged.
+func replaceTabs(src []byte, width int) []byte {
+	spaces := bytes.Repeat([]byte(" "), width)
+	replace := func(b []byte) []byte {
+		return bytes.ReplaceAll(b, []byte("\t"), spaces)
+	}
+
+	fset := token.NewFileSet()
+	file := fset.AddFile("", fset.Base(), len(src))
+	var s scanner.Scanner
+	s.Init(file, src, nil, scanner.ScanComments)
+
+	out := &bytes.Buffer{}
+	var offset int
+	for {
+		pos, tok, lit := s.Scan()
+		if tok == token.EOF {
+			break
+		}
+		if tok != token.STRING && tok != token.CHAR && tok != token.COMMENT {
+			continue
+		}
+		start := file.Offset(pos)
+		end := start + len(lit)
+		if end > len(src) || string(src[start:end]) != lit {
+			// the literal doesn't match the source (e.g. a comment containing a carriage return),
+			// so leave it for the tabs to be replaced with spaces
+			continue
+		}
+		out.Write(replace(src[offset:start]))
+		switch {
+		case tok == token.COMMENT:
+			out.Write(replace(src[start:end]))
+		case lit[0] == '`':
+			out.Write(src[start:end])
+		default:
+			out.Write(bytes.ReplaceAll(src[start:end], []byte("\t"), []byte(`\t`)))
+		}
+		offset = end
+	}
+	out.Write(replace(src[offset:]))
+	return out.Bytes()
+}
@@ -0,0 +1,71 @@
+package decorator
+
+import (
+	"bytes"
+	"go/parser"
+	"go/token"
+
+	"github.com/dave/dst"
+	"github.com/dave/dst/decorator/resolver"
+	"github.com/dave/dst/decorator/resolver/goast"
+	"github.com/dave/dst/decorator/resolver/guess"
+)
+
+// Transform parses src with import management enabled, runs fn to mutate the file, and returns
+// the restored and formatted source. Package names are guessed from the import path. Use
+// Transformer to configure the resolvers.
+func Transform(src []byte, fn func(*dst.File) error) ([]byte, error) {
+	return (&Transformer{}).Transform(src, fn)
+}
+
+// Transformer bundles the parse, mutate and restore lifecycle of a single file with import
+// management enabled. The zero value is ready to use.
+type Transformer struct {
+	// Path is the local package path. If empty, the package name from the package clause is used.
+	Path string
+	// RestorerResolver resolves package names when restoring, and when resolving identifiers if
+	// DecoratorResolver is nil. If nil, guess.New() is used.
+	RestorerResolver resolver.RestorerResolver
+	// DecoratorResolver resolves identifiers when decorating. If nil,
+	// goast.WithResolver(RestorerResolver) is used.
+	DecoratorResolver resolver.DecoratorResolver
+}
+
+// Transform parses src, runs fn to mutate the file, and returns the restored and formatted source.
+func (t *Transformer) Transform(src []byte, fn func(*dst.File) error) ([]byte, error) {
+
+	rr := t.RestorerResolver
+	if rr == nil {
+		rr = guess.New()
+	}
+	dr := t.DecoratorResolver
+	if dr == nil {
+		dr = goast.WithResolver(rr)
+	}
+
+	fset := token.NewFileSet()
+	af, err := parser.ParseFile(fset, "", src, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+
+	path := t.Path
+	if path == "" {
+		path = af.Name.Name
+	}
+
+	f, err := NewDecoratorWithImports(fset, path, dr).DecorateFile(af)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := fn(f); err != nil {
+		return nil, err
+	}
+
+	buf := &bytes.Buffer{}
+	if err := NewRestorerWithImports(path, rr).Fprint(buf, f); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
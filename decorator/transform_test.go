@@ -0,0 +1,66 @@
+package decorator
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/dave/dst"
+	"github.com/dave/dst/decorator/resolver/guess"
+)
+
+func TestTransform(t *testing.T) {
+	src := `package main
+
+import "fmt"
+
+func main() {
+	fmt.Println("a")
+}
+`
+	expect := `package main
+
+import (
+	"fmt"
+
+	"github.com/a/b-go"
+)
+
+func main() {
+	fmt.Println("a")
+	b.B()
+}
+`
+	addCall := func(f *dst.File) error {
+		body := f.Decls[1].(*dst.FuncDecl).Body
+		body.List = append(body.List, &dst.ExprStmt{
+			X: &dst.CallExpr{Fun: &dst.Ident{Name: "B", Path: "github.com/a/b-go"}},
+		})
+		return nil
+	}
+
+	tr := &Transformer{RestorerResolver: guess.WithMap(map[string]string{"github.com/a/b-go": "b"})}
+	out, err := tr.Transform([]byte(src), addCall)
+	if err != nil {
+		t.Fatal(err)
+	}
+	compare(t, expect, string(out))
+
+	// with the default resolver, the package name is guessed from the path
+	out, err = Transform([]byte(src), func(f *dst.File) error {
+		body := f.Decls[1].(*dst.FuncDecl).Body
+		body.List = []dst.Stmt{&dst.ExprStmt{
+			X:    &dst.CallExpr{Fun: &dst.Ident{Name: "Exit", Path: "os"}},
+			Decs: dst.ExprStmtDecorations{NodeDecs: dst.NodeDecs{Before: dst.NewLine, After: dst.NewLine}},
+		}}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	compare(t, "package main\n\nimport \"os\"\n\nfunc main() {\n\tos.Exit()\n}\n", string(out))
+
+	fail := errors.New("fail")
+	if _, err := Transform([]byte(src), func(*dst.File) error { return fail }); err != fail {
+		t.Errorf("expected %v, found %v", fail, err)
+	}
+}
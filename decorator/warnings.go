@@ -0,0 +1,55 @@
+package decorator
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"strconv"
+)
+
+// Warning is a problem found while decorating that does not prevent the file from being
+// decorated, but may cause the restored file to be invalid.
+type Warning struct {
+	Pos     token.Position // Position of the node that caused the warning
+	Message string
+}
+
+func (w Warning) String() string {
+	return fmt.Sprintf("%s: %s", w.Pos, w.Message)
+}
+
+// detectDuplicateImports adds a warning for each import spec that duplicates the path of a
+// previous import spec, and for each import alias that is used for more than one path.
+func (d *Decorator) detectDuplicateImports(f *ast.File) {
+	paths := map[string]*ast.ImportSpec{}
+	aliases := map[string]string{}
+	for _, spec := range f.Imports {
+		path, err := strconv.Unquote(spec.Path.Value)
+		if err != nil {
+			continue
+		}
+		if path == "C" {
+			// cgo imports may be repeated
+			continue
+		}
+		if prev, ok := paths[path]; ok {
+			d.Warnings = append(d.Warnings, Warning{
+				Pos:     d.Fset.Position(spec.Pos()),
+				Message: fmt.Sprintf("duplicate import %s (previous import at %s)", spec.Path.Value, d.Fset.Position(prev.Pos())),
+			})
+		} else {
+			paths[path] = spec
+		}
+		if spec.Name == nil || spec.Name.Name == "_" || spec.Name.Name == "." {
+			continue
+		}
+		if p, ok := aliases[spec.Name.Name]; ok && p != path {
+			d.Warnings = append(d.Warnings, Warning{
+				Pos:     d.Fset.Position(spec.Pos()),
+				Message: fmt.Sprintf("import alias %s used for %q and %q", spec.Name.Name, p, path),
+			})
+			continue
+		}
+		aliases[spec.Name.Name] = path
+	}
+}
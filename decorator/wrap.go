@@ -0,0 +1,149 @@
+package decorator
+
+import (
+	"bytes"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"strings"
+
+	"github.com/dave/dst"
+)
+
+// wrapTabWidth is the width of a tab when measuring lines for WrapLongLines.
+const wrapTabWidth = 8
+
+// wrapLongLines returns a copy of file with newline decorations added to the arguments of calls
+// and the elements of composite literals that are printed on a line longer than WrapLongLines.
+// The copy is printed, and the output is parsed to find the long lines. The nodes in the parsed
+// output are matched to the dst nodes by their order in the tree. Each iteration wraps the
+// outermost candidate node on each long line, until no more nodes can be wrapped.
+func (r *FileRestorer) wrapLongLines(file *dst.File) (*dst.File, error) {
+	file = dst.Clone(file).(*dst.File)
+	skip := map[dst.Node]bool{}
+	for {
+		// restore and print with a throwaway restorer, so the FileSet and Map are unchanged
+		pr := *r.Restorer
+		pr.Map = newMap()
+		pr.Fset = token.NewFileSet()
+		pr.WrapLongLines = 0
+		trial := &FileRestorer{Restorer: &pr, Alias: r.Alias, Name: r.Name}
+		af, err := trial.RestoreFile(file)
+		if err != nil {
+			return nil, err
+		}
+		buf := &bytes.Buffer{}
+		if err := pr.format(buf, af, newNonce(file)); err != nil {
+			return nil, err
+		}
+
+		fset := token.NewFileSet()
+		pf, err := parser.ParseFile(fset, "", buf.Bytes(), parser.ParseComments)
+		if err != nil {
+			// can't match the output to the nodes, so stop wrapping
+			return file, nil
+		}
+		restored, parsed := wrapCandidates(af), wrapCandidates(pf)
+		if len(restored) != len(parsed) {
+			return file, nil
+		}
+
+		long := map[int]bool{}
+		for i, line := range strings.Split(buf.String(), "\n") {
+			if lineWidth(line) > r.WrapLongLines {
+				long[i+1] = true
+			}
+		}
+
+		var found int
+		for i, n := range parsed {
+			start, end := fset.Position(n.Pos()).Line, fset.Position(n.End()).Line
+			if start != end || !long[start] {
+				continue
+			}
+			dn := trial.Dst.Nodes[restored[i]]
+			if dn == nil || skip[dn] || !wrappable(dn) {
+				continue
+			}
+			wrap(dn)
+			skip[dn] = true
+			found++
+			// wrap the outermost node of the line only, the line is split by this node
+			long[start] = false
+		}
+		if found == 0 {
+			return file, nil
+		}
+	}
+}
+
+// wrapCandidates returns the calls and composite literals in n in depth-first order.
+func wrapCandidates(n ast.Node) []ast.Node {
+	var nodes []ast.Node
+	ast.Inspect(n, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.CallExpr:
+			nodes = append(nodes, n)
+		case *ast.CompositeLit:
+			nodes = append(nodes, n)
+		}
+		return true
+	})
+	return nodes
+}
+
+// wrappable returns true if n has arguments or elements, and none of them (or the opening
+// parenthesis or brace) have any decorations.
+func wrappable(n dst.Node) bool {
+	var list []dst.Expr
+	switch n := n.(type) {
+	case *dst.CallExpr:
+		if len(n.Decs.Lparen) > 0 || len(n.Decs.Ellipsis) > 0 {
+			return false
+		}
+		list = n.Args
+	case *dst.CompositeLit:
+		if len(n.Decs.Lbrace) > 0 {
+			return false
+		}
+		list = n.Elts
+	}
+	if len(list) == 0 {
+		return false
+	}
+	for _, e := range list {
+		decs := e.Decorations()
+		if decs.Before != dst.None || decs.After != dst.None || len(decs.Start) > 0 || len(decs.End) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// wrap puts each argument or element of n on a new line.
+func wrap(n dst.Node) {
+	var list []dst.Expr
+	switch n := n.(type) {
+	case *dst.CallExpr:
+		list = n.Args
+	case *dst.CompositeLit:
+		list = n.Elts
+	}
+	for _, e := range list {
+		e.Decorations().Before = dst.NewLine
+	}
+	list[len(list)-1].Decorations().After = dst.NewLine
+}
+
+// lineWidth returns the width of a line, with tabs expanded to the next multiple of wrapTabWidth.
+func lineWidth(line string) int {
+	var width int
+	for _, c := range line {
+		if c == '\t' {
+			width += wrapTabWidth - width%wrapTabWidth
+			continue
+		}
+		width++
+	}
+	return width
+}
@@ -98,10 +98,11 @@ func (f *FieldList) NumFields() int {
 type (
 	// A BadExpr node is a placeholder for expressions containing
 	// syntax errors for which no correct expression nodes can be
-	// created.
+	// created. If Source is set, it is restored verbatim.
 	//
 	BadExpr struct {
-		Length int // position range of bad expression
+		Length int    // position range of bad expression
+		Source string // original source of bad expression; or empty
 		Decs   BadExprDecorations
 	}
 
@@ -122,6 +123,10 @@ type (
 	}
 
 	// A BasicLit node represents a literal of basic type.
+	//
+	// Value is decorated and restored verbatim. When printing, go/format formats numeric literals
+	// in the same way as gofmt (e.g. 0X1F is printed as 0x1F).
+	//
 	BasicLit struct {
 		Kind  token.Token // token.INT, token.FLOAT, token.IMAG, token.CHAR, or token.STRING
 		Value string      // literal string; e.g. 42, 0x7f, 3.14, 1e-9, 2.4i, 'a', '\x7f', "foo" or `\m\n\o`
@@ -183,6 +188,11 @@ type (
 	}
 
 	// A CallExpr node represents an expression followed by an argument list.
+	//
+	// There's no field for a trailing comma after the last argument: it's rendered when the
+	// closing parenthesis is on a later line than the last argument (e.g. the last argument has
+	// After set to NewLine), and omitted when the call is rendered on a single line. The same
+	// applies to the elements of a CompositeLit.
 	CallExpr struct {
 		Fun      Expr   // function expression
 		Args     []Expr // function arguments; or nil
@@ -218,6 +228,9 @@ type (
 	// A KeyValueExpr node represents (key : value) pairs
 	// in composite literals.
 	//
+	// In a multi-line composite literal, a comment in the End decorations of an element is
+	// rendered after the comma, as a trailing comment on the line of the element.
+	//
 	KeyValueExpr struct {
 		Key   Expr
 		Value Expr
@@ -353,10 +366,11 @@ func (id *Ident) String() string {
 type (
 	// A BadStmt node is a placeholder for statements containing
 	// syntax errors for which no correct statement nodes can be
-	// created.
+	// created. If Source is set, it is restored verbatim.
 	//
 	BadStmt struct {
-		Length int // position range of bad statement
+		Length int    // position range of bad statement
+		Source string // original source of bad statement; or empty
 		Decs   BadStmtDecorations
 	}
 
@@ -442,6 +456,11 @@ type (
 	}
 
 	// A BlockStmt node represents a braced statement list.
+	//
+	// A block with no statements and no Lbrace decorations is rendered as "{}". A line break or a
+	// comment in the Lbrace decorations renders the block over several lines (e.g. "func f() {\n}"
+	// is decorated with Lbrace set to "\n"). Comments attached to the statements are removed with
+	// them, so use dstutil.ClearBody to empty a function body.
 	BlockStmt struct {
 		List           []Stmt
 		RbraceHasNoPos bool // Sometimes (after a BadExpr?) the rbrace has a zero position, and this causes the brace to render in a different position. We duplicate this in the output for compatibility.
@@ -458,6 +477,10 @@ type (
 	}
 
 	// A CaseClause represents a case of an expression or type switch statement.
+	//
+	// Comments after the colon are attached to the Colon decorations if the case has statements,
+	// or to the End decorations if the case body is empty.
+	//
 	CaseClause struct {
 		List []Expr // list of expressions or types; nil means default case
 		Body []Stmt // statement list; or nil
@@ -480,7 +503,8 @@ type (
 		Decs   TypeSwitchStmtDecorations
 	}
 
-	// A CommClause node represents a case of a select statement.
+	// A CommClause node represents a case of a select statement. Comments after the colon are
+	// attached in the same way as CaseClause.
 	CommClause struct {
 		Comm Stmt   // send or receive statement; nil means default case
 		Body []Stmt // statement list; or nil
@@ -563,7 +587,7 @@ type (
 	ValueSpec struct {
 		Names  []*Ident // value names (len(Names) > 0)
 		Type   Expr     // value type; or nil
-		Values []Expr   // initial values; or nil
+		Values []Expr   // initial values; or nil (an empty slice is treated as nil)
 		Decs   ValueSpecDecorations
 	}
 
@@ -590,10 +614,11 @@ func (*TypeSpec) specNode()   {}
 type (
 	// A BadDecl node is a placeholder for declarations containing
 	// syntax errors for which no correct declaration nodes can be
-	// created.
+	// created. If Source is set, it is restored verbatim.
 	//
 	BadDecl struct {
-		Length int // position range of bad declaration
+		Length int    // position range of bad declaration
+		Source string // original source of bad declaration; or empty
 		Decs   BadDeclDecorations
 	}
 
@@ -608,6 +633,11 @@ type (
 	//	token.TYPE    *TypeSpec
 	//	token.VAR     *ValueSpec
 	//
+	// When Lparen is set, each spec is printed on its own line, and the names, types, values and
+	// trailing comments of consecutive const and var specs are aligned in columns as gofmt would,
+	// so no decorations are required. An EmptyLine in the Before decorations of a spec starts a
+	// new alignment section.
+	//
 	GenDecl struct {
 		Tok    token.Token // IMPORT, CONST, TYPE, VAR
 		Lparen bool
@@ -0,0 +1,157 @@
+package dstutil
+
+import (
+	"errors"
+	"fmt"
+	"go/token"
+
+	"github.com/dave/dst"
+)
+
+// opAssign maps each operator-assignment token to the binary operator.
+var opAssign = map[token.Token]token.Token{
+	token.ADD_ASSIGN:     token.ADD,
+	token.SUB_ASSIGN:     token.SUB,
+	token.MUL_ASSIGN:     token.MUL,
+	token.QUO_ASSIGN:     token.QUO,
+	token.REM_ASSIGN:     token.REM,
+	token.AND_ASSIGN:     token.AND,
+	token.OR_ASSIGN:      token.OR,
+	token.XOR_ASSIGN:     token.XOR,
+	token.SHL_ASSIGN:     token.SHL,
+	token.SHR_ASSIGN:     token.SHR,
+	token.AND_NOT_ASSIGN: token.AND_NOT,
+}
+
+// ExpandOpAssign returns the assignment "x = x op y" that is equivalent to the operator-assignment
+// "x op= y" in stmt. The right-hand side is parenthesized if needed (e.g. "x *= a + b" becomes
+// "x = x * (a + b)"). The left-hand side is duplicated without its decorations, and the other
+// nodes and decorations of stmt are moved to the returned statement, so stmt should be replaced by
+// it. nil is returned if stmt is not an operator-assignment, or the left-hand side contains a
+// function call or a receive operation, which would be evaluated twice.
+//
+// Note: the operator-assignment evaluates the operands of the left-hand side once, but the
+// expanded form reads x on the right-hand side separately. The order of that read relative to the
+// function calls in y is not specified, so for "a[i] += f()" the result differs if f modifies
+// a[i].
+func ExpandOpAssign(stmt *dst.AssignStmt) *dst.AssignStmt {
+	op, ok := opAssign[stmt.Tok]
+	if !ok || len(stmt.Lhs) != 1 || len(stmt.Rhs) != 1 || hasSideEffects(stmt.Lhs[0]) {
+		return nil
+	}
+	y := stmt.Rhs[0]
+	if b, ok := y.(*dst.BinaryExpr); ok && b.Op.Precedence() <= op.Precedence() {
+		y = &dst.ParenExpr{X: y}
+	}
+	out := &dst.AssignStmt{
+		Lhs: stmt.Lhs,
+		Tok: token.ASSIGN,
+		Rhs: []dst.Expr{&dst.BinaryExpr{
+			X:  stripped(stmt.Lhs[0]).(dst.Expr),
+			Op: op,
+			Y:  y,
+		}},
+	}
+	out.Decs = stmt.Decs
+	return out
+}
+
+// CompactToOpAssign is the inverse of ExpandOpAssign: it returns the operator-assignment
+// "x op= y" that is equivalent to the assignment "x = x op y" in stmt, where the two occurrences
+// of x are structurally equal (ignoring decorations). Parentheses around y are removed if they
+// have no decorations. The decorations of the binary expression are moved to y, the decorations of
+// the second occurrence of x are removed, and the other nodes and decorations of stmt are moved to
+// the returned statement, so stmt should be replaced by it. nil is returned if stmt doesn't have
+// this form, or x contains a function call or a receive operation, which would then be evaluated
+// once instead of twice.
+func CompactToOpAssign(stmt *dst.AssignStmt) *dst.AssignStmt {
+	if stmt.Tok != token.ASSIGN || len(stmt.Lhs) != 1 || len(stmt.Rhs) != 1 || hasSideEffects(stmt.Lhs[0]) {
+		return nil
+	}
+	b, ok := stmt.Rhs[0].(*dst.BinaryExpr)
+	if !ok || !structurallyEqual(stmt.Lhs[0], b.X) {
+		return nil
+	}
+	var tok token.Token
+	for t, op := range opAssign {
+		if op == b.Op {
+			tok = t
+		}
+	}
+	if tok == token.ILLEGAL {
+		return nil
+	}
+	y := b.Y
+	if p, ok := y.(*dst.ParenExpr); ok && len(p.Decs.Start)+len(p.Decs.Lparen)+len(p.Decs.X)+len(p.Decs.End) == 0 {
+		y = p.X
+	}
+	decs := y.Decorations()
+	decs.Start.Prepend(b.Decs.Op...)
+	decs.Start.Prepend(b.Decs.Start...)
+	decs.End.Append(b.Decs.End...)
+	out := &dst.AssignStmt{
+		Lhs: stmt.Lhs,
+		Tok: tok,
+		Rhs: []dst.Expr{y},
+	}
+	out.Decs = stmt.Decs
+	return out
+}
+
+// ToDefine changes the assignment "x = y" in stmt to the short variable declaration "x := y". An
+// error is returned if stmt is not a plain assignment, or the left-hand side is not a list of
+// identifiers with at least one that isn't blank. Whether the declaration declares a new variable
+// depends on the scope, which isn't checked. The decorations are unchanged: the decorations after
+// the "=" are rendered after the ":=".
+func ToDefine(stmt *dst.AssignStmt) error {
+	if stmt.Tok != token.ASSIGN {
+		return fmt.Errorf("statement is not a plain assignment: %s", stmt.Tok)
+	}
+	var named bool
+	for i, e := range stmt.Lhs {
+		id, ok := e.(*dst.Ident)
+		if !ok {
+			return fmt.Errorf("left-hand side %d is not an identifier: %T", i, e)
+		}
+		if id.Path != "" {
+			return fmt.Errorf("left-hand side %d is a qualified identifier: %s.%s", i, id.Path, id.Name)
+		}
+		if id.Name != "_" {
+			named = true
+		}
+	}
+	if !named {
+		return errors.New("no non-blank identifiers on left-hand side")
+	}
+	stmt.Tok = token.DEFINE
+	return nil
+}
+
+// ToAssign changes the short variable declaration "x := y" in stmt to the assignment "x = y", e.g.
+// after the declaration of x has been moved to an outer scope. An error is returned if stmt is not
+// a short variable declaration. The decorations are unchanged.
+func ToAssign(stmt *dst.AssignStmt) error {
+	if stmt.Tok != token.DEFINE {
+		return fmt.Errorf("statement is not a short variable declaration: %s", stmt.Tok)
+	}
+	stmt.Tok = token.ASSIGN
+	return nil
+}
+
+// hasSideEffects returns true if e contains a function call (including conversions, which can't
+// be distinguished without type information) or a receive operation.
+func hasSideEffects(e dst.Expr) bool {
+	var found bool
+	dst.Inspect(e, func(n dst.Node) bool {
+		switch n := n.(type) {
+		case *dst.CallExpr:
+			found = true
+		case *dst.UnaryExpr:
+			if n.Op == token.ARROW {
+				found = true
+			}
+		}
+		return !found
+	})
+	return found
+}
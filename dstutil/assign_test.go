@@ -0,0 +1,177 @@
+package dstutil_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/dave/dst"
+	"github.com/dave/dst/decorator"
+	"github.com/dave/dst/dstutil"
+)
+
+func TestExpandOpAssign(t *testing.T) {
+	tests := []struct {
+		name, code, expect string
+	}{
+		{
+			name:   "index",
+			code:   "a[i] += f() // b",
+			expect: "a[i] = a[i] + f() // b",
+		},
+		{
+			name:   "precedence",
+			code:   "x *= y + z",
+			expect: "x = x * (y + z)",
+		},
+		{
+			name:   "higher-precedence",
+			code:   "x += y * z",
+			expect: "x = x + y*z",
+		},
+		{
+			name:   "same-precedence",
+			code:   "x -= y - z",
+			expect: "x = x - (y - z)",
+		},
+		{
+			name:   "decorations",
+			code:   "/* a */ s.x /* b */ <<= /* c */ 1",
+			expect: "/* a */ s.x /* b */ = /* c */ s.x << 1",
+		},
+		{
+			name: "call-in-lhs",
+			code: "a[f()] += 1",
+		},
+		{
+			name: "assign",
+			code: "x = 1",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			stmt, found := assignStmt(t, test.code)
+			expanded := dstutil.ExpandOpAssign(stmt)
+			if test.expect == "" {
+				if expanded != nil {
+					t.Errorf("expected nil, found %s", found(expanded))
+				}
+				return
+			}
+			if expanded == nil {
+				t.Fatal("expected statement, found nil")
+			}
+			if s := found(expanded); s != test.expect {
+				t.Errorf("\nexpect: %q\nfound : %q", test.expect, s)
+			}
+
+			// the inverse restores the original statement
+			compacted := dstutil.CompactToOpAssign(expanded)
+			if compacted == nil {
+				t.Fatal("expected compacted statement, found nil")
+			}
+			if s := found(compacted); s != test.code {
+				t.Errorf("\nexpect: %q\nfound : %q", test.code, s)
+			}
+		})
+	}
+}
+
+func TestCompactToOpAssign(t *testing.T) {
+	tests := []struct {
+		name, code, expect string
+	}{
+		{name: "simple", code: "x = x + 1", expect: "x += 1"},
+		{name: "decorated-x", code: "a[i] = a /* b */ [i] &^ m", expect: "a[i] &^= m"},
+		{name: "paren", code: "x = x * (y + z)", expect: "x *= y + z"},
+		{name: "different", code: "x = y + 1"},
+		{name: "reversed", code: "x = 1 + x"},
+		{name: "comparison", code: "x = x == y"},
+		{name: "call-in-lhs", code: "a[f()] = a[f()] + 1"},
+		{name: "define", code: "x := x + 1"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			stmt, found := assignStmt(t, test.code)
+			compacted := dstutil.CompactToOpAssign(stmt)
+			if test.expect == "" {
+				if compacted != nil {
+					t.Errorf("expected nil, found %s", found(compacted))
+				}
+				return
+			}
+			if compacted == nil {
+				t.Fatal("expected statement, found nil")
+			}
+			if s := found(compacted); s != test.expect {
+				t.Errorf("\nexpect: %q\nfound : %q", test.expect, s)
+			}
+		})
+	}
+}
+
+func TestToDefineToAssign(t *testing.T) {
+	tests := []struct {
+		name, code, expect string
+		define             bool
+	}{
+		{name: "define", code: "x, _ /* a */ = f() // b", expect: "x, _ /* a */ := f() // b", define: true},
+		{name: "define-blank", code: "_, _ = f()", define: true},
+		{name: "define-selector", code: "s.x = 1", define: true},
+		{name: "define-index", code: "x, a[0] = 1, 2", define: true},
+		{name: "define-op", code: "x += 1", define: true},
+		{name: "define-define", code: "x := 1", define: true},
+		{name: "assign", code: "x, y := /* a */ 1, 2 // b", expect: "x, y = /* a */ 1, 2 // b"},
+		{name: "assign-assign", code: "x = 1"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			stmt, found := assignStmt(t, test.code)
+			var err error
+			if test.define {
+				err = dstutil.ToDefine(stmt)
+			} else {
+				err = dstutil.ToAssign(stmt)
+			}
+			if test.expect == "" {
+				if err == nil {
+					t.Errorf("expected error, found %s", found(stmt))
+				}
+				if s := found(stmt); s != test.code {
+					t.Errorf("statement changed: %q", s)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if s := found(stmt); s != test.expect {
+				t.Errorf("\nexpect: %q\nfound : %q", test.expect, s)
+			}
+		})
+	}
+}
+
+// assignStmt parses code as the single statement in the body of a function, and returns it with a
+// function that replaces it and prints the new statement.
+func assignStmt(t *testing.T, code string) (*dst.AssignStmt, func(*dst.AssignStmt) string) {
+	t.Helper()
+	f, err := decorator.Parse("package a\n\nfunc a() {\n\t" + code + "\n}\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body := f.Decls[0].(*dst.FuncDecl).Body
+	return body.List[0].(*dst.AssignStmt), func(stmt *dst.AssignStmt) string {
+		t.Helper()
+		body.List[0] = stmt
+		buf := &bytes.Buffer{}
+		if err := decorator.Fprint(buf, f); err != nil {
+			t.Fatal(err)
+		}
+		prefix, suffix := "package a\n\nfunc a() {\n\t", "\n}\n"
+		s := buf.String()
+		if len(s) < len(prefix)+len(suffix) {
+			t.Fatalf("unexpected output %q", s)
+		}
+		return s[len(prefix) : len(s)-len(suffix)]
+	}
+}
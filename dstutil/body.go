@@ -0,0 +1,17 @@
+package dstutil
+
+import "github.com/dave/dst"
+
+// ClearBody removes the statements from the body of fn, so it's rendered as "{}". The Lbrace
+// decorations are removed with the statements, because a line break or a comment there renders the
+// body over several lines. The decorations before and after the body are kept. If fn has no body
+// (e.g. a function implemented in assembly) an empty body is added.
+func ClearBody(fn *dst.FuncDecl) {
+	if fn.Body == nil {
+		fn.Body = &dst.BlockStmt{}
+		return
+	}
+	fn.Body.List = nil
+	fn.Body.Decs.Lbrace = nil
+	fn.Body.RbraceHasNoPos = false
+}
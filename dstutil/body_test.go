@@ -0,0 +1,79 @@
+package dstutil_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/dave/dst"
+	"github.com/dave/dst/decorator"
+	"github.com/dave/dst/dstutil"
+)
+
+func TestClearBody(t *testing.T) {
+	tests := []struct {
+		name, code, expect string
+	}{
+		{
+			name:   "statements",
+			code:   "package a\n\nfunc a() {\n\tb() // b\n\t// c\n}\n",
+			expect: "package a\n\nfunc a() {}\n",
+		},
+		{
+			name:   "line-break",
+			code:   "package a\n\nfunc a() {\n}\n",
+			expect: "package a\n\nfunc a() {}\n",
+		},
+		{
+			name:   "comment",
+			code:   "package a\n\nfunc a() {\n\t// b\n}\n",
+			expect: "package a\n\nfunc a() {}\n",
+		},
+		{
+			name:   "no-body",
+			code:   "package a\n\nfunc a()\n",
+			expect: "package a\n\nfunc a() {}\n",
+		},
+		{
+			name:   "end",
+			code:   "package a\n\nfunc a() {\n\tb()\n} // b\n",
+			expect: "package a\n\nfunc a() {} // b\n",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			f, err := decorator.Parse(test.code)
+			if err != nil {
+				t.Fatal(err)
+			}
+			dstutil.ClearBody(f.Decls[0].(*dst.FuncDecl))
+			buf := &bytes.Buffer{}
+			if err := decorator.Fprint(buf, f); err != nil {
+				t.Fatal(err)
+			}
+			if buf.String() != test.expect {
+				t.Errorf("\nexpect: %q\nfound : %q", test.expect, buf.String())
+			}
+		})
+	}
+}
+
+func TestEmptyBlock(t *testing.T) {
+	// an empty block with no decorations is collapsed, and a commented empty block is rendered
+	// over several lines
+	f, err := decorator.Parse("package a\n\nfunc a() {\n\tb()\n}\n\nfunc c() {\n\td()\n}\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Decls[0].(*dst.FuncDecl).Body.List = nil
+	body := f.Decls[1].(*dst.FuncDecl).Body
+	body.List = nil
+	body.Decs.Lbrace.Append("\n", "// e")
+	buf := &bytes.Buffer{}
+	if err := decorator.Fprint(buf, f); err != nil {
+		t.Fatal(err)
+	}
+	expect := "package a\n\nfunc a() {}\n\nfunc c() {\n\t// e\n}\n"
+	if buf.String() != expect {
+		t.Errorf("\nexpect: %q\nfound : %q", expect, buf.String())
+	}
+}
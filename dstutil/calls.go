@@ -0,0 +1,84 @@
+package dstutil
+
+import (
+	"go/ast"
+	"strings"
+
+	"github.com/dave/dst"
+	"github.com/dave/dst/decorator/resolver"
+)
+
+// CallSites returns the calls in root to the function name in the package with path pkg, in
+// source order. If pkg is empty, calls to the local function name are returned.
+//
+// Calls to a qualified identifier with Path set (from a file decorated with import management) are
+// matched by the path. Selector expressions (e.g. fmt.Println) are resolved with r, which is passed
+// the import specs of root if it's a *dst.File (goast.New() is suitable). If r is nil, or root is
+// not a *dst.File, selector expressions are matched by text: the package identifier must be the
+// last element of pkg (or pkg itself). An identifier with an Obj is never a package name, so
+// selectors on local variables that shadow the package name are not matched. Similarly, a local
+// call with an Obj must refer to a function, so calls of local variables that shadow the function
+// are not matched.
+func CallSites(root dst.Node, pkg, name string, r resolver.DecoratorResolver) []*dst.CallExpr {
+	var af *ast.File
+	if f, ok := root.(*dst.File); ok && r != nil {
+		af = importsFile(f)
+	}
+	var calls []*dst.CallExpr
+	dst.Inspect(root, func(n dst.Node) bool {
+		call, ok := n.(*dst.CallExpr)
+		if ok && isCallTo(call, pkg, name, af, r) {
+			calls = append(calls, call)
+		}
+		return true
+	})
+	return calls
+}
+
+func isCallTo(call *dst.CallExpr, pkg, name string, af *ast.File, r resolver.DecoratorResolver) bool {
+	switch fun := Unparen(call.Fun).(type) {
+	case *dst.Ident:
+		if fun.Name != name {
+			return false
+		}
+		if fun.Path != "" {
+			return fun.Path == pkg
+		}
+		if fun.Obj != nil {
+			return pkg == "" && fun.Obj.Kind == dst.Fun
+		}
+		if pkg == "" {
+			return true
+		}
+		if af == nil {
+			return false
+		}
+		// a function from a dot-imported package
+		id := ast.NewIdent(fun.Name)
+		path, err := r.ResolveIdent(af, &ast.CallExpr{Fun: id}, "Fun", id)
+		return err == nil && path == pkg
+	case *dst.SelectorExpr:
+		x, ok := fun.X.(*dst.Ident)
+		if !ok || pkg == "" || fun.Sel.Name != name || x.Obj != nil || x.Path != "" {
+			return false
+		}
+		if af == nil {
+			return x.Name == pkg || x.Name == pkg[strings.LastIndex(pkg, "/")+1:]
+		}
+		sel := ast.NewIdent(fun.Sel.Name)
+		path, err := r.ResolveIdent(af, &ast.SelectorExpr{X: ast.NewIdent(x.Name), Sel: sel}, "Sel", sel)
+		return err == nil && path == pkg
+	}
+	return false
+}
+
+// SetVariadicSpread sets whether the last argument of call is spread with "..." (e.g.
+// "append(a, b)" becomes "append(a, b...)"). The decorations after the "..." (the Ellipsis
+// decorations) are rendered before the closing parenthesis either way. A call with no arguments
+// can't be spread, so is not changed.
+func SetVariadicSpread(call *dst.CallExpr, spread bool) {
+	if spread && len(call.Args) == 0 {
+		return
+	}
+	call.Ellipsis = spread
+}
@@ -0,0 +1,107 @@
+package dstutil_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/dave/dst"
+	"github.com/dave/dst/decorator"
+	"github.com/dave/dst/decorator/resolver"
+	"github.com/dave/dst/decorator/resolver/goast"
+	"github.com/dave/dst/dstutil"
+)
+
+func TestCallSites(t *testing.T) {
+	code := `package a
+
+import (
+	"fmt"
+	str "strings"
+)
+
+func B() {}
+
+func A() {
+	fmt.Println("a")
+	B()
+	{
+		fmt := struct{ Println func(...interface{}) }{}
+		fmt.Println("b")
+		B := func() {}
+		B()
+	}
+	str.ToUpper("c")
+	(fmt.Println)("d")
+}
+`
+	f, err := decorator.Parse(code)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name, pkg, fn string
+		resolver      resolver.DecoratorResolver
+		expect        int
+	}{
+		{"qualified", "fmt", "Println", goast.New(), 2},
+		{"qualified-text", "fmt", "Println", nil, 2},
+		{"alias", "strings", "ToUpper", goast.New(), 1},
+		{"alias-text", "strings", "ToUpper", nil, 0},
+		{"local", "", "B", nil, 1},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			calls := dstutil.CallSites(f, test.pkg, test.fn, test.resolver)
+			if len(calls) != test.expect {
+				t.Fatalf("expected %d calls, found %d", test.expect, len(calls))
+			}
+			for _, call := range calls {
+				if len(call.Args) > 0 {
+					if lit, ok := call.Args[0].(*dst.BasicLit); ok && lit.Value == `"b"` {
+						t.Error("matched call on shadowed package name")
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestSetVariadicSpread(t *testing.T) {
+	code := `package a
+
+var a = append(a, b... /* a */) // c
+`
+	f, err := decorator.Parse(code)
+	if err != nil {
+		t.Fatal(err)
+	}
+	call := f.Decls[0].(*dst.GenDecl).Specs[0].(*dst.ValueSpec).Values[0].(*dst.CallExpr)
+	fprint := func() string {
+		t.Helper()
+		buf := &bytes.Buffer{}
+		if err := decorator.Fprint(buf, f); err != nil {
+			t.Fatal(err)
+		}
+		return buf.String()
+	}
+	if found := fprint(); found != code {
+		t.Errorf("\nexpect: %q\nfound : %q", code, found)
+	}
+
+	dstutil.SetVariadicSpread(call, false)
+	expect := "package a\n\nvar a = append(a, b /* a */) // c\n"
+	if found := fprint(); found != expect {
+		t.Errorf("\nexpect: %q\nfound : %q", expect, found)
+	}
+
+	dstutil.SetVariadicSpread(call, true)
+	if found := fprint(); found != code {
+		t.Errorf("\nexpect: %q\nfound : %q", code, found)
+	}
+
+	empty := &dst.CallExpr{Fun: dst.NewIdent("f")}
+	dstutil.SetVariadicSpread(empty, true)
+	if empty.Ellipsis {
+		t.Error("expected a call with no arguments to be unchanged")
+	}
+}
@@ -0,0 +1,119 @@
+package dstutil
+
+import (
+	"reflect"
+
+	"github.com/dave/dst"
+)
+
+// DedupeCases removes the expressions of the case clauses in a switch statement that are
+// structurally equal (ignoring decorations) to an earlier expression of the same or an earlier
+// clause, which would fail to compile for constants. A switch runs the first matching clause, so
+// the behavior is unchanged. Clauses left with no expressions are removed (a default clause is
+// never removed). The removed expressions are returned in order. Use MergeDuplicateCases to move
+// the bodies of the duplicates to the first occurrence instead.
+func DedupeCases(sw *dst.SwitchStmt) []dst.Expr {
+	return dedupeCases(sw, false)
+}
+
+// MergeDuplicateCases is the same as DedupeCases, but the body of each clause that has a duplicate
+// of an expression in an earlier clause is appended to the body of the clause with the first
+// occurrence (duplicates within a clause are only removed). Note that this changes the behavior of
+// the other expressions of the first clause. If the clause with the duplicate is not removed (it
+// has other expressions), its body is cloned.
+func MergeDuplicateCases(sw *dst.SwitchStmt) []dst.Expr {
+	return dedupeCases(sw, true)
+}
+
+func dedupeCases(sw *dst.SwitchStmt, merge bool) []dst.Expr {
+	type occurrence struct {
+		expr   dst.Expr
+		clause *dst.CaseClause
+	}
+	var seen []occurrence
+	var duplicates []dst.Expr
+	var list []dst.Stmt
+	for _, stmt := range sw.Body.List {
+		clause := stmt.(*dst.CaseClause)
+		if clause.List == nil {
+			list = append(list, clause)
+			continue
+		}
+		var exprs []dst.Expr
+		var firsts []*dst.CaseClause
+		for _, e := range clause.List {
+			var first *dst.CaseClause
+			for _, o := range seen {
+				if structurallyEqual(o.expr, e) {
+					first = o.clause
+					break
+				}
+			}
+			if first == nil {
+				exprs = append(exprs, e)
+				seen = append(seen, occurrence{expr: e, clause: clause})
+				continue
+			}
+			duplicates = append(duplicates, e)
+			if first != clause && !containsClause(firsts, first) {
+				firsts = append(firsts, first)
+			}
+		}
+		if merge {
+			for _, first := range firsts {
+				body := clause.Body
+				if len(exprs) > 0 || len(firsts) > 1 {
+					body = nil
+					for _, s := range clause.Body {
+						body = append(body, dst.Clone(s).(dst.Stmt))
+					}
+				}
+				first.Body = append(first.Body, body...)
+			}
+		}
+		if len(exprs) == 0 {
+			continue
+		}
+		clause.List = exprs
+		list = append(list, clause)
+	}
+	sw.Body.List = list
+	return duplicates
+}
+
+func containsClause(list []*dst.CaseClause, c *dst.CaseClause) bool {
+	for _, v := range list {
+		if v == c {
+			return true
+		}
+	}
+	return false
+}
+
+// structurallyEqual returns true if a and b are structurally equal, ignoring decorations and objects.
+func structurallyEqual(a, b dst.Expr) bool {
+	if dst.Hash(a) != dst.Hash(b) {
+		return false
+	}
+	// confirm with a full comparison, in case of a hash collision
+	return reflect.DeepEqual(stripped(a), stripped(b))
+}
+
+// stripped returns a clone of n with the decorations and objects removed.
+func stripped(n dst.Node) dst.Node {
+	n = dst.Clone(n)
+	dst.Inspect(n, func(n dst.Node) bool {
+		if n == nil {
+			return false
+		}
+		v := reflect.ValueOf(n).Elem()
+		if f := v.FieldByName("Decs"); f.IsValid() {
+			f.Set(reflect.Zero(f.Type()))
+		}
+		if id, ok := n.(*dst.Ident); ok {
+			id.Obj = nil
+		}
+		return true
+	})
+	return n
+}
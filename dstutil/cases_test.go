@@ -0,0 +1,137 @@
+package dstutil_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/dave/dst"
+	"github.com/dave/dst/decorator"
+	"github.com/dave/dst/dstutil"
+)
+
+func TestDedupeCases(t *testing.T) {
+	code := `package a
+
+func a(i int) {
+	switch i {
+	case 1, 2:
+		print("a")
+	case 3, 1:
+		print("b")
+	case 2:
+		print("c")
+	default:
+		print("d")
+	}
+}
+`
+	tests := []struct {
+		name   string
+		fn     func(*dst.SwitchStmt) []dst.Expr
+		expect string
+	}{
+		{
+			name: "dedupe",
+			fn:   dstutil.DedupeCases,
+			expect: `package a
+
+func a(i int) {
+	switch i {
+	case 1, 2:
+		print("a")
+	case 3:
+		print("b")
+	default:
+		print("d")
+	}
+}
+`,
+		},
+		{
+			name: "merge",
+			fn:   dstutil.MergeDuplicateCases,
+			expect: `package a
+
+func a(i int) {
+	switch i {
+	case 1, 2:
+		print("a")
+		print("b")
+		print("c")
+	case 3:
+		print("b")
+	default:
+		print("d")
+	}
+}
+`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			f, err := decorator.Parse(code)
+			if err != nil {
+				t.Fatal(err)
+			}
+			sw := f.Decls[0].(*dst.FuncDecl).Body.List[0].(*dst.SwitchStmt)
+			duplicates := test.fn(sw)
+			if len(duplicates) != 2 {
+				t.Fatalf("expected 2 duplicates, found %d", len(duplicates))
+			}
+			for i, value := range []string{"1", "2"} {
+				if lit, ok := duplicates[i].(*dst.BasicLit); !ok || lit.Value != value {
+					t.Errorf("unexpected duplicate %#v", duplicates[i])
+				}
+			}
+			buf := &bytes.Buffer{}
+			if err := decorator.Fprint(buf, f); err != nil {
+				t.Fatal(err)
+			}
+			if buf.String() != test.expect {
+				t.Errorf("\nexpect: %q\nfound : %q", test.expect, buf.String())
+			}
+		})
+	}
+}
+
+func TestDedupeCasesSameClause(t *testing.T) {
+	code := `package a
+
+func a(i int) {
+	switch i {
+	case 1, 1:
+		print("a")
+	case 2, 1, 2:
+		print("b")
+	}
+}
+`
+	expect := `package a
+
+func a(i int) {
+	switch i {
+	case 1:
+		print("a")
+		print("b")
+	case 2:
+		print("b")
+	}
+}
+`
+	f, err := decorator.Parse(code)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sw := f.Decls[0].(*dst.FuncDecl).Body.List[0].(*dst.SwitchStmt)
+	duplicates := dstutil.MergeDuplicateCases(sw)
+	if len(duplicates) != 3 {
+		t.Fatalf("expected 3 duplicates, found %d", len(duplicates))
+	}
+	buf := &bytes.Buffer{}
+	if err := decorator.Fprint(buf, f); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != expect {
+		t.Errorf("\nexpect: %q\nfound : %q", expect, buf.String())
+	}
+}
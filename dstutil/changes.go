@@ -0,0 +1,42 @@
+package dstutil
+
+import (
+	"sync"
+
+	"github.com/dave/dst"
+)
+
+// Changes records which files have been modified. Use ApplyWithChanges to record modifications
+// made with Cursor methods, or MarkChanged to record manual modifications. A nil *Changes records
+// nothing. Changes is safe for concurrent use.
+type Changes struct {
+	m     sync.Mutex
+	files map[*dst.File]bool
+}
+
+// MarkChanged records that f has been modified.
+func (c *Changes) MarkChanged(f *dst.File) {
+	c.mark(f)
+}
+
+// Changed returns true if f has been modified.
+func (c *Changes) Changed(f *dst.File) bool {
+	if c == nil {
+		return false
+	}
+	c.m.Lock()
+	defer c.m.Unlock()
+	return c.files[f]
+}
+
+func (c *Changes) mark(f *dst.File) {
+	if c == nil || f == nil {
+		return
+	}
+	c.m.Lock()
+	defer c.m.Unlock()
+	if c.files == nil {
+		c.files = map[*dst.File]bool{}
+	}
+	c.files[f] = true
+}
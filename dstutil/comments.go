@@ -0,0 +1,81 @@
+package dstutil
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/dave/dst"
+)
+
+// InsertComment inserts a standalone comment into a block, directly before the statement at index
+// (or after the last statement if index == len(block.List)). The comment is rendered on its own
+// line, and spacing controls the line spacing between the comment and the preceding statement
+// (or the opening brace). The text must be a well-formed line or block comment, including the
+// "//" or "/*" markers.
+func InsertComment(block *dst.BlockStmt, index int, text string, spacing dst.SpaceType) error {
+	if err := validateComment(text); err != nil {
+		return err
+	}
+	if index < 0 || index > len(block.List) {
+		return fmt.Errorf("index %d out of range for block with %d statements", index, len(block.List))
+	}
+
+	// decs is the comment and any newline required to start the next line
+	decs := []string{text}
+	if strings.HasPrefix(text, "/*") {
+		decs = append(decs, "\n")
+	}
+
+	if index < len(block.List) {
+		d := block.List[index].Decorations()
+		d.Start.Prepend(decs...)
+		if spacing == dst.None {
+			spacing = dst.NewLine
+		}
+		if index > 0 || spacing == dst.EmptyLine {
+			d.Before = spacing
+		}
+		return nil
+	}
+
+	// after the last statement, the comment goes in the End decorations of the last statement, or
+	// the Lbrace decorations of an empty block
+	var newlines []string
+	switch spacing {
+	case dst.EmptyLine:
+		newlines = []string{"\n", "\n"}
+	default:
+		newlines = []string{"\n"}
+	}
+	var target *dst.Decorations
+	if len(block.List) == 0 {
+		// the Lbrace decorations of an empty block usually end with the newline before the closing
+		// brace, which is replaced by the newline after the comment
+		target = &block.Decs.Lbrace
+		for len(*target) > 0 && (*target)[len(*target)-1] == "\n" {
+			*target = (*target)[:len(*target)-1]
+		}
+	} else {
+		target = &block.List[len(block.List)-1].Decorations().End
+	}
+	target.Append(newlines...)
+	target.Append(decs...)
+	return nil
+}
+
+// validateComment returns an error if text is not a single well-formed line or block comment.
+func validateComment(text string) error {
+	switch {
+	case strings.HasPrefix(text, "//"):
+		if strings.ContainsAny(text, "\r\n") {
+			return fmt.Errorf("line comment must not contain newlines: %q", text)
+		}
+	case strings.HasPrefix(text, "/*"):
+		if len(text) < 4 || !strings.HasSuffix(text, "*/") || strings.Index(text, "*/") != len(text)-2 {
+			return fmt.Errorf("block comment must end with the only \"*/\": %q", text)
+		}
+	default:
+		return fmt.Errorf("comment must start with \"//\" or \"/*\": %q", text)
+	}
+	return nil
+}
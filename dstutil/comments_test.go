@@ -0,0 +1,93 @@
+package dstutil_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/dave/dst"
+	"github.com/dave/dst/decorator"
+	"github.com/dave/dst/dstutil"
+)
+
+func TestInsertComment(t *testing.T) {
+	tests := []struct {
+		name    string
+		code    string
+		index   int
+		text    string
+		spacing dst.SpaceType
+		expect  string
+	}{
+		{
+			name:    "between",
+			code:    "package a\n\nfunc main() {\n\ta := 1\n\tprintln(a)\n}\n",
+			index:   1,
+			text:    "// print it",
+			spacing: dst.NewLine,
+			expect:  "package a\n\nfunc main() {\n\ta := 1\n\t// print it\n\tprintln(a)\n}\n",
+		},
+		{
+			name:    "between-empty-line",
+			code:    "package a\n\nfunc main() {\n\ta := 1\n\t// existing\n\tprintln(a)\n}\n",
+			index:   1,
+			text:    "/* print it */",
+			spacing: dst.EmptyLine,
+			expect:  "package a\n\nfunc main() {\n\ta := 1\n\n\t/* print it */\n\t// existing\n\tprintln(a)\n}\n",
+		},
+		{
+			name:    "first",
+			code:    "package a\n\nfunc main() {\n\ta := 1\n\tprintln(a)\n}\n",
+			index:   0,
+			text:    "// first",
+			spacing: dst.NewLine,
+			expect:  "package a\n\nfunc main() {\n\t// first\n\ta := 1\n\tprintln(a)\n}\n",
+		},
+		{
+			name:    "last",
+			code:    "package a\n\nfunc main() {\n\ta := 1\n\tprintln(a)\n}\n",
+			index:   2,
+			text:    "// last",
+			spacing: dst.EmptyLine,
+			expect:  "package a\n\nfunc main() {\n\ta := 1\n\tprintln(a)\n\n\t// last\n}\n",
+		},
+		{
+			name:    "empty",
+			code:    "package a\n\nfunc main() {\n}\n",
+			index:   0,
+			text:    "// empty",
+			spacing: dst.NewLine,
+			expect:  "package a\n\nfunc main() {\n\t// empty\n}\n",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			f, err := decorator.Parse(test.code)
+			if err != nil {
+				t.Fatal(err)
+			}
+			block := f.Decls[0].(*dst.FuncDecl).Body
+			if err := dstutil.InsertComment(block, test.index, test.text, test.spacing); err != nil {
+				t.Fatal(err)
+			}
+			buf := &bytes.Buffer{}
+			if err := decorator.Fprint(buf, f); err != nil {
+				t.Fatal(err)
+			}
+			if buf.String() != test.expect {
+				t.Errorf("\nexpect: %q\nfound : %q", test.expect, buf.String())
+			}
+		})
+	}
+}
+
+func TestInsertCommentErrors(t *testing.T) {
+	block := &dst.BlockStmt{List: []dst.Stmt{&dst.EmptyStmt{}}}
+	for _, text := range []string{"foo", "// a\nb", "/* a */ b */", "/* a"} {
+		if err := dstutil.InsertComment(block, 0, text, dst.NewLine); err == nil {
+			t.Errorf("expected error for %q", text)
+		}
+	}
+	if err := dstutil.InsertComment(block, 2, "// a", dst.NewLine); err == nil {
+		t.Error("expected error for index out of range")
+	}
+}
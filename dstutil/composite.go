@@ -0,0 +1,45 @@
+package dstutil
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/dave/dst"
+)
+
+// KeyifyComposite converts a positional struct literal (e.g. "T{1, 2}") to the keyed form (e.g.
+// "T{A: 1, B: 2}"), where fieldNames are the names of the fields of the struct in order (e.g. from
+// the type information, or supplied by the caller). The decorations of each element (line spacing,
+// and Start and End decorations) are moved to the new key-value expression, so comments stay with
+// the element. An empty literal is unchanged. An error is returned if the type of lit is not a
+// struct type or a type name, an element is already a key-value pair, the number of elements is
+// not the number of field names, or a field name is blank (a blank field can't be keyed).
+func KeyifyComposite(lit *dst.CompositeLit, fieldNames []string) error {
+	switch Unparen(lit.Type).(type) {
+	case *dst.ArrayType, *dst.MapType, nil:
+		return errors.New("composite literal is not a struct literal")
+	}
+	if len(lit.Elts) == 0 {
+		return nil
+	}
+	for _, elt := range lit.Elts {
+		if _, ok := elt.(*dst.KeyValueExpr); ok {
+			return errors.New("composite literal has a keyed element")
+		}
+	}
+	if len(lit.Elts) != len(fieldNames) {
+		return fmt.Errorf("composite literal has %d elements, but %d field names were supplied", len(lit.Elts), len(fieldNames))
+	}
+	for _, name := range fieldNames {
+		if name == "_" || name == "" {
+			return fmt.Errorf("can't key the blank field %q", name)
+		}
+	}
+	for i, elt := range lit.Elts {
+		kv := &dst.KeyValueExpr{Key: dst.NewIdent(fieldNames[i]), Value: elt}
+		kv.Decs.NodeDecs = *elt.Decorations()
+		*elt.Decorations() = dst.NodeDecs{}
+		lit.Elts[i] = kv
+	}
+	return nil
+}
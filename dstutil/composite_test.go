@@ -0,0 +1,72 @@
+package dstutil_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/dave/dst"
+	"github.com/dave/dst/decorator"
+	"github.com/dave/dst/dstutil"
+)
+
+func TestKeyifyComposite(t *testing.T) {
+	src := `package a
+
+var a = T{1, "b", c /* c */}
+
+var d = T{
+	1, // e
+	// f
+	"f",
+	g(),
+}
+
+var h = T{A: 1}
+
+var i = []int{1, 2, 3}
+`
+	expect := `package a
+
+var a = T{A: 1, B: "b", C: c /* c */}
+
+var d = T{
+	A: 1, // e
+	// f
+	B: "f",
+	C: g(),
+}
+
+var h = T{A: 1}
+
+var i = []int{1, 2, 3}
+`
+	f, err := decorator.Parse(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lit := func(i int) *dst.CompositeLit {
+		return f.Decls[i].(*dst.GenDecl).Specs[0].(*dst.ValueSpec).Values[0].(*dst.CompositeLit)
+	}
+	names := []string{"A", "B", "C"}
+	for i := 0; i < 2; i++ {
+		if err := dstutil.KeyifyComposite(lit(i), names); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := dstutil.KeyifyComposite(lit(2), names); err == nil {
+		t.Error("expected error for keyed literal, found none")
+	}
+	if err := dstutil.KeyifyComposite(lit(3), names); err == nil {
+		t.Error("expected error for slice literal, found none")
+	}
+	if err := dstutil.KeyifyComposite(&dst.CompositeLit{Type: dst.NewIdent("T"), Elts: []dst.Expr{dst.NewIdent("a")}}, names); err == nil {
+		t.Error("expected error for wrong number of elements, found none")
+	}
+	buf := &bytes.Buffer{}
+	if err := decorator.Fprint(buf, f); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != expect {
+		t.Errorf("\nexpect: %q\nfound : %q", expect, buf.String())
+	}
+}
@@ -0,0 +1,36 @@
+package dstutil
+
+import (
+	"errors"
+	"go/token"
+
+	"github.com/dave/dst"
+)
+
+// AppendConst appends a spec with no type or value to a const declaration, so the expression of the
+// previous spec is repeated (e.g. the next value of an iota enum). The spec is rendered on its own
+// line, and the declaration is parenthesized if needed. An error is returned if block is not a
+// const declaration, or if it has no specs (the first spec must have a value).
+func AppendConst(block *dst.GenDecl, name string) (*dst.ValueSpec, error) {
+	if block.Tok != token.CONST {
+		return nil, errors.New("declaration is not a const declaration")
+	}
+	if len(block.Specs) == 0 {
+		return nil, errors.New("const declaration has no specs")
+	}
+	if !block.Lparen {
+		block.Lparen, block.Rparen = true, true
+		for _, spec := range block.Specs {
+			if decs := spec.Decorations(); decs.Before == dst.None {
+				decs.Before = dst.NewLine
+			}
+		}
+	}
+	if last := block.Specs[len(block.Specs)-1].Decorations(); last.After == dst.None {
+		last.After = dst.NewLine
+	}
+	spec := &dst.ValueSpec{Names: []*dst.Ident{dst.NewIdent(name)}}
+	spec.Decs.Before, spec.Decs.After = dst.NewLine, dst.NewLine
+	block.Specs = append(block.Specs, spec)
+	return spec, nil
+}
@@ -0,0 +1,50 @@
+package dstutil_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/dave/dst"
+	"github.com/dave/dst/decorator"
+	"github.com/dave/dst/dstutil"
+)
+
+func TestAppendConst(t *testing.T) {
+	tests := []struct {
+		name, code, expect string
+	}{
+		{
+			name:   "enum",
+			code:   "package a\n\nconst (\n\tA Kind = iota // a\n\tB\n\n\t// c\n\tC\n)\n",
+			expect: "package a\n\nconst (\n\tA Kind = iota // a\n\tB\n\n\t// c\n\tC\n\tD\n)\n",
+		},
+		{
+			name:   "single",
+			code:   "package a\n\n// A is first.\nconst A = iota\n",
+			expect: "package a\n\n// A is first.\nconst (\n\tA = iota\n\tD\n)\n",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			f, err := decorator.Parse(test.code)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := dstutil.AppendConst(f.Decls[0].(*dst.GenDecl), "D"); err != nil {
+				t.Fatal(err)
+			}
+			buf := &bytes.Buffer{}
+			if err := decorator.Fprint(buf, f); err != nil {
+				t.Fatal(err)
+			}
+			if buf.String() != test.expect {
+				t.Errorf("\nexpect: %q\nfound : %q", test.expect, buf.String())
+			}
+		})
+	}
+
+	f, _ := decorator.Parse("package a\n\nvar A = 1\n")
+	if _, err := dstutil.AppendConst(f.Decls[0].(*dst.GenDecl), "B"); err == nil {
+		t.Error("expected error, found none")
+	}
+}
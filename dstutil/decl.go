@@ -0,0 +1,148 @@
+package dstutil
+
+import (
+	"go/token"
+	"strings"
+
+	"github.com/dave/dst"
+)
+
+// RemoveDecl removes a top-level declaration from a file, along with its doc comment and trailing
+// comment. The line spacing of the adjacent declarations is unchanged, so the empty line that
+// separated the declaration is removed with it. Comments in the decorations of decl that don't
+// belong to it are kept: comments in the Start decorations separated from the declaration by an
+// empty line (e.g. a heading for a group of declarations), and comments in the End decorations on
+// the lines below the declaration. They are moved to the Start decorations of the following
+// declaration, separated from its doc comment by an empty line, or if decl is the last declaration
+// to the End decorations of the file. Nothing is changed if decl is not in f.Decls.
+func RemoveDecl(f *dst.File, decl dst.Decl) {
+	index := -1
+	for i, d := range f.Decls {
+		if d == decl {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		return
+	}
+	f.Decls = append(f.Decls[:index:index], f.Decls[index+1:]...)
+
+	decs := decl.Decorations()
+	var kept dst.Decorations
+	for i := len(decs.Start) - 1; i >= 0; i-- {
+		if decs.Start[i] == "\n" {
+			kept = append(kept, trimNewlines(decs.Start[:i])...)
+			break
+		}
+	}
+	for i, d := range decs.End {
+		// the line of the declaration ends at the first line break or line comment
+		if d == "\n" || strings.HasPrefix(d, "//") {
+			if below := trimNewlines(decs.End[i+1:]); len(below) > 0 {
+				if len(kept) > 0 {
+					kept = append(kept, "\n")
+				}
+				kept = append(kept, below...)
+			}
+			break
+		}
+	}
+	if len(kept) == 0 {
+		return
+	}
+	if index == len(f.Decls) {
+		f.Decs.End.Append("\n")
+		f.Decs.End.Append(kept...)
+		return
+	}
+	next := f.Decls[index].Decorations()
+	next.Start.Replace(append(append(kept, "\n"), next.Start...)...)
+}
+
+// GroupDecls merges each run of adjacent top-level declarations with the token tok (token.VAR,
+// token.CONST or token.TYPE) into a single parenthesized declaration. The comments of a merged
+// declaration are moved to its specs: the doc comment (and any comments after the keyword or the
+// opening parenthesis) to the first spec, and the trailing comment to the last spec, so each spec
+// keeps its comments. The line spacing between the merged declarations is kept between the specs.
+// A const declaration that refers to iota is never merged into the previous declaration, because
+// its value would change.
+func GroupDecls(f *dst.File, tok token.Token) {
+	var target *dst.GenDecl
+	decls := make([]dst.Decl, 0, len(f.Decls))
+	for _, decl := range f.Decls {
+		gd, ok := decl.(*dst.GenDecl)
+		if !ok || gd.Tok != tok {
+			target = nil
+			decls = append(decls, decl)
+			continue
+		}
+		if target == nil || len(gd.Specs) == 0 || tok == token.CONST && usesIota(gd) {
+			target = nil
+			if len(gd.Specs) > 0 {
+				target = gd
+			}
+			decls = append(decls, gd)
+			continue
+		}
+		if !target.Lparen {
+			groupSpecs(target)
+		} else if len(target.Decs.End) > 0 {
+			// the comments after the closing parenthesis follow the last spec
+			target.Specs[len(target.Specs)-1].Decorations().End.Append(target.Decs.End...)
+			target.Decs.End = nil
+		}
+		first, last := gd.Specs[0].Decorations(), gd.Specs[len(gd.Specs)-1].Decorations()
+		first.Start.Prepend(gd.Decs.Lparen...)
+		first.Start.Prepend(gd.Decs.Tok...)
+		first.Start.Prepend(gd.Decs.Start...)
+		last.End.Append(gd.Decs.End...)
+		first.Before = gd.Decs.Before
+		for _, spec := range gd.Specs {
+			decs := spec.Decorations()
+			if decs.Before == dst.None {
+				decs.Before = dst.NewLine
+			}
+			decs.After = dst.NewLine
+		}
+		target.Specs = append(target.Specs, gd.Specs...)
+		target.Decs.After = gd.Decs.After
+	}
+	f.Decls = decls
+}
+
+// groupSpecs adds parentheses to a declaration with a single spec, moving the comments of the
+// declaration to the spec.
+func groupSpecs(gd *dst.GenDecl) {
+	spec := gd.Specs[0].Decorations()
+	spec.Start.Prepend(gd.Decs.Tok...)
+	spec.Start.Prepend(gd.Decs.Start...)
+	spec.End.Append(gd.Decs.End...)
+	spec.Before, spec.After = dst.NewLine, dst.NewLine
+	gd.Decs.Start, gd.Decs.Tok, gd.Decs.End = nil, nil, nil
+	gd.Lparen, gd.Rparen = true, true
+}
+
+// usesIota returns true if a spec of gd refers to iota, or has no values (so repeats the
+// expression of a previous spec).
+func usesIota(gd *dst.GenDecl) bool {
+	var found bool
+	for _, spec := range gd.Specs {
+		vs, ok := spec.(*dst.ValueSpec)
+		if !ok {
+			continue
+		}
+		if len(vs.Values) == 0 {
+			return true
+		}
+		for _, v := range vs.Values {
+			dst.Inspect(v, func(n dst.Node) bool {
+				if id, ok := n.(*dst.Ident); ok && id.Name == "iota" && id.Path == "" {
+					found = true
+				}
+				return !found
+			})
+		}
+	}
+	return found
+}
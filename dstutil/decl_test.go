@@ -0,0 +1,249 @@
+package dstutil_test
+
+import (
+	"bytes"
+	"go/token"
+	"testing"
+
+	"github.com/dave/dst/decorator"
+	"github.com/dave/dst/dstutil"
+)
+
+func TestRemoveDecl(t *testing.T) {
+	code := `package a
+
+// A is a.
+func A() {}
+
+// Section.
+
+// B is b.
+func B() {} // b
+// c
+
+func C() {}
+
+// D is d.
+var D int // d
+`
+	tests := []struct {
+		name   string
+		index  int
+		expect string
+	}{
+		{
+			name:  "first",
+			index: 0,
+			expect: `package a
+
+// Section.
+
+// B is b.
+func B() {} // b
+// c
+
+func C() {}
+
+// D is d.
+var D int // d
+`,
+		},
+		{
+			name:  "middle",
+			index: 1,
+			expect: `package a
+
+// A is a.
+func A() {}
+
+// Section.
+
+// c
+
+func C() {}
+
+// D is d.
+var D int // d
+`,
+		},
+		{
+			name:  "last",
+			index: 3,
+			expect: `package a
+
+// A is a.
+func A() {}
+
+// Section.
+
+// B is b.
+func B() {} // b
+// c
+
+func C() {}
+`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			f, err := decorator.Parse(code)
+			if err != nil {
+				t.Fatal(err)
+			}
+			dstutil.RemoveDecl(f, f.Decls[test.index])
+			buf := &bytes.Buffer{}
+			if err := decorator.Fprint(buf, f); err != nil {
+				t.Fatal(err)
+			}
+			if buf.String() != test.expect {
+				t.Errorf("\nexpect: %q\nfound : %q", test.expect, buf.String())
+			}
+		})
+	}
+
+	// comments below the last declaration are moved to the end of the file
+	f, err := decorator.Parse("package a\n\nvar A int\n\n// B is b.\nvar B int\n// c\n\n// d\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dstutil.RemoveDecl(f, f.Decls[1])
+	buf := &bytes.Buffer{}
+	if err := decorator.Fprint(buf, f); err != nil {
+		t.Fatal(err)
+	}
+	if expect := "package a\n\nvar A int\n\n// c\n\n// d\n"; buf.String() != expect {
+		t.Errorf("\nexpect: %q\nfound : %q", expect, buf.String())
+	}
+}
+
+func TestGroupDecls(t *testing.T) {
+	tests := []struct {
+		name, code, expect string
+		tok                token.Token
+	}{
+		{
+			name: "var",
+			tok:  token.VAR,
+			code: `package a
+
+// A is a.
+var A int // a
+
+var B, C = 1, 2
+
+// D is d.
+var D string
+
+func E() {}
+
+var F int
+`,
+			expect: `package a
+
+var (
+	// A is a.
+	A int // a
+
+	B, C = 1, 2
+
+	// D is d.
+	D string
+)
+
+func E() {}
+
+var F int
+`,
+		},
+		{
+			name: "block",
+			tok:  token.VAR,
+			code: `package a
+
+var (
+	A int
+	B int
+) // b
+var C int // c
+`,
+			expect: `package a
+
+var (
+	A int
+	B int // b
+	C int // c
+)
+`,
+		},
+		{
+			name: "iota",
+			tok:  token.CONST,
+			code: `package a
+
+const A = 1
+const B = 2
+
+const (
+	C = iota
+	D
+)
+const E = iota
+const F = 3
+`,
+			expect: `package a
+
+const (
+	A = 1
+	B = 2
+)
+
+const (
+	C = iota
+	D
+)
+const (
+	E = iota
+	F = 3
+)
+`,
+		},
+		{
+			name: "type",
+			tok:  token.TYPE,
+			code: `package a
+
+var A int
+var B int
+
+type C int
+type D = C
+`,
+			expect: `package a
+
+var A int
+var B int
+
+type (
+	C int
+	D = C
+)
+`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			f, err := decorator.Parse(test.code)
+			if err != nil {
+				t.Fatal(err)
+			}
+			dstutil.GroupDecls(f, test.tok)
+			buf := &bytes.Buffer{}
+			if err := decorator.Fprint(buf, f); err != nil {
+				t.Fatal(err)
+			}
+			if buf.String() != test.expect {
+				t.Errorf("\nexpect: %q\nfound : %q", test.expect, buf.String())
+			}
+		})
+	}
+}
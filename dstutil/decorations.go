@@ -12,3 +12,32 @@ type DecorationPoint struct {
 	Name string
 	Decs []string
 }
+
+// DecorationLocation identifies a decoration by the node, the name of the decoration attachment
+// point (e.g. "Start" or "End") and the index in the list of decorations attached there.
+type DecorationLocation struct {
+	Node  dst.Node
+	Name  string
+	Index int
+}
+
+// FindDecoration returns the locations of all decorations in root (including the children of
+// root) that exactly match text, in depth-first order.
+func FindDecoration(root dst.Node, text string) []DecorationLocation {
+	var locations []DecorationLocation
+	dst.Inspect(root, func(n dst.Node) bool {
+		if n == nil {
+			return false
+		}
+		_, _, points := decorations(n)
+		for _, point := range points {
+			for i, d := range point.Decs {
+				if d == text {
+					locations = append(locations, DecorationLocation{Node: n, Name: point.Name, Index: i})
+				}
+			}
+		}
+		return true
+	})
+	return locations
+}
@@ -0,0 +1,54 @@
+package dstutil_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/dave/dst"
+	"github.com/dave/dst/decorator"
+	"github.com/dave/dst/dstutil"
+)
+
+func TestFindDecoration(t *testing.T) {
+	f, err := decorator.Parse(`package a
+
+// a
+func a() {
+	// a
+	b := 1 // b
+
+	// b
+	println(b /* a */)
+}
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	find := func(text string) string {
+		var found []string
+		for _, l := range dstutil.FindDecoration(f, text) {
+			found = append(found, fmt.Sprintf("%T %s %d", l.Node, l.Name, l.Index))
+		}
+		return strings.Join(found, ", ")
+	}
+	if found, expect := find("// a"), "*dst.FuncDecl Start 0, *dst.AssignStmt Start 0"; found != expect {
+		t.Errorf("\nexpect: %s\nfound : %s", expect, found)
+	}
+	if found, expect := find("// b"), "*dst.AssignStmt End 0, *dst.ExprStmt Start 0"; found != expect {
+		t.Errorf("\nexpect: %s\nfound : %s", expect, found)
+	}
+	if found, expect := find("/* a */"), "*dst.Ident End 0"; found != expect {
+		t.Errorf("\nexpect: %s\nfound : %s", expect, found)
+	}
+	if found := find("// c"); found != "" {
+		t.Errorf("expected no locations, found %s", found)
+	}
+
+	// the location can be used to remove the decoration
+	l := dstutil.FindDecoration(f, "// b")[1]
+	decs := l.Node.(*dst.ExprStmt).Decs.Start
+	if decs[l.Index] != "// b" {
+		t.Errorf("expected %q, found %q", "// b", decs[l.Index])
+	}
+}
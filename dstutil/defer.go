@@ -0,0 +1,88 @@
+package dstutil
+
+import (
+	"go/token"
+
+	"github.com/dave/dst"
+)
+
+// DeferCall returns a defer statement for call. The line spacing before and after call is moved to
+// the statement, which is rendered on its own line.
+func DeferCall(call *dst.CallExpr) *dst.DeferStmt {
+	stmt := &dst.DeferStmt{Call: call}
+	stmt.Decs.Before, stmt.Decs.After = stmtSpacing(&call.Decs.NodeDecs)
+	return stmt
+}
+
+// GoCall returns a go statement for call. The line spacing before and after call is moved to the
+// statement, which is rendered on its own line.
+func GoCall(call *dst.CallExpr) *dst.GoStmt {
+	stmt := &dst.GoStmt{Call: call}
+	stmt.Decs.Before, stmt.Decs.After = stmtSpacing(&call.Decs.NodeDecs)
+	return stmt
+}
+
+// CaptureInClosure returns a defer statement that runs stmt in a closure when the surrounding
+// function returns. The variables named in vars are captured by value when the defer statement is
+// executed, rather than by reference when the closure runs (e.g. to capture loop variables). The
+// types of the variables aren't needed, because the closure is returned by an outer function that
+// is called when the defer statement is executed:
+//
+//	defer func() func() {
+//		a, b := a, b
+//		return func() {
+//			stmt
+//		}
+//	}()()
+//
+// If vars is empty, the result is a plain "defer func() { stmt }()". The line spacing before and
+// after stmt is moved to the defer statement.
+func CaptureInClosure(stmt dst.Stmt, vars []string) *dst.DeferStmt {
+	before, after := stmtSpacing(stmt.Decorations())
+	stmt.Decorations().Before, stmt.Decorations().After = dst.NewLine, dst.NewLine
+
+	inner := &dst.FuncLit{
+		Type: &dst.FuncType{Func: true, Params: &dst.FieldList{}},
+		Body: &dst.BlockStmt{List: []dst.Stmt{stmt}},
+	}
+	var call *dst.CallExpr
+	if len(vars) == 0 {
+		call = &dst.CallExpr{Fun: inner}
+	} else {
+		assign := &dst.AssignStmt{Tok: token.DEFINE}
+		for _, name := range vars {
+			assign.Lhs = append(assign.Lhs, dst.NewIdent(name))
+			assign.Rhs = append(assign.Rhs, dst.NewIdent(name))
+		}
+		assign.Decs.Before, assign.Decs.After = dst.NewLine, dst.NewLine
+		ret := &dst.ReturnStmt{Results: []dst.Expr{inner}}
+		ret.Decs.Before, ret.Decs.After = dst.NewLine, dst.NewLine
+		outer := &dst.FuncLit{
+			Type: &dst.FuncType{
+				Func:    true,
+				Params:  &dst.FieldList{},
+				Results: &dst.FieldList{List: []*dst.Field{{Type: &dst.FuncType{Func: true, Params: &dst.FieldList{}}}}},
+			},
+			Body: &dst.BlockStmt{List: []dst.Stmt{assign, ret}},
+		}
+		call = &dst.CallExpr{Fun: &dst.CallExpr{Fun: outer}}
+	}
+
+	out := &dst.DeferStmt{Call: call}
+	out.Decs.Before, out.Decs.After = before, after
+	return out
+}
+
+// stmtSpacing clears the line spacing of decs, and returns the spacing for a statement that
+// replaces the node. A statement is always rendered on its own line.
+func stmtSpacing(decs *dst.NodeDecs) (before, after dst.SpaceType) {
+	before, after = decs.Before, decs.After
+	decs.Before, decs.After = dst.None, dst.None
+	if before == dst.None {
+		before = dst.NewLine
+	}
+	if after == dst.None {
+		after = dst.NewLine
+	}
+	return before, after
+}
@@ -0,0 +1,65 @@
+package dstutil_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/dave/dst"
+	"github.com/dave/dst/decorator"
+	"github.com/dave/dst/dstutil"
+)
+
+func TestDeferCall(t *testing.T) {
+	tests := []struct {
+		name   string
+		fn     func(stmt *dst.ExprStmt) dst.Stmt
+		expect string
+	}{
+		{
+			name: "defer",
+			fn: func(stmt *dst.ExprStmt) dst.Stmt {
+				return dstutil.DeferCall(stmt.X.(*dst.CallExpr))
+			},
+			expect: "defer f(i)",
+		},
+		{
+			name: "go",
+			fn: func(stmt *dst.ExprStmt) dst.Stmt {
+				return dstutil.GoCall(stmt.X.(*dst.CallExpr))
+			},
+			expect: "go f(i)",
+		},
+		{
+			name: "closure",
+			fn: func(stmt *dst.ExprStmt) dst.Stmt {
+				return dstutil.CaptureInClosure(stmt, nil)
+			},
+			expect: "defer func() {\n\t\t\tf(i)\n\t\t}()",
+		},
+		{
+			name: "capture",
+			fn: func(stmt *dst.ExprStmt) dst.Stmt {
+				return dstutil.CaptureInClosure(stmt, []string{"i", "j"})
+			},
+			expect: "defer func() func() {\n\t\t\ti, j := i, j\n\t\t\treturn func() {\n\t\t\t\tf(i)\n\t\t\t}\n\t\t}()()",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			f, err := decorator.Parse("package a\n\nfunc a() {\n\tfor i, j := range x {\n\t\tf(i)\n\t\tg(j)\n\t}\n}\n")
+			if err != nil {
+				t.Fatal(err)
+			}
+			body := f.Decls[0].(*dst.FuncDecl).Body.List[0].(*dst.RangeStmt).Body
+			body.List[0] = test.fn(body.List[0].(*dst.ExprStmt))
+			buf := &bytes.Buffer{}
+			if err := decorator.Fprint(buf, f); err != nil {
+				t.Fatal(err)
+			}
+			expect := "package a\n\nfunc a() {\n\tfor i, j := range x {\n\t\t" + test.expect + "\n\t\tg(j)\n\t}\n}\n"
+			if buf.String() != expect {
+				t.Errorf("\nexpect: %q\nfound : %q", expect, buf.String())
+			}
+		})
+	}
+}
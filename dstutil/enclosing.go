@@ -0,0 +1,38 @@
+package dstutil
+
+import "github.com/dave/dst"
+
+// EnclosingFunc returns the innermost *dst.FuncDecl or *dst.FuncLit in root that contains target
+// (not including target itself), found in a single walk of root that keeps a stack of the
+// enclosing functions. false is returned if target isn't in a function (e.g. it's in the
+// initializer of a package variable, or it's a function declaration), or isn't in root.
+func EnclosingFunc(root dst.Node, target dst.Node) (dst.Node, bool) {
+	var funcs []dst.Node
+	var fn dst.Node
+	var found bool
+	Apply(root, func(c *Cursor) bool {
+		if found {
+			return false
+		}
+		n := c.Node()
+		if n == target {
+			found = true
+			if len(funcs) > 0 {
+				fn = funcs[len(funcs)-1]
+			}
+			return false
+		}
+		switch n.(type) {
+		case *dst.FuncDecl, *dst.FuncLit:
+			funcs = append(funcs, n)
+		}
+		return true
+	}, func(c *Cursor) bool {
+		switch c.Node().(type) {
+		case *dst.FuncDecl, *dst.FuncLit:
+			funcs = funcs[:len(funcs)-1]
+		}
+		return !found
+	})
+	return fn, fn != nil
+}
@@ -0,0 +1,57 @@
+package dstutil_test
+
+import (
+	"testing"
+
+	"github.com/dave/dst"
+	"github.com/dave/dst/decorator"
+	"github.com/dave/dst/dstutil"
+)
+
+func TestEnclosingFunc(t *testing.T) {
+	code := `package a
+
+var v = 1 + 2
+
+type T struct{}
+
+func (t T) M() {
+	a := 1
+	f := func() {
+		b := 2
+		_ = b
+	}
+	f()
+	_ = a
+}
+`
+	f, err := decorator.Parse(code)
+	if err != nil {
+		t.Fatal(err)
+	}
+	method := f.Decls[2].(*dst.FuncDecl)
+	lit := method.Body.List[1].(*dst.AssignStmt).Rhs[0].(*dst.FuncLit)
+
+	tests := []struct {
+		name   string
+		target dst.Node
+		expect dst.Node
+	}{
+		{"method", method.Body.List[0], method},
+		{"closure", lit.Body.List[0], lit},
+		{"closure-ident", lit.Body.List[1].(*dst.AssignStmt).Rhs[0], lit},
+		{"func-lit", lit, method},
+		{"after-closure", method.Body.List[2], method},
+		{"file-scope", f.Decls[0].(*dst.GenDecl).Specs[0].(*dst.ValueSpec).Values[0], nil},
+		{"func-decl", method, nil},
+		{"not-found", dst.NewIdent("a"), nil},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			fn, ok := dstutil.EnclosingFunc(f, test.target)
+			if ok != (test.expect != nil) || fn != test.expect {
+				t.Errorf("expected %v, found %v (%v)", test.expect, fn, ok)
+			}
+		})
+	}
+}
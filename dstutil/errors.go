@@ -0,0 +1,107 @@
+package dstutil
+
+import (
+	"go/ast"
+	"go/token"
+	"strconv"
+	"strings"
+
+	"github.com/dave/dst"
+	"github.com/dave/dst/decorator/resolver"
+)
+
+// Context describes an error return found by WrapErrorReturns.
+type Context struct {
+	Func   *dst.FuncDecl   // the function
+	Return *dst.ReturnStmt // the return statement
+	Err    *dst.Ident      // the error variable that is returned
+}
+
+// WrapErrorReturns wraps the error variables returned by fn with fmt.Errorf, and returns the number
+// of results wrapped. For each return statement that returns an identifier (other than nil) as a
+// result of type error, format is called and the identifier is replaced by
+// fmt.Errorf("<format>: %w", err), with any "%" in the text escaped. If format returns an empty
+// string the result is left unchanged. Results that aren't identifiers (e.g. an error that is
+// already wrapped) are never changed, and return statements in function literals are skipped
+// because they return from the literal. Bare return statements in a function with named results
+// are not changed either.
+//
+// The fmt package is referred to with r, which is passed a synthetic *ast.File without imports
+// (see WithImports). If r resolves fmt.Errorf to the "fmt" package (i.e. the file imports fmt),
+// the call is a selector expression. Otherwise, or if r is nil, the call is an identifier with
+// Path set to "fmt", so the file must be restored with a resolver (e.g.
+// decorator.NewRestorerWithImports), which adds the import. fn is passed without its file, so
+// WrapErrorReturns can't add the import itself. The decorations of the identifier are moved to the
+// call.
+func WrapErrorReturns(fn *dst.FuncDecl, format func(ctx Context) string, r resolver.DecoratorResolver) int {
+	if fn.Body == nil || fn.Type.Results == nil {
+		return 0
+	}
+	var errs []int
+	var results int
+	for _, field := range fn.Type.Results.List {
+		n := len(field.Names)
+		if n == 0 {
+			n = 1
+		}
+		if id, ok := field.Type.(*dst.Ident); ok && id.Name == "error" && id.Path == "" {
+			for i := 0; i < n; i++ {
+				errs = append(errs, results+i)
+			}
+		}
+		results += n
+	}
+	if len(errs) == 0 {
+		return 0
+	}
+	imported := fmtImported(r)
+
+	var count int
+	dst.Inspect(fn.Body, func(n dst.Node) bool {
+		switch n := n.(type) {
+		case *dst.FuncLit:
+			return false
+		case *dst.ReturnStmt:
+			if len(n.Results) != results {
+				return true
+			}
+			for _, i := range errs {
+				id, ok := n.Results[i].(*dst.Ident)
+				if !ok || id.Path != "" || id.Name == "nil" {
+					continue
+				}
+				text := format(Context{Func: fn, Return: n, Err: id})
+				if text == "" {
+					continue
+				}
+				var fun dst.Expr = &dst.Ident{Name: "Errorf", Path: "fmt"}
+				if imported {
+					fun = &dst.SelectorExpr{X: dst.NewIdent("fmt"), Sel: dst.NewIdent("Errorf")}
+				}
+				call := &dst.CallExpr{
+					Fun: fun,
+					Args: []dst.Expr{
+						&dst.BasicLit{Kind: token.STRING, Value: strconv.Quote(strings.ReplaceAll(text, "%", "%%") + ": %w")},
+						id,
+					},
+				}
+				call.Decs.NodeDecs = id.Decs.NodeDecs
+				id.Decs.NodeDecs = dst.NodeDecs{}
+				n.Results[i] = call
+				count++
+			}
+		}
+		return true
+	})
+	return count
+}
+
+// fmtImported returns true if r resolves fmt.Errorf to the "fmt" package.
+func fmtImported(r resolver.DecoratorResolver) bool {
+	if r == nil {
+		return false
+	}
+	sel := &ast.SelectorExpr{X: ast.NewIdent("fmt"), Sel: ast.NewIdent("Errorf")}
+	path, err := r.ResolveIdent(&ast.File{Name: ast.NewIdent("p")}, sel, "Sel", sel.Sel)
+	return err == nil && path == "fmt"
+}
@@ -0,0 +1,178 @@
+package dstutil_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/dave/dst"
+	"github.com/dave/dst/decorator"
+	"github.com/dave/dst/decorator/resolver/goast"
+	"github.com/dave/dst/decorator/resolver/guess"
+	"github.com/dave/dst/dstutil"
+)
+
+func TestWrapErrorReturns(t *testing.T) {
+	code := `package a
+
+import "os"
+
+func A(name string) error {
+	f, err := os.Open(name)
+	if err != nil {
+		return err // a
+	}
+	if err := f.Close(); err != nil {
+		return /* b */ err
+	}
+	return nil
+}
+
+func B(name string) (int, string, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return 0, "", err
+	}
+	defer func() error {
+		return err
+	}()
+	if err := f.Sync(); err != nil {
+		return 0, "sync", &os.PathError{Op: "sync", Path: name, Err: err}
+	}
+	return 1, name, nil
+}
+
+func C() (a, b error) {
+	return a, b
+}
+
+func D() int {
+	var err int
+	return err
+}
+
+func E() (err error) {
+	_, err = os.Open("e")
+	return
+}
+`
+	expect := `package a
+
+import (
+	"fmt"
+	"os"
+)
+
+func A(name string) error {
+	f, err := os.Open(name)
+	if err != nil {
+		return fmt.Errorf("A 1: %w", err) // a
+	}
+	if err := f.Close(); err != nil {
+		return /* b */ fmt.Errorf("A 2: %w", err)
+	}
+	return nil
+}
+
+func B(name string) (int, string, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return 0, "", fmt.Errorf("B 1: %w", err)
+	}
+	defer func() error {
+		return err
+	}()
+	if err := f.Sync(); err != nil {
+		return 0, "sync", &os.PathError{Op: "sync", Path: name, Err: err}
+	}
+	return 1, name, nil
+}
+
+func C() (a, b error) {
+	return a, fmt.Errorf("C 100%%: %w", b)
+}
+
+func D() int {
+	var err int
+	return err
+}
+
+func E() (err error) {
+	_, err = os.Open("e")
+	return
+}
+`
+	f, err := decorator.NewDecoratorWithImports(nil, "a", goast.New()).Parse(code)
+	if err != nil {
+		t.Fatal(err)
+	}
+	counts := map[string]int{"A": 2, "B": 1, "C": 1, "D": 0, "E": 0}
+	for _, decl := range f.Decls {
+		fn, ok := decl.(*dst.FuncDecl)
+		if !ok {
+			continue
+		}
+		var calls int
+		found := dstutil.WrapErrorReturns(fn, func(ctx dstutil.Context) string {
+			if ctx.Func != fn {
+				t.Errorf("%s: unexpected Func %s", fn.Name.Name, ctx.Func.Name.Name)
+			}
+			if ctx.Err.Name == "a" {
+				return ""
+			}
+			if fn.Name.Name == "C" {
+				// the % is escaped in the format string
+				return "C 100%"
+			}
+			calls++
+			return fn.Name.Name + " " + string(rune('0'+calls))
+		}, nil)
+		if found != counts[fn.Name.Name] {
+			t.Errorf("%s: expected %d, found %d", fn.Name.Name, counts[fn.Name.Name], found)
+		}
+	}
+	buf := &bytes.Buffer{}
+	if err := decorator.NewRestorerWithImports("a", guess.New()).Fprint(buf, f); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != expect {
+		t.Errorf("\nexpect: %q\nfound : %q", expect, buf.String())
+	}
+}
+
+func TestWrapErrorReturnsImported(t *testing.T) {
+	code := `package a
+
+import "fmt"
+
+func A() error {
+	err := fmt.Errorf("a")
+	return err
+}
+`
+	expect := `package a
+
+import "fmt"
+
+func A() error {
+	err := fmt.Errorf("a")
+	return fmt.Errorf("a: %w", err)
+}
+`
+	f, err := decorator.Parse(code)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fn := f.Decls[1].(*dst.FuncDecl)
+	r := dstutil.WithImports(f, goast.New())
+	if found := dstutil.WrapErrorReturns(fn, func(dstutil.Context) string { return "a" }, r); found != 1 {
+		t.Errorf("expected 1, found %d", found)
+	}
+	// fmt is imported, so the file can be printed without a resolver
+	buf := &bytes.Buffer{}
+	if err := decorator.Fprint(buf, f); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != expect {
+		t.Errorf("\nexpect: %q\nfound : %q", expect, buf.String())
+	}
+}
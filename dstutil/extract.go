@@ -0,0 +1,458 @@
+package dstutil
+
+import (
+	"errors"
+	"fmt"
+	"go/token"
+	"go/types"
+
+	"github.com/dave/dst"
+	"github.com/dave/dst/decorator/resolver"
+)
+
+// ExtractFunc moves the statements block.List[start:end] into a new function, and replaces them
+// with a call to the function. The new function and the call are returned, and the caller is
+// responsible for adding the function to the file.
+//
+// The parameters of the function are the variables captured by the statements (see FreeVars, which
+// is passed r, e.g. WithImports(f, goast.New())). The results of the function are the variables
+// declared by the statements that are used after them in block, followed by the captured variables
+// that the statements assign to, which are assigned from the results of the call. The types of the
+// variables are found from their declarations, so the file must be decorated with object
+// resolution. An error is returned if a type can't be found (e.g. a variable declared with the
+// result of a function call), or if the statements contain a return, defer, goto, labeled or
+// fallthrough statement, or a break or continue statement that isn't inside a loop, switch or
+// select statement in the range.
+//
+// The statements keep their decorations, and the line spacing before the first statement and
+// after the last statement is moved to the call.
+func ExtractFunc(block *dst.BlockStmt, start, end int, name string, r resolver.DecoratorResolver) (*dst.FuncDecl, *dst.CallExpr, error) {
+	if start < 0 || end > len(block.List) || start >= end {
+		return nil, nil, fmt.Errorf("invalid statement range [%d:%d]", start, end)
+	}
+	stmts := block.List[start:end]
+	if err := checkExtractable(stmts); err != nil {
+		return nil, nil, err
+	}
+
+	// inside is the set of nodes in the range, used to find declarations outside the range
+	inside := map[dst.Node]bool{}
+	for _, stmt := range stmts {
+		dst.Inspect(stmt, func(n dst.Node) bool {
+			if n != nil {
+				inside[n] = true
+			}
+			return true
+		})
+	}
+
+	// find the captured variables
+	v := newFreeVars(r)
+	v.walkList(stmts, &scope{})
+	if v.err != nil {
+		return nil, nil, v.err
+	}
+	var params []*dst.Ident
+	for _, n := range v.names {
+		id := findRef(stmts, n, inside)
+		if id == nil {
+			return nil, nil, fmt.Errorf("can't find the declaration of %s", n)
+		}
+		params = append(params, id)
+	}
+
+	// find the variables declared at the top level of the range that are used after it
+	var declared []*dst.Ident
+	for _, stmt := range stmts {
+		for _, id := range declaredVars(stmt) {
+			if usedAfter(block.List[end:], id) {
+				declared = append(declared, id)
+			}
+		}
+	}
+
+	// find the captured variables that are assigned
+	assigned := assignedVars(stmts)
+	var reassigned []*dst.Ident
+	for _, id := range params {
+		if assigned[id.Name] {
+			reassigned = append(reassigned, id)
+		}
+	}
+
+	fields := func(ids []*dst.Ident, named bool) (*dst.FieldList, error) {
+		list := &dst.FieldList{}
+		for _, id := range ids {
+			typ, err := varType(id)
+			if err != nil {
+				return nil, err
+			}
+			field := &dst.Field{Type: typ}
+			if named {
+				field.Names = []*dst.Ident{dst.NewIdent(id.Name)}
+			}
+			list.List = append(list.List, field)
+		}
+		return list, nil
+	}
+	paramList, err := fields(params, true)
+	if err != nil {
+		return nil, nil, err
+	}
+	outs := append(append([]*dst.Ident{}, declared...), reassigned...)
+	var results *dst.FieldList
+	if len(outs) > 0 {
+		if results, err = fields(outs, false); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	call := &dst.CallExpr{Fun: dst.NewIdent(name)}
+	for _, id := range params {
+		call.Args = append(call.Args, dst.NewIdent(id.Name))
+	}
+
+	before, after := stmts[0].Decorations().Before, stmts[len(stmts)-1].Decorations().After
+	body := append([]dst.Stmt{}, stmts...)
+	body[0].Decorations().Before = dst.NewLine
+	body[len(body)-1].Decorations().After = dst.NewLine
+	if len(outs) > 0 {
+		ret := &dst.ReturnStmt{}
+		for _, id := range outs {
+			ret.Results = append(ret.Results, dst.NewIdent(id.Name))
+		}
+		ret.Decs.Before, ret.Decs.After = dst.NewLine, dst.NewLine
+		body = append(body, ret)
+	}
+	fd := &dst.FuncDecl{
+		Name: dst.NewIdent(name),
+		Type: &dst.FuncType{Func: true, Params: paramList, Results: results},
+		Body: &dst.BlockStmt{List: body},
+	}
+	fd.Decs.Before, fd.Decs.After = dst.EmptyLine, dst.EmptyLine
+
+	// replace the statements with the call
+	var replacement []dst.Stmt
+	switch {
+	case len(outs) == 0:
+		replacement = []dst.Stmt{&dst.ExprStmt{X: call}}
+	case len(reassigned) == 0:
+		replacement = []dst.Stmt{assignOuts(outs, token.DEFINE, call)}
+	default:
+		// the declared variables are declared before the call, so they can be assigned with the
+		// captured variables
+		for i, id := range declared {
+			spec := &dst.ValueSpec{Names: []*dst.Ident{dst.NewIdent(id.Name)}, Type: results.List[i].Type}
+			spec.Type = dst.Clone(spec.Type).(dst.Expr)
+			replacement = append(replacement, &dst.DeclStmt{Decl: &dst.GenDecl{Tok: token.VAR, Specs: []dst.Spec{spec}}})
+		}
+		replacement = append(replacement, assignOuts(outs, token.ASSIGN, call))
+	}
+	for _, stmt := range replacement {
+		stmt.Decorations().Before, stmt.Decorations().After = dst.NewLine, dst.NewLine
+	}
+	replacement[0].Decorations().Before = before
+	replacement[len(replacement)-1].Decorations().After = after
+	block.List = append(append(append([]dst.Stmt{}, block.List[:start]...), replacement...), block.List[end:]...)
+
+	return fd, call, nil
+}
+
+// assignOuts returns an assignment of the result of call to the variables.
+func assignOuts(outs []*dst.Ident, tok token.Token, call *dst.CallExpr) *dst.AssignStmt {
+	assign := &dst.AssignStmt{Tok: tok, Rhs: []dst.Expr{call}}
+	for _, id := range outs {
+		assign.Lhs = append(assign.Lhs, dst.NewIdent(id.Name))
+	}
+	return assign
+}
+
+// checkExtractable returns an error if the statements contain a statement that changes the control
+// flow of the enclosing function (or a loop outside the statements).
+func checkExtractable(stmts []dst.Stmt) error {
+	var err error
+	// loops counts the enclosing loops, and breakable the enclosing loop, switch and select
+	// statements
+	var loops, breakable int
+	for _, stmt := range stmts {
+		Apply(stmt, func(c *Cursor) bool {
+			switch n := c.Node().(type) {
+			case *dst.FuncLit:
+				return false
+			case *dst.ReturnStmt:
+				err = errors.New("can't extract a return statement")
+			case *dst.DeferStmt:
+				err = errors.New("can't extract a defer statement")
+			case *dst.LabeledStmt:
+				err = errors.New("can't extract a labeled statement")
+			case *dst.ForStmt, *dst.RangeStmt:
+				loops++
+				breakable++
+			case *dst.SwitchStmt, *dst.TypeSwitchStmt, *dst.SelectStmt:
+				breakable++
+			case *dst.BranchStmt:
+				switch {
+				case n.Label != nil || n.Tok == token.GOTO || n.Tok == token.FALLTHROUGH:
+					err = fmt.Errorf("can't extract a %s statement", n.Tok)
+				case n.Tok == token.BREAK && breakable == 0, n.Tok == token.CONTINUE && loops == 0:
+					err = fmt.Errorf("can't extract a %s statement outside of the statements", n.Tok)
+				}
+			}
+			return err == nil
+		}, func(c *Cursor) bool {
+			switch c.Node().(type) {
+			case *dst.ForStmt, *dst.RangeStmt:
+				loops--
+				breakable--
+			case *dst.SwitchStmt, *dst.TypeSwitchStmt, *dst.SelectStmt:
+				breakable--
+			}
+			return true
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// findRef returns the first identifier with the name in stmts that refers to a declaration
+// outside of the statements.
+func findRef(stmts []dst.Stmt, name string, inside map[dst.Node]bool) *dst.Ident {
+	var found *dst.Ident
+	for _, stmt := range stmts {
+		dst.Inspect(stmt, func(n dst.Node) bool {
+			if id, ok := n.(*dst.Ident); ok && found == nil && id.Name == name && id.Obj != nil {
+				if decl, ok := id.Obj.Decl.(dst.Node); ok && !inside[decl] {
+					found = id
+				}
+			}
+			return found == nil
+		})
+	}
+	return found
+}
+
+// declaredVars returns the variables declared by a statement (and not in its nested scopes).
+func declaredVars(stmt dst.Stmt) []*dst.Ident {
+	var ids []*dst.Ident
+	switch stmt := stmt.(type) {
+	case *dst.AssignStmt:
+		if stmt.Tok != token.DEFINE {
+			return nil
+		}
+		for _, e := range stmt.Lhs {
+			// a variable redeclared by the assignment is declared elsewhere
+			if id, ok := e.(*dst.Ident); ok && id.Name != "_" && (id.Obj == nil || id.Obj.Decl == stmt) {
+				ids = append(ids, id)
+			}
+		}
+	case *dst.DeclStmt:
+		if gd, ok := stmt.Decl.(*dst.GenDecl); ok && gd.Tok == token.VAR {
+			for _, spec := range gd.Specs {
+				for _, id := range spec.(*dst.ValueSpec).Names {
+					if id.Name != "_" {
+						ids = append(ids, id)
+					}
+				}
+			}
+		}
+	}
+	return ids
+}
+
+// usedAfter returns true if any of the statements refer to the variable declared by id.
+func usedAfter(stmts []dst.Stmt, id *dst.Ident) bool {
+	var found bool
+	for _, stmt := range stmts {
+		dst.Inspect(stmt, func(n dst.Node) bool {
+			if ref, ok := n.(*dst.Ident); ok && ref != id && ref.Name == id.Name {
+				if id.Obj == nil || ref.Obj == id.Obj {
+					found = true
+				}
+			}
+			return !found
+		})
+	}
+	return found
+}
+
+// assignedVars returns the names of the variables that are assigned (or have their address taken)
+// in the statements. Assignments to a field or element of a variable count as an assignment to the
+// variable.
+func assignedVars(stmts []dst.Stmt) map[string]bool {
+	assigned := map[string]bool{}
+	assign := func(e dst.Expr) {
+		for {
+			switch x := e.(type) {
+			case *dst.Ident:
+				assigned[x.Name] = true
+				return
+			case *dst.SelectorExpr:
+				e = x.X
+			case *dst.IndexExpr:
+				e = x.X
+			case *dst.ParenExpr:
+				e = x.X
+			default:
+				return
+			}
+		}
+	}
+	for _, stmt := range stmts {
+		dst.Inspect(stmt, func(n dst.Node) bool {
+			switch n := n.(type) {
+			case *dst.AssignStmt:
+				if n.Tok != token.DEFINE {
+					for _, e := range n.Lhs {
+						assign(e)
+					}
+				}
+			case *dst.IncDecStmt:
+				assign(n.X)
+			case *dst.RangeStmt:
+				if n.Tok == token.ASSIGN {
+					assign(n.Key)
+					assign(n.Value)
+				}
+			case *dst.UnaryExpr:
+				if n.Op == token.AND {
+					assign(n.X)
+				}
+			}
+			return true
+		})
+	}
+	return assigned
+}
+
+// varType returns the type of the variable declared by the identifier, from the declaration of its
+// object. The type of a variable declared without a type is inferred from the value (see
+// exprType).
+func varType(id *dst.Ident) (dst.Expr, error) {
+	unknown := fmt.Errorf("can't determine the type of %s", id.Name)
+	if id.Obj == nil {
+		return nil, unknown
+	}
+	index := func(names []*dst.Ident) int {
+		for i, n := range names {
+			if n.Name == id.Name {
+				return i
+			}
+		}
+		return -1
+	}
+	var value dst.Expr
+	switch decl := id.Obj.Decl.(type) {
+	case *dst.Field:
+		return dst.Clone(decl.Type).(dst.Expr), nil
+	case *dst.ValueSpec:
+		if decl.Type != nil {
+			return dst.Clone(decl.Type).(dst.Expr), nil
+		}
+		if i := index(decl.Names); i >= 0 && len(decl.Values) == len(decl.Names) {
+			value = decl.Values[i]
+		}
+	case *dst.AssignStmt:
+		var names []*dst.Ident
+		for _, e := range decl.Lhs {
+			n, _ := e.(*dst.Ident)
+			if n == nil {
+				n = &dst.Ident{}
+			}
+			names = append(names, n)
+		}
+		if i := index(names); i >= 0 && len(decl.Rhs) == len(decl.Lhs) {
+			value = decl.Rhs[i]
+		}
+	}
+	if typ := exprType(value); typ != nil {
+		return typ, nil
+	}
+	return nil, unknown
+}
+
+// exprType returns the type of an expression, or nil if the type can't be found without type
+// checking. Only literals, conversions to named types, variables with a known type, and unary and
+// binary expressions of those are supported.
+func exprType(e dst.Expr) dst.Expr {
+	switch e := e.(type) {
+	case *dst.BasicLit:
+		switch e.Kind {
+		case token.INT:
+			return dst.NewIdent("int")
+		case token.FLOAT:
+			return dst.NewIdent("float64")
+		case token.IMAG:
+			return dst.NewIdent("complex128")
+		case token.CHAR:
+			return dst.NewIdent("rune")
+		case token.STRING:
+			return dst.NewIdent("string")
+		}
+	case *dst.CompositeLit:
+		if e.Type != nil {
+			return dst.Clone(e.Type).(dst.Expr)
+		}
+	case *dst.FuncLit:
+		return dst.Clone(e.Type).(dst.Expr)
+	case *dst.ParenExpr:
+		return exprType(e.X)
+	case *dst.Ident:
+		if e.Obj == nil {
+			if e.Name == "true" || e.Name == "false" {
+				return dst.NewIdent("bool")
+			}
+			return nil
+		}
+		if e.Obj.Kind == dst.Var {
+			typ, _ := varType(e)
+			return typ
+		}
+	case *dst.CallExpr:
+		// a conversion to a named type or a predeclared type
+		if len(e.Args) != 1 {
+			return nil
+		}
+		if id, ok := e.Fun.(*dst.Ident); ok && id.Path == "" {
+			if id.Obj != nil && id.Obj.Kind == dst.Typ {
+				return dst.NewIdent(id.Name)
+			}
+			if id.Obj == nil && types.Universe.Lookup(id.Name) != nil {
+				if _, ok := types.Universe.Lookup(id.Name).(*types.TypeName); ok {
+					return dst.NewIdent(id.Name)
+				}
+			}
+		}
+	case *dst.UnaryExpr:
+		switch e.Op {
+		case token.NOT:
+			return dst.NewIdent("bool")
+		case token.AND:
+			if typ := exprType(e.X); typ != nil {
+				return &dst.StarExpr{X: typ}
+			}
+		case token.ADD, token.SUB, token.XOR:
+			return exprType(e.X)
+		}
+	case *dst.StarExpr:
+		if star, ok := exprType(e.X).(*dst.StarExpr); ok {
+			return star.X
+		}
+	case *dst.BinaryExpr:
+		switch e.Op {
+		case token.EQL, token.NEQ, token.LSS, token.LEQ, token.GTR, token.GEQ, token.LAND, token.LOR:
+			return dst.NewIdent("bool")
+		case token.SHL, token.SHR:
+			return exprType(e.X)
+		}
+		// an untyped constant operand takes the type of the other operand
+		_, litX := Unparen(e.X).(*dst.BasicLit)
+		_, litY := Unparen(e.Y).(*dst.BasicLit)
+		if litX && !litY {
+			return exprType(e.Y)
+		}
+		return exprType(e.X)
+	}
+	return nil
+}
@@ -0,0 +1,125 @@
+package dstutil_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/dave/dst"
+	"github.com/dave/dst/decorator"
+	"github.com/dave/dst/decorator/resolver/goast"
+	"github.com/dave/dst/dstutil"
+)
+
+func TestExtractFunc(t *testing.T) {
+	code := `package a
+
+import "fmt"
+
+func A(n int) {
+	fmt.Println(n)
+
+	// a
+	x := n * 2
+	y := x + 1 // b
+
+	fmt.Println(y)
+}
+`
+	expect := `package a
+
+import "fmt"
+
+func A(n int) {
+	fmt.Println(n)
+
+	y := B(n)
+
+	fmt.Println(y)
+}
+
+func B(n int) int {
+	// a
+	x := n * 2
+	y := x + 1 // b
+	return y
+}
+`
+	f, err := decorator.Parse(code)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body := f.Decls[1].(*dst.FuncDecl).Body
+	fd, call, err := dstutil.ExtractFunc(body, 1, 3, "B", dstutil.WithImports(f, goast.New()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(call.Args) != 1 {
+		t.Errorf("unexpected arguments %#v", call.Args)
+	}
+	f.Decls = append(f.Decls, fd)
+
+	buf := &bytes.Buffer{}
+	if err := decorator.Fprint(buf, f); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != expect {
+		t.Errorf("\nexpect: %q\nfound : %q", expect, buf.String())
+	}
+}
+
+func TestExtractFuncAssigned(t *testing.T) {
+	code := `package a
+
+func A(n int) {
+	var total float64
+	for i := 0; i < n; i++ {
+		total += 1.5
+		if i > 2 {
+			break
+		}
+	}
+	print(total)
+}
+`
+	expect := `package a
+
+func A(n int) {
+	var total float64
+	total = B(n, total)
+	print(total)
+}
+
+func B(n int, total float64) float64 {
+	for i := 0; i < n; i++ {
+		total += 1.5
+		if i > 2 {
+			break
+		}
+	}
+	return total
+}
+`
+	f, err := decorator.Parse(code)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body := f.Decls[0].(*dst.FuncDecl).Body
+	fd, _, err := dstutil.ExtractFunc(body, 1, 2, "B", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Decls = append(f.Decls, fd)
+	buf := &bytes.Buffer{}
+	if err := decorator.Fprint(buf, f); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != expect {
+		t.Errorf("\nexpect: %q\nfound : %q", expect, buf.String())
+	}
+
+	// a return statement can't be extracted
+	f, _ = decorator.Parse("package a\n\nfunc A() {\n\tprint()\n\treturn\n}\n")
+	if _, _, err := dstutil.ExtractFunc(f.Decls[0].(*dst.FuncDecl).Body, 0, 2, "B", nil); err == nil {
+		t.Error("expected error, found none")
+	}
+}
@@ -0,0 +1,83 @@
+package dstutil
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/dave/dst"
+)
+
+// ReorderFields permutes the fields of a struct type, so the field at index order[i] becomes the
+// i'th field. The indexes are of st.Fields.List, so a field with several names (e.g. "A, B int")
+// and an embedded field are each a single entry. Each field keeps its names, type, tag and
+// decorations (doc and trailing comments, and the line spacing before and after it, so groups of
+// fields separated by an empty line stay separated). An empty line before the new first field or
+// after the new last field is reduced to a line break. An error is returned if order is not a
+// permutation of the field indexes.
+func ReorderFields(st *dst.StructType, order []int) error {
+	list := st.Fields.List
+	if len(order) != len(list) {
+		return fmt.Errorf("order has %d indexes, but the struct has %d fields", len(order), len(list))
+	}
+	seen := make([]bool, len(list))
+	fields := make([]*dst.Field, len(list))
+	for i, index := range order {
+		if index < 0 || index >= len(list) || seen[index] {
+			return fmt.Errorf("order is not a permutation of the field indexes: %v", order)
+		}
+		seen[index] = true
+		fields[i] = list[index]
+	}
+	st.Fields.List = fields
+	trimFieldSpacing(fields)
+	return nil
+}
+
+// SortFields sorts the fields of a struct type using less to compare the fields. The sort is
+// stable. The fields keep their decorations, as in ReorderFields.
+func SortFields(st *dst.StructType, less func(a, b *dst.Field) bool) {
+	list := st.Fields.List
+	sort.SliceStable(list, func(i, j int) bool {
+		return less(list[i], list[j])
+	})
+	trimFieldSpacing(list)
+}
+
+func trimFieldSpacing(fields []*dst.Field) {
+	if len(fields) == 0 {
+		return
+	}
+	if first := fields[0]; first.Decs.Before == dst.EmptyLine {
+		first.Decs.Before = dst.NewLine
+	}
+	if last := fields[len(fields)-1]; last.Decs.After == dst.EmptyLine {
+		last.Decs.After = dst.NewLine
+	}
+}
+
+// AnnotateFieldOffsets adds a trailing line comment to each field of a struct type, with the text
+// returned by sizes for the index of the field in st.Fields.List (e.g. "// offset 8, size 4"). No
+// comment is added for a field if sizes returns an empty string. Each field is moved onto its own
+// line, so the printer aligns the comments in a column. The comment is added after any existing
+// End decorations of the field, and an existing trailing line comment has the text appended
+// instead (e.g. "// a" becomes "// a; offset 8, size 4").
+func AnnotateFieldOffsets(st *dst.StructType, sizes func(fieldIndex int) string) {
+	for i, field := range st.Fields.List {
+		if field.Decs.Before == dst.None {
+			field.Decs.Before = dst.NewLine
+		}
+		if field.Decs.After == dst.None {
+			field.Decs.After = dst.NewLine
+		}
+		text := sizes(i)
+		if text == "" {
+			continue
+		}
+		if n := len(field.Decs.End); n > 0 && strings.HasPrefix(field.Decs.End[n-1], "//") {
+			field.Decs.End[n-1] += "; " + text
+			continue
+		}
+		field.Decs.End.Append("// " + text)
+	}
+}
@@ -0,0 +1,115 @@
+package dstutil_test
+
+import (
+	"bytes"
+	"fmt"
+	"go/token"
+	"testing"
+
+	"github.com/dave/dst"
+	"github.com/dave/dst/decorator"
+	"github.com/dave/dst/dstutil"
+)
+
+func TestReorderFields(t *testing.T) {
+	code := `package a
+
+type A struct {
+	// B is first.
+	B, C int ` + "`json:\"b\"`" + ` // b
+
+	// D is embedded.
+	D
+	E string ` + "`json:\"e,omitempty\"`" + `
+}
+`
+	expect := `package a
+
+type A struct {
+	E string ` + "`json:\"e,omitempty\"`" + `
+	// B is first.
+	B, C int ` + "`json:\"b\"`" + ` // b
+
+	// D is embedded.
+	D
+}
+`
+	f, err := decorator.Parse(code)
+	if err != nil {
+		t.Fatal(err)
+	}
+	st := f.Decls[0].(*dst.GenDecl).Specs[0].(*dst.TypeSpec).Type.(*dst.StructType)
+	if err := dstutil.ReorderFields(st, []int{2, 0, 1}); err != nil {
+		t.Fatal(err)
+	}
+	buf := &bytes.Buffer{}
+	if err := decorator.Fprint(buf, f); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != expect {
+		t.Errorf("\nexpect: %q\nfound : %q", expect, buf.String())
+	}
+	if err := dstutil.ReorderFields(st, []int{0, 0, 1}); err == nil {
+		t.Error("expected error, found none")
+	}
+
+	// sort the fields with tags first
+	dstutil.SortFields(st, func(a, b *dst.Field) bool {
+		return a.Tag != nil && b.Tag == nil
+	})
+	if len(st.Fields.List[0].Names) != 1 || st.Fields.List[0].Names[0].Name != "E" || st.Fields.List[2].Names != nil {
+		t.Errorf("unexpected order")
+	}
+}
+
+func TestAnnotateFieldOffsets(t *testing.T) {
+	st := &dst.StructType{Fields: &dst.FieldList{}}
+	for _, f := range []struct{ name, typ string }{{"A", "bool"}, {"Count", "int32"}, {"P", "*int"}, {"Name", "string"}} {
+		st.Fields.List = append(st.Fields.List, &dst.Field{
+			Names: []*dst.Ident{dst.NewIdent(f.name)},
+			Type:  dst.NewIdent(f.typ),
+		})
+	}
+	offsets := []string{"offset 0, size 1", "offset 4, size 4", "offset 8, size 8", ""}
+	dstutil.AnnotateFieldOffsets(st, func(i int) string { return offsets[i] })
+
+	f := &dst.File{
+		Name: dst.NewIdent("a"),
+		Decls: []dst.Decl{&dst.GenDecl{
+			Tok:   token.TYPE,
+			Specs: []dst.Spec{&dst.TypeSpec{Name: dst.NewIdent("T"), Type: st}},
+		}},
+	}
+	buf := &bytes.Buffer{}
+	if err := decorator.Fprint(buf, f); err != nil {
+		t.Fatal(err)
+	}
+	expect := `package a
+
+type T struct {
+	A     bool  // offset 0, size 1
+	Count int32 // offset 4, size 4
+	P     *int  // offset 8, size 8
+	Name  string
+}
+`
+	if buf.String() != expect {
+		t.Errorf("\nexpect: %q\nfound : %q", expect, buf.String())
+	}
+
+	// an existing trailing comment has the text appended
+	pf, err := decorator.Parse("package a\n\ntype T struct {\n\tA int // a\n\tBB int /* b */\n}\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	pst := pf.Decls[0].(*dst.GenDecl).Specs[0].(*dst.TypeSpec).Type.(*dst.StructType)
+	dstutil.AnnotateFieldOffsets(pst, func(i int) string { return fmt.Sprintf("offset %d", i*8) })
+	buf.Reset()
+	if err := decorator.Fprint(buf, pf); err != nil {
+		t.Fatal(err)
+	}
+	expect = "package a\n\ntype T struct {\n\tA  int // a; offset 0\n\tBB int /* b */ // offset 8\n}\n"
+	if buf.String() != expect {
+		t.Errorf("\nexpect: %q\nfound : %q", expect, buf.String())
+	}
+}
@@ -0,0 +1,166 @@
+package dstutil
+
+import (
+	"go/build/constraint"
+	"strings"
+
+	"github.com/dave/dst"
+)
+
+// PackageDoc returns the package doc comment of a file: the comments in the File Start decorations
+// that directly precede the package clause with no empty line between them.
+func PackageDoc(f *dst.File) dst.Decorations {
+	_, doc := splitFileStart(f.Decs.Start)
+	return doc
+}
+
+// FileHeader returns the comments in the File Start decorations that are separated from the
+// package clause by an empty line (e.g. a license header or build constraints).
+func FileHeader(f *dst.File) dst.Decorations {
+	header, _ := splitFileStart(f.Decs.Start)
+	return header
+}
+
+// SetPackageDoc replaces the package doc comment of a file. Any header comments are left in place,
+// separated from the package doc by an empty line. The package doc is always rendered directly
+// above the package clause.
+func SetPackageDoc(f *dst.File, doc ...string) {
+	header, _ := splitFileStart(f.Decs.Start)
+	f.Decs.Start.Replace(joinFileStart(header, doc)...)
+}
+
+// SetFileHeader replaces the header comments of a file (e.g. a license header or build
+// constraints). The header is separated from the package doc by an empty line, which ensures the
+// package doc stays attached to the package clause.
+func SetFileHeader(f *dst.File, header ...string) {
+	_, doc := splitFileStart(f.Decs.Start)
+	f.Decs.Start.Replace(joinFileStart(header, doc)...)
+}
+
+// SetPackageComment sets the comment on the same line as the package clause (e.g.
+// "package main // a"), which is in the Name decorations of the file. The full text of the comment
+// is used, including the "//" or "/*" marker. If text is empty, the comment is removed.
+func SetPackageComment(f *dst.File, text string) {
+	if text == "" {
+		f.Decs.Name.Clear()
+		return
+	}
+	f.Decs.Name.Replace(text)
+}
+
+// splitFileStart splits the File Start decorations into the header and the package doc. Newline
+// decorations separating the two, and trailing newlines after the package doc are discarded.
+func splitFileStart(decs dst.Decorations) (header, doc dst.Decorations) {
+	// newlines counts the line breaks following the most recent comment. A line comment ends with
+	// an implicit line break.
+	var newlines int
+	lastHeader, docStart := 0, 0
+	lastComment := -1
+	for i, d := range decs {
+		if d == "\n" {
+			newlines++
+			continue
+		}
+		if newlines >= 2 && lastComment >= 0 {
+			// an empty line precedes this comment, so everything before belongs to the header
+			lastHeader = lastComment + 1
+			docStart = i
+		}
+		lastComment = i
+		newlines = 0
+		if strings.HasPrefix(d, "//") {
+			newlines = 1
+		}
+	}
+	if lastComment < 0 {
+		return nil, nil
+	}
+	if newlines >= 2 {
+		// an empty line separates the last comment from the package clause, so there's no doc
+		return trimNewlines(decs[:lastComment+1]), nil
+	}
+	return trimNewlines(decs[:lastHeader]), trimNewlines(decs[docStart : lastComment+1])
+}
+
+// joinFileStart is the inverse of splitFileStart.
+func joinFileStart(header, doc dst.Decorations) dst.Decorations {
+	header, doc = trimNewlines(header), trimNewlines(doc)
+	var out dst.Decorations
+	endLine := func() {
+		// line comments are followed by an implicit line break
+		if !strings.HasPrefix(out[len(out)-1], "//") {
+			out = append(out, "\n")
+		}
+	}
+	if len(header) > 0 {
+		out = append(out, header...)
+		endLine()
+		out = append(out, "\n")
+	}
+	if len(doc) > 0 {
+		out = append(out, doc...)
+		endLine()
+	}
+	return out
+}
+
+func trimNewlines(decs dst.Decorations) dst.Decorations {
+	start, end := 0, len(decs)
+	for start < end && decs[start] == "\n" {
+		start++
+	}
+	for end > start && decs[end-1] == "\n" {
+		end--
+	}
+	if start == end {
+		return nil
+	}
+	return append(dst.Decorations{}, decs[start:end]...)
+}
+
+// SetBuildConstraint sets the build constraint of a file, replacing any existing "//go:build" or
+// "// +build" lines. The constraint is rendered as the first line of the file, followed by an empty
+// line. If compat is true, the equivalent legacy "// +build" lines are also rendered. If expr is
+// empty, any existing build constraint is removed.
+func SetBuildConstraint(f *dst.File, expr string, compat bool) error {
+	var lines []string
+	if expr != "" {
+		x, err := constraint.Parse("//go:build " + expr)
+		if err != nil {
+			return err
+		}
+		lines = append(lines, "//go:build "+x.String())
+		if compat {
+			plus, err := constraint.PlusBuildLines(x)
+			if err != nil {
+				return err
+			}
+			lines = append(lines, plus...)
+		}
+	}
+	header, doc := splitFileStart(f.Decs.Start)
+	header, doc = removeBuildConstraints(header), removeBuildConstraints(doc)
+	if len(lines) > 0 {
+		if len(header) > 0 {
+			lines = append(lines, "\n")
+		}
+		header = append(lines, header...)
+	}
+	f.Decs.Start.Replace(joinFileStart(header, doc)...)
+	return nil
+}
+
+// removeBuildConstraints removes any build constraint lines, along with the newline that follows.
+func removeBuildConstraints(decs dst.Decorations) dst.Decorations {
+	var out dst.Decorations
+	for i := 0; i < len(decs); i++ {
+		if constraint.IsGoBuild(decs[i]) || constraint.IsPlusBuild(decs[i]) {
+			if i+1 < len(decs) && decs[i+1] == "\n" {
+				i++
+			}
+			continue
+		}
+		out = append(out, decs[i])
+	}
+	return trimNewlines(out)
+}
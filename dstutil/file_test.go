@@ -0,0 +1,195 @@
+package dstutil_test
+
+import (
+	"bytes"
+	"go/ast"
+	"testing"
+
+	"github.com/dave/dst"
+	"github.com/dave/dst/decorator"
+	"github.com/dave/dst/dstutil"
+)
+
+func TestPackageDoc(t *testing.T) {
+	src := `// Copyright 2026 The Authors.
+
+//go:build linux
+
+// Package p does things.
+// It does them well.
+package p
+`
+	f, err := decorator.Parse(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	compareDecs(t, dst.Decorations{"// Package p does things.", "// It does them well."}, dstutil.PackageDoc(f))
+	compareDecs(t, dst.Decorations{"// Copyright 2026 The Authors.", "\n", "//go:build linux"}, dstutil.FileHeader(f))
+
+	restore := func(f *dst.File) (string, *ast.File) {
+		t.Helper()
+		r := decorator.NewRestorer()
+		af, err := r.RestoreFile(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		buf := &bytes.Buffer{}
+		if err := decorator.Fprint(buf, f); err != nil {
+			t.Fatal(err)
+		}
+		return buf.String(), af
+	}
+
+	out, af := restore(f)
+	if out != src {
+		t.Errorf("\nexpect: %q\nfound : %q", src, out)
+	}
+	if af.Doc == nil || af.Doc.Text() != "Package p does things.\nIt does them well.\n" {
+		t.Errorf("unexpected package doc %#v", af.Doc)
+	}
+
+	dstutil.SetFileHeader(f, "// Copyright 2026 The Authors.", "\n", "//go:build windows", "\n", "/* generated */")
+	dstutil.SetPackageDoc(f, "// Package p does other things.")
+
+	expect := `// Copyright 2026 The Authors.
+
+//go:build windows
+
+/* generated */
+
+// Package p does other things.
+package p
+`
+	out, af = restore(f)
+	if out != expect {
+		t.Errorf("\nexpect: %q\nfound : %q", expect, out)
+	}
+	if af.Doc == nil || af.Doc.Text() != "Package p does other things.\n" {
+		t.Errorf("unexpected package doc %#v", af.Doc)
+	}
+}
+
+func TestPackageDocDetached(t *testing.T) {
+	f, err := decorator.Parse("// Copyright 2026 The Authors.\n\npackage p\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	compareDecs(t, nil, dstutil.PackageDoc(f))
+	compareDecs(t, dst.Decorations{"// Copyright 2026 The Authors."}, dstutil.FileHeader(f))
+
+	dstutil.SetPackageDoc(f, "// Package p does things.")
+
+	buf := &bytes.Buffer{}
+	if err := decorator.Fprint(buf, f); err != nil {
+		t.Fatal(err)
+	}
+	expect := "// Copyright 2026 The Authors.\n\n// Package p does things.\npackage p\n"
+	if buf.String() != expect {
+		t.Errorf("\nexpect: %q\nfound : %q", expect, buf.String())
+	}
+}
+
+func compareDecs(t *testing.T, expect, found dst.Decorations) {
+	t.Helper()
+	if len(expect) != len(found) {
+		t.Fatalf("\nexpect: %q\nfound : %q", expect, found)
+	}
+	for i := range expect {
+		if expect[i] != found[i] {
+			t.Fatalf("\nexpect: %q\nfound : %q", expect, found)
+		}
+	}
+}
+
+func TestSetBuildConstraint(t *testing.T) {
+	tests := []struct {
+		name, code, expr string
+		compat           bool
+		expect           string
+	}{
+		{
+			name:   "new",
+			code:   "// Package p does things.\npackage p\n",
+			expr:   "windows",
+			expect: "//go:build windows\n\n// Package p does things.\npackage p\n",
+		},
+		{
+			name:   "no-doc",
+			code:   "package p\n",
+			expr:   "linux && amd64",
+			compat: true,
+			expect: "//go:build linux && amd64\n// +build linux,amd64\n\npackage p\n",
+		},
+		{
+			name:   "replace",
+			code:   "// Copyright 2026 The Authors.\n\n//go:build linux\n// +build linux\n\n// Package p does things.\npackage p\n",
+			expr:   "windows || darwin",
+			compat: true,
+			expect: "//go:build windows || darwin\n// +build windows darwin\n\n// Copyright 2026 The Authors.\n\n// Package p does things.\npackage p\n",
+		},
+		{
+			name:   "remove",
+			code:   "//go:build linux\n\n// Copyright 2026 The Authors.\n\npackage p\n",
+			expr:   "",
+			expect: "// Copyright 2026 The Authors.\n\npackage p\n",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			f, err := decorator.Parse(test.code)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := dstutil.SetBuildConstraint(f, test.expr, test.compat); err != nil {
+				t.Fatal(err)
+			}
+			buf := &bytes.Buffer{}
+			if err := decorator.Fprint(buf, f); err != nil {
+				t.Fatal(err)
+			}
+			if buf.String() != test.expect {
+				t.Errorf("\nexpect: %q\nfound : %q", test.expect, buf.String())
+			}
+		})
+	}
+
+	f, _ := decorator.Parse("package p\n")
+	if err := dstutil.SetBuildConstraint(f, "linux &&", false); err == nil {
+		t.Error("expected error, found none")
+	}
+}
+
+func TestSetPackageComment(t *testing.T) {
+	src := "// a\npackage main // build: special\n\nfunc main() {}\n"
+	f, err := decorator.Parse(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fprint := func() string {
+		t.Helper()
+		buf := &bytes.Buffer{}
+		if err := decorator.Fprint(buf, f); err != nil {
+			t.Fatal(err)
+		}
+		return buf.String()
+	}
+	if found := fprint(); found != src {
+		t.Errorf("\nexpect: %q\nfound : %q", src, found)
+	}
+
+	dstutil.SetPackageComment(f, "// b")
+	if expect, found := "// a\npackage main // b\n\nfunc main() {}\n", fprint(); found != expect {
+		t.Errorf("\nexpect: %q\nfound : %q", expect, found)
+	}
+
+	dstutil.SetPackageComment(f, "/* c */")
+	if expect, found := "// a\npackage main /* c */\n\nfunc main() {}\n", fprint(); found != expect {
+		t.Errorf("\nexpect: %q\nfound : %q", expect, found)
+	}
+
+	dstutil.SetPackageComment(f, "")
+	if expect, found := "// a\npackage main\n\nfunc main() {}\n", fprint(); found != expect {
+		t.Errorf("\nexpect: %q\nfound : %q", expect, found)
+	}
+}
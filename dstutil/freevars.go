@@ -0,0 +1,288 @@
+package dstutil
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+
+	"github.com/dave/dst"
+	"github.com/dave/dst/decorator/resolver"
+)
+
+// FreeVars returns the names of the variables that a function literal captures from its enclosing
+// scopes: the identifiers referenced inside fn that are not declared inside it, in order of first
+// use. The scopes of the declarations inside fn are analyzed, so this works without object
+// resolution. Where an identifier has an Obj, only variables are returned (references to
+// constants, types and functions are not captures). Without an Obj, any identifier that is not
+// declared inside fn is returned, except predeclared identifiers (e.g. len or nil), identifiers
+// with Path set, and the package names of qualified identifiers.
+//
+// Qualified identifiers (e.g. fmt.Println) are resolved with r, which is passed a synthetic
+// *ast.File without imports, so use WithImports to resolve the identifiers from the imports of the
+// file containing fn. If r is nil, qualified identifiers are not resolved, so the package names are
+// also returned.
+func FreeVars(fn *dst.FuncLit, r resolver.DecoratorResolver) ([]string, error) {
+	v := newFreeVars(r)
+	v.walk(fn, &scope{})
+	if v.err != nil {
+		return nil, v.err
+	}
+	return v.names, nil
+}
+
+func newFreeVars(r resolver.DecoratorResolver) *freeVars {
+	return &freeVars{r: r, af: &ast.File{Name: ast.NewIdent("p")}, seen: map[string]bool{}}
+}
+
+type freeVars struct {
+	r     resolver.DecoratorResolver
+	af    *ast.File
+	names []string
+	seen  map[string]bool
+	err   error
+
+	// paths are the paths of the qualified identifiers, recorded if not nil
+	paths map[string]bool
+}
+
+type scope struct {
+	outer *scope
+	names map[string]bool
+}
+
+func (s *scope) inner() *scope {
+	return &scope{outer: s}
+}
+
+func (s *scope) declare(id *dst.Ident) {
+	if s.names == nil {
+		s.names = map[string]bool{}
+	}
+	s.names[id.Name] = true
+}
+
+func (s *scope) declared(name string) bool {
+	for ; s != nil; s = s.outer {
+		if s.names[name] {
+			return true
+		}
+	}
+	return false
+}
+
+// ref records a reference to an identifier.
+func (v *freeVars) ref(id *dst.Ident, s *scope) {
+	if id.Path != "" || id.Name == "_" || s.declared(id.Name) || v.seen[id.Name] {
+		return
+	}
+	if id.Obj != nil {
+		if id.Obj.Kind != dst.Var {
+			return
+		}
+	} else if types.Universe.Lookup(id.Name) != nil {
+		return
+	}
+	v.seen[id.Name] = true
+	v.names = append(v.names, id.Name)
+}
+
+// isPackage reports whether x is the package name of a qualified identifier.
+func (v *freeVars) isPackage(x *dst.Ident, sel *dst.Ident, s *scope) bool {
+	if v.r == nil || x.Obj != nil || x.Path != "" || s.declared(x.Name) {
+		return false
+	}
+	id := ast.NewIdent(sel.Name)
+	path, err := v.r.ResolveIdent(v.af, &ast.SelectorExpr{X: ast.NewIdent(x.Name), Sel: id}, "Sel", id)
+	if err != nil && v.err == nil {
+		v.err = err
+	}
+	if path != "" && v.paths != nil {
+		v.paths[path] = true
+	}
+	return path != ""
+}
+
+func (v *freeVars) walk(n dst.Node, s *scope) {
+	if n == nil || v.err != nil {
+		return
+	}
+	switch n := n.(type) {
+	case *dst.Ident:
+		v.ref(n, s)
+	case *dst.SelectorExpr:
+		if x, ok := n.X.(*dst.Ident); ok && v.isPackage(x, n.Sel, s) {
+			return
+		}
+		v.walk(n.X, s)
+	case *dst.CompositeLit:
+		v.walk(n.Type, s)
+		_, isMap := Unparen(n.Type).(*dst.MapType)
+		for _, elt := range n.Elts {
+			if kv, ok := elt.(*dst.KeyValueExpr); ok && !isMap {
+				if _, ok := kv.Key.(*dst.Ident); ok {
+					// the key is a struct field name
+					v.walk(kv.Value, s)
+					continue
+				}
+			}
+			v.walk(elt, s)
+		}
+	case *dst.FuncLit:
+		v.walk(n.Type, s)
+		s = s.inner()
+		v.declareFields(n.Type.Params, s)
+		v.declareFields(n.Type.Results, s)
+		v.walkList(n.Body.List, s)
+	case *dst.FuncDecl:
+		v.walkFields(n.Recv, s)
+		v.walk(n.Type, s)
+		if n.Body == nil {
+			return
+		}
+		s = s.inner()
+		v.declareFields(n.Recv, s)
+		v.declareFields(n.Type.Params, s)
+		v.declareFields(n.Type.Results, s)
+		v.walkList(n.Body.List, s)
+	case *dst.FuncType:
+		v.walkFields(n.Params, s)
+		v.walkFields(n.Results, s)
+	case *dst.StructType:
+		v.walkFields(n.Fields, s)
+	case *dst.InterfaceType:
+		v.walkFields(n.Methods, s)
+	case *dst.BlockStmt:
+		v.walkList(n.List, s.inner())
+	case *dst.AssignStmt:
+		for _, e := range n.Rhs {
+			v.walk(e, s)
+		}
+		for _, e := range n.Lhs {
+			if id, ok := e.(*dst.Ident); ok && n.Tok == token.DEFINE {
+				s.declare(id)
+				continue
+			}
+			v.walk(e, s)
+		}
+	case *dst.GenDecl:
+		for _, spec := range n.Specs {
+			switch spec := spec.(type) {
+			case *dst.ValueSpec:
+				v.walk(spec.Type, s)
+				for _, e := range spec.Values {
+					v.walk(e, s)
+				}
+				for _, id := range spec.Names {
+					s.declare(id)
+				}
+			case *dst.TypeSpec:
+				s.declare(spec.Name)
+				v.walk(spec.Type, s)
+			}
+		}
+	case *dst.RangeStmt:
+		v.walk(n.X, s)
+		s = s.inner()
+		for _, e := range []dst.Expr{n.Key, n.Value} {
+			if id, ok := e.(*dst.Ident); ok && n.Tok == token.DEFINE {
+				s.declare(id)
+				continue
+			}
+			v.walk(e, s)
+		}
+		v.walk(n.Body, s)
+	case *dst.ForStmt:
+		s = s.inner()
+		v.walk(n.Init, s)
+		v.walk(n.Cond, s)
+		v.walk(n.Post, s)
+		v.walk(n.Body, s)
+	case *dst.IfStmt:
+		s = s.inner()
+		v.walk(n.Init, s)
+		v.walk(n.Cond, s)
+		v.walk(n.Body, s)
+		v.walk(n.Else, s)
+	case *dst.SwitchStmt:
+		s = s.inner()
+		v.walk(n.Init, s)
+		v.walk(n.Tag, s)
+		v.walk(n.Body, s)
+	case *dst.TypeSwitchStmt:
+		s = s.inner()
+		v.walk(n.Init, s)
+		// the variable declared by the guard is declared in each clause
+		var guard *dst.Ident
+		switch a := n.Assign.(type) {
+		case *dst.AssignStmt:
+			for _, e := range a.Rhs {
+				v.walk(e, s)
+			}
+			if len(a.Lhs) == 1 {
+				guard, _ = a.Lhs[0].(*dst.Ident)
+			}
+		default:
+			v.walk(a, s)
+		}
+		for _, stmt := range n.Body.List {
+			clause := stmt.(*dst.CaseClause)
+			cs := s.inner()
+			for _, e := range clause.List {
+				v.walk(e, cs)
+			}
+			if guard != nil {
+				cs.declare(guard)
+			}
+			v.walkList(clause.Body, cs)
+		}
+	case *dst.CaseClause:
+		s = s.inner()
+		for _, e := range n.List {
+			v.walk(e, s)
+		}
+		v.walkList(n.Body, s)
+	case *dst.CommClause:
+		s = s.inner()
+		v.walk(n.Comm, s)
+		v.walkList(n.Body, s)
+	case *dst.LabeledStmt:
+		v.walk(n.Stmt, s)
+	case *dst.BranchStmt:
+		// labels are not variables
+	default:
+		dst.Inspect(n, func(c dst.Node) bool {
+			if c == n {
+				return true
+			}
+			v.walk(c, s)
+			return false
+		})
+	}
+}
+
+func (v *freeVars) walkList(list []dst.Stmt, s *scope) {
+	for _, stmt := range list {
+		v.walk(stmt, s)
+	}
+}
+
+// walkFields walks the types of the fields, but not the names.
+func (v *freeVars) walkFields(fields *dst.FieldList, s *scope) {
+	if fields == nil {
+		return
+	}
+	for _, field := range fields.List {
+		v.walk(field.Type, s)
+	}
+}
+
+func (v *freeVars) declareFields(fields *dst.FieldList, s *scope) {
+	if fields == nil {
+		return
+	}
+	for _, field := range fields.List {
+		for _, id := range field.Names {
+			s.declare(id)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package dstutil_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/dave/dst"
+	"github.com/dave/dst/decorator"
+	"github.com/dave/dst/decorator/resolver/goast"
+	"github.com/dave/dst/dstutil"
+)
+
+func TestFreeVars(t *testing.T) {
+	src := `package a
+
+import "fmt"
+
+var total int
+
+type T struct{ A int }
+
+func f(items []int) {
+	for i, v := range items {
+		go func(n int) {
+			x := T{A: v}
+			for _, s := range []string{"a"} {
+				switch y := interface{}(s).(type) {
+				case string:
+					fmt.Println(y, n, x, total, len(items))
+				}
+			}
+			i++
+		}(i)
+	}
+}
+`
+	tests := []struct {
+		name     string
+		skip     bool
+		resolver bool
+		expect   []string
+	}{
+		{name: "objects", resolver: true, expect: []string{"v", "total", "items", "i"}},
+		{name: "no-resolver", expect: []string{"v", "fmt", "total", "items", "i"}},
+		{name: "no-objects", skip: true, resolver: true, expect: []string{"T", "v", "total", "items", "i"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			d := decorator.NewDecorator(nil)
+			d.SkipObjectResolution = test.skip
+			f, err := d.Parse(src)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var fn *dst.FuncLit
+			dst.Inspect(f, func(n dst.Node) bool {
+				if lit, ok := n.(*dst.FuncLit); ok && fn == nil {
+					fn = lit
+				}
+				return true
+			})
+			var found []string
+			if test.resolver {
+				found, err = dstutil.FreeVars(fn, dstutil.WithImports(f, goast.New()))
+			} else {
+				found, err = dstutil.FreeVars(fn, nil)
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(found, test.expect) {
+				t.Errorf("expected %q, found %q", test.expect, found)
+			}
+		})
+	}
+}
@@ -0,0 +1,128 @@
+package dstutil
+
+import (
+	"go/ast"
+	"go/token"
+
+	"github.com/dave/dst"
+	"github.com/dave/dst/decorator/resolver"
+)
+
+// Graph is the reference graph of the top-level declarations of a file, computed by DeclGraph.
+type Graph struct {
+	// Decls lists the declarations of the file, excluding import declarations, in source order.
+	Decls []dst.Decl
+	// Edges maps each declaration to the declarations it refers to, in source order. References
+	// of a declaration to itself (e.g. a recursive function) are not included.
+	Edges map[dst.Decl][]dst.Decl
+}
+
+// DeclGraph returns the graph of references between the top-level declarations of f, e.g. for
+// reordering the declarations without breaking the initialization order of package variables.
+// Methods are not in the package scope, so they are never referred to by name, but a method refers
+// to its receiver type.
+//
+// Identifiers with an Obj refer to the declaration of the object, so local declarations that
+// shadow a top-level name are not matched. Identifiers without an Obj (e.g. in a file parsed
+// without object resolution) are matched by name, which is conservative: it may add edges, but
+// doesn't miss any. If r is not nil, these identifiers are also resolved with r, which is passed the
+// import specs of the file in a synthetic *ast.File, and identifiers from a dot-imported package
+// are not matched. Declared names (including fields and parameters), labels, selectors (e.g. the
+// Sel of "a.B") and identifiers with Path set are never matched.
+func DeclGraph(f *dst.File, r resolver.DecoratorResolver) (*Graph, error) {
+	g := &Graph{Edges: map[dst.Decl][]dst.Decl{}}
+
+	// names maps the top-level names to their declarations, and objects maps the nodes that
+	// objects are declared by to the declarations.
+	names := map[string]dst.Decl{}
+	objects := map[interface{}]dst.Decl{}
+	for _, decl := range f.Decls {
+		switch decl := decl.(type) {
+		case *dst.FuncDecl:
+			if decl.Recv == nil && decl.Name.Name != "init" && decl.Name.Name != "_" {
+				names[decl.Name.Name] = decl
+			}
+			objects[decl] = decl
+		case *dst.GenDecl:
+			if decl.Tok == token.IMPORT {
+				continue
+			}
+			for _, spec := range decl.Specs {
+				objects[spec] = decl
+				switch spec := spec.(type) {
+				case *dst.ValueSpec:
+					for _, name := range spec.Names {
+						if name.Name != "_" {
+							names[name.Name] = decl
+						}
+					}
+				case *dst.TypeSpec:
+					names[spec.Name.Name] = decl
+				}
+			}
+		}
+		g.Decls = append(g.Decls, decl)
+	}
+
+	var af *ast.File
+	if r != nil {
+		af = importsFile(f)
+	}
+
+	var err error
+	for _, decl := range g.Decls {
+		found := map[dst.Decl]bool{}
+		Apply(decl, func(c *Cursor) bool {
+			id, ok := c.Node().(*dst.Ident)
+			if !ok || id.Path != "" || err != nil {
+				return err == nil
+			}
+			if !isReference(c) {
+				return true
+			}
+			var target dst.Decl
+			if id.Obj != nil {
+				target = objects[id.Obj.Decl]
+			} else {
+				target = names[id.Name]
+				if target != nil && r != nil {
+					aid := ast.NewIdent(id.Name)
+					var path string
+					path, err = r.ResolveIdent(af, &ast.ParenExpr{X: aid}, "X", aid)
+					if path != "" {
+						// an identifier from a dot-imported package
+						target = nil
+					}
+				}
+			}
+			if target != nil && target != decl {
+				found[target] = true
+			}
+			return true
+		}, nil)
+		if err != nil {
+			return nil, err
+		}
+		for _, d := range g.Decls {
+			if found[d] {
+				g.Edges[decl] = append(g.Edges[decl], d)
+			}
+		}
+	}
+	return g, nil
+}
+
+// isReference returns false if the identifier at the cursor is a declared name (including fields,
+// methods and parameters), a label, or the Sel of a selector expression, so can't refer to a
+// package-level object.
+func isReference(c *Cursor) bool {
+	switch c.Parent().(type) {
+	case *dst.SelectorExpr:
+		return c.Name() != "Sel"
+	case *dst.ValueSpec, *dst.TypeSpec, *dst.FuncDecl, *dst.Field:
+		return c.Name() != "Names" && c.Name() != "Name"
+	case *dst.LabeledStmt, *dst.BranchStmt:
+		return false
+	}
+	return true
+}
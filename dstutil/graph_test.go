@@ -0,0 +1,141 @@
+package dstutil_test
+
+import (
+	"fmt"
+	"go/ast"
+	"reflect"
+	"testing"
+
+	"github.com/dave/dst"
+	"github.com/dave/dst/decorator"
+	"github.com/dave/dst/dstutil"
+)
+
+func TestDeclGraph(t *testing.T) {
+	code := `package a
+
+import . "strings"
+
+var A = B * 2
+
+const B = 1
+
+type T struct {
+	B int
+}
+
+func (t T) M() int { return t.B }
+
+func F(x T) T {
+	B := x.M()
+	_ = T{B: B}
+	return G(x)
+}
+
+func G(x T) T {
+	return G(x)
+}
+
+var C = ToUpper("c") + D
+
+func init() {
+	F(T{})
+}
+`
+	// name returns the name of a declaration, for the expected edges
+	name := func(decl dst.Decl) string {
+		switch decl := decl.(type) {
+		case *dst.FuncDecl:
+			if decl.Recv != nil {
+				return "T." + decl.Name.Name
+			}
+			return decl.Name.Name
+		case *dst.GenDecl:
+			switch spec := decl.Specs[0].(type) {
+			case *dst.ValueSpec:
+				return spec.Names[0].Name
+			case *dst.TypeSpec:
+				return spec.Name.Name
+			}
+		}
+		return ""
+	}
+	edges := func(g *dstutil.Graph) map[string][]string {
+		out := map[string][]string{}
+		for _, decl := range g.Decls {
+			for _, d := range g.Edges[decl] {
+				out[name(decl)] = append(out[name(decl)], name(d))
+			}
+		}
+		return out
+	}
+
+	for _, skipObjects := range []bool{false, true} {
+		t.Run(fmt.Sprint("skip-objects-", skipObjects), func(t *testing.T) {
+			d := decorator.NewDecorator(nil)
+			d.SkipObjectResolution = skipObjects
+			f, err := d.Parse(code)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			g, err := dstutil.DeclGraph(f, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(g.Decls) != 8 {
+				t.Fatalf("expected 8 declarations, found %d", len(g.Decls))
+			}
+			expect := map[string][]string{
+				"A":    {"B"},
+				"T.M":  {"T"},
+				"F":    {"T", "G"},
+				"G":    {"T"},
+				"init": {"T", "F"},
+			}
+			if skipObjects {
+				// the local variable B and the field B in the composite literal are matched by name
+				expect["F"] = []string{"B", "T", "G"}
+			}
+			if found := edges(g); !reflect.DeepEqual(expect, found) {
+				t.Errorf("\nexpect: %v\nfound : %v", expect, found)
+			}
+		})
+	}
+}
+
+// dotResolver resolves the identifiers in names as if they were from dot-imported packages.
+type dotResolver map[string]string
+
+func (r dotResolver) ResolveIdent(file *ast.File, parent ast.Node, parentField string, id *ast.Ident) (string, error) {
+	if _, ok := parent.(*ast.SelectorExpr); ok {
+		return "", nil
+	}
+	return r[id.Name], nil
+}
+
+func TestDeclGraphResolver(t *testing.T) {
+	d := decorator.NewDecorator(nil)
+	d.SkipObjectResolution = true
+	f, err := d.Parse(`package a
+
+import . "b"
+
+var A = B + C
+
+var B = 1
+
+var C = 2
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// B is declared in the file, but the resolver reports it is from the dot-imported package
+	g, err := dstutil.DeclGraph(f, dotResolver{"B": "b"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expect, found := []dst.Decl{f.Decls[3]}, g.Edges[f.Decls[1]]; !reflect.DeepEqual(expect, found) {
+		t.Errorf("\nexpect: %v\nfound : %v", expect, found)
+	}
+}
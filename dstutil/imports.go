@@ -0,0 +1,106 @@
+package dstutil
+
+import (
+	"go/ast"
+	"go/token"
+	"strconv"
+
+	"github.com/dave/dst"
+	"github.com/dave/dst/decorator/resolver"
+)
+
+// CoalesceImports merges all the import declarations at the top of the file into the first import
+// declaration. The specs of each merged declaration are kept together as a group, separated from
+// the previous group by an empty line. Any comments attached to a merged declaration are moved to
+// the first and last specs of its group. The cgo "C" import is never merged, because it must stay
+// in its own declaration directly below its preamble comment.
+func CoalesceImports(f *dst.File) {
+	var target *dst.GenDecl
+	decls := make([]dst.Decl, 0, len(f.Decls))
+	for i, decl := range f.Decls {
+		gd, ok := decl.(*dst.GenDecl)
+		if !ok || gd.Tok != token.IMPORT {
+			// import declarations must come before all other declarations
+			decls = append(decls, f.Decls[i:]...)
+			break
+		}
+		if isCgoImport(gd) {
+			decls = append(decls, gd)
+			continue
+		}
+		if target == nil {
+			target = gd
+			decls = append(decls, gd)
+			continue
+		}
+		if len(gd.Specs) == 0 {
+			continue
+		}
+		first, last := gd.Specs[0].Decorations(), gd.Specs[len(gd.Specs)-1].Decorations()
+		first.Start.Prepend(gd.Decs.Lparen...)
+		first.Start.Prepend(gd.Decs.Tok...)
+		first.Start.Prepend(gd.Decs.Start...)
+		last.End.Append(gd.Decs.End...)
+		if len(target.Specs) > 0 {
+			first.Before = dst.EmptyLine
+		}
+		target.Specs = append(target.Specs, gd.Specs...)
+	}
+	f.Decls = decls
+
+	if target == nil {
+		return
+	}
+
+	target.Lparen = len(target.Specs) > 1
+	target.Rparen = target.Lparen
+	if target.Lparen {
+		for _, spec := range target.Specs {
+			decs := spec.Decorations()
+			if decs.Before == dst.None {
+				decs.Before = dst.NewLine
+			}
+			decs.After = dst.NewLine
+		}
+	}
+
+	// update the File Imports list to reflect the new order of the specs
+	var imports []*dst.ImportSpec
+	for _, decl := range f.Decls {
+		gd, ok := decl.(*dst.GenDecl)
+		if !ok || gd.Tok != token.IMPORT {
+			break
+		}
+		for _, spec := range gd.Specs {
+			imports = append(imports, spec.(*dst.ImportSpec))
+		}
+	}
+	f.Imports = imports
+}
+
+func isCgoImport(gd *dst.GenDecl) bool {
+	for _, spec := range gd.Specs {
+		if path, err := strconv.Unquote(spec.(*dst.ImportSpec).Path.Value); err == nil && path == "C" {
+			return true
+		}
+	}
+	return false
+}
+
+// WithImports returns a resolver that resolves identifiers with r as if they were in f: r is passed
+// the import specs of f in a synthetic *ast.File, instead of the file that the returned resolver is
+// passed. It is used to resolve the qualified identifiers in nodes that are passed without their
+// file (e.g. to FreeVars or ExtractFunc), so r must resolve identifiers from the imports (e.g.
+// goast.DecoratorResolver).
+func WithImports(f *dst.File, r resolver.DecoratorResolver) resolver.DecoratorResolver {
+	return &importsResolver{file: importsFile(f), r: r}
+}
+
+type importsResolver struct {
+	file *ast.File
+	r    resolver.DecoratorResolver
+}
+
+func (r *importsResolver) ResolveIdent(file *ast.File, parent ast.Node, parentField string, id *ast.Ident) (string, error) {
+	return r.r.ResolveIdent(r.file, parent, parentField, id)
+}
@@ -0,0 +1,106 @@
+package dstutil_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/dave/dst/decorator"
+	"github.com/dave/dst/dstutil"
+)
+
+func TestCoalesceImports(t *testing.T) {
+	tests := []struct {
+		name, code, expect string
+	}{
+		{
+			name: "blocks",
+			code: `package a
+
+import (
+	"fmt"
+	"os"
+)
+
+// second
+import (
+	. "github.com/a/b"
+	_ "github.com/a/c" // c
+)
+
+import d "github.com/a/d"
+
+func main() {}
+`,
+			expect: `package a
+
+import (
+	"fmt"
+	"os"
+
+	// second
+	. "github.com/a/b"
+	_ "github.com/a/c" // c
+
+	d "github.com/a/d"
+)
+
+func main() {}
+`,
+		},
+		{
+			name: "single",
+			code: `package a
+
+import "fmt"
+import "os"
+`,
+			expect: `package a
+
+import (
+	"fmt"
+
+	"os"
+)
+`,
+		},
+		{
+			name: "cgo",
+			code: `package a
+
+// #include <stdio.h>
+import "C"
+
+import "fmt"
+
+import "os"
+`,
+			expect: `package a
+
+// #include <stdio.h>
+import "C"
+
+import (
+	"fmt"
+
+	"os"
+)
+`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			f, err := decorator.Parse(test.code)
+			if err != nil {
+				t.Fatal(err)
+			}
+			dstutil.CoalesceImports(f)
+			buf := &bytes.Buffer{}
+			if err := decorator.Fprint(buf, f); err != nil {
+				t.Fatal(err)
+			}
+			if buf.String() != test.expect {
+				t.Errorf("\nexpect: %q\nfound : %q", test.expect, buf.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,341 @@
+package dstutil
+
+import (
+	"errors"
+	"fmt"
+	"go/token"
+
+	"github.com/dave/dst"
+)
+
+// InlineCall returns the expression that replaces call with the body of fn, which must be the
+// declaration of the called function or method. Only functions with a body that is a single return
+// statement with a single result are inlined, and an error is returned for any other function
+// (e.g. variadic functions, functions with named results, or return expressions containing a
+// function literal).
+//
+// References to the parameters in the return expression are replaced with the arguments. Arguments
+// that are identifiers or selectors on identifiers are substituted directly, unless the identifier
+// has the name of another identifier in the return expression (e.g. y in g(y) when g returns
+// x + y), which would change what it refers to, or the return expression takes the address of the
+// parameter. A substituted argument is converted to the type of
+// the parameter (e.g. float64(n)), unless it is a variable declared with the same type, so untyped
+// constants and values assigned to interface parameters keep the type of the parameter. Any other
+// argument is assigned to a new variable in the returned preamble statements, so it is evaluated
+// exactly once and keeps the type of the parameter. The preamble must be inserted before the
+// statement containing call. The names of the new variables are made unique among the identifiers
+// of fn and call, so don't capture references in the arguments. A return expression containing
+// only literals is converted to the result type, and a binary expression is parenthesized.
+//
+// The decorations of call are moved to the returned expression, and the decorations in the return
+// expression and the arguments are kept.
+func InlineCall(call *dst.CallExpr, fn *dst.FuncDecl) (dst.Expr, []dst.Stmt, error) {
+	if fn.Body == nil || len(fn.Body.List) != 1 {
+		return nil, nil, errors.New("function body must be a single return statement")
+	}
+	ret, ok := fn.Body.List[0].(*dst.ReturnStmt)
+	if !ok || len(ret.Results) != 1 {
+		return nil, nil, errors.New("function body must be a single return statement with one result")
+	}
+	results := fn.Type.Results
+	if results == nil || len(results.List) != 1 || len(results.List[0].Names) > 1 {
+		return nil, nil, errors.New("function must have a single result")
+	}
+	if len(results.List[0].Names) > 0 {
+		return nil, nil, errors.New("function has named results")
+	}
+	if call.Ellipsis {
+		return nil, nil, errors.New("call has a variadic argument")
+	}
+	var inlinable = true
+	dst.Inspect(ret.Results[0], func(n dst.Node) bool {
+		if _, ok := n.(*dst.FuncLit); ok {
+			inlinable = false
+		}
+		return inlinable
+	})
+	if !inlinable {
+		return nil, nil, errors.New("return expression contains a function literal")
+	}
+
+	// params lists the parameters that are passed an argument, with the receiver first
+	var params []*dst.Ident
+	var types []dst.Expr
+	var args []dst.Expr
+	if fn.Recv != nil && len(fn.Recv.List) > 0 {
+		sel, ok := call.Fun.(*dst.SelectorExpr)
+		if !ok {
+			return nil, nil, errors.New("method call must be a selector expression")
+		}
+		name := dst.NewIdent("_")
+		if recv := fn.Recv.List[0]; len(recv.Names) > 0 {
+			name = recv.Names[0]
+		}
+		if name.Name != "_" || !isSimpleExpr(sel.X) {
+			params = append(params, name)
+			types = append(types, nil)
+			args = append(args, sel.X)
+		}
+	}
+	var count int
+	for _, field := range fn.Type.Params.List {
+		if _, ok := field.Type.(*dst.Ellipsis); ok {
+			return nil, nil, errors.New("function is variadic")
+		}
+		names := field.Names
+		if len(names) == 0 {
+			names = []*dst.Ident{nil}
+		}
+		for _, name := range names {
+			if count >= len(call.Args) {
+				return nil, nil, fmt.Errorf("not enough arguments in call to %s", fn.Name.Name)
+			}
+			arg := call.Args[count]
+			count++
+			if name == nil || name.Name == "_" {
+				if !isSimpleExpr(arg) {
+					params = append(params, dst.NewIdent("_"))
+					types = append(types, field.Type)
+					args = append(args, arg)
+				}
+				continue
+			}
+			params = append(params, name)
+			types = append(types, field.Type)
+			args = append(args, arg)
+		}
+	}
+	if count != len(call.Args) {
+		return nil, nil, fmt.Errorf("too many arguments in call to %s", fn.Name.Name)
+	}
+
+	used := map[string]bool{}
+	for _, n := range []dst.Node{fn, call} {
+		dst.Inspect(n, func(n dst.Node) bool {
+			if id, ok := n.(*dst.Ident); ok {
+				used[id.Name] = true
+			}
+			return true
+		})
+	}
+
+	// free lists the names in the return expression, other than the parameters, that an argument
+	// substituted directly would be captured by
+	free := map[string]bool{}
+	skip := selectorIdents(ret.Results[0])
+	dst.Inspect(ret.Results[0], func(n dst.Node) bool {
+		if id, ok := n.(*dst.Ident); ok && id.Path == "" && !skip[id] {
+			free[id.Name] = true
+		}
+		return true
+	})
+	for _, param := range params {
+		delete(free, param.Name)
+	}
+
+	// replace maps the name of each parameter to the expression that replaces it
+	replace := map[string]dst.Expr{}
+	var preamble []dst.Stmt
+	for i, param := range params {
+		arg := args[i]
+		if param.Name != "_" && isSimpleExpr(arg) && !captured(arg, free) && !addressed(ret.Results[0], param.Name) {
+			if types[i] != nil && !hasType(arg, types[i]) {
+				arg = conversion(types[i], arg)
+			}
+			replace[param.Name] = arg
+			continue
+		}
+		name := "_"
+		if param.Name != "_" && references(ret.Results[0], param.Name) {
+			name = uniqueName(param.Name, used)
+			replace[param.Name] = dst.NewIdent(name)
+		}
+		var stmt dst.Stmt
+		if types[i] == nil {
+			// the receiver is declared without a type, because the argument may be a value of the
+			// receiver base type
+			tok := token.DEFINE
+			if name == "_" {
+				tok = token.ASSIGN
+			}
+			stmt = &dst.AssignStmt{
+				Lhs: []dst.Expr{dst.NewIdent(name)},
+				Tok: tok,
+				Rhs: []dst.Expr{dst.Clone(arg).(dst.Expr)},
+			}
+		} else {
+			stmt = &dst.DeclStmt{Decl: &dst.GenDecl{
+				Tok: token.VAR,
+				Specs: []dst.Spec{&dst.ValueSpec{
+					Names:  []*dst.Ident{dst.NewIdent(name)},
+					Type:   dst.Clone(types[i]).(dst.Expr),
+					Values: []dst.Expr{dst.Clone(arg).(dst.Expr)},
+				}},
+			}}
+		}
+		stmt.Decorations().Before, stmt.Decorations().After = dst.NewLine, dst.NewLine
+		preamble = append(preamble, stmt)
+	}
+
+	expr := substitute(dst.Clone(ret.Results[0]).(dst.Expr), replace)
+
+	if isLiteralExpr(expr) {
+		expr = conversion(results.List[0].Type, expr)
+	} else if _, ok := expr.(*dst.BinaryExpr); ok {
+		expr = &dst.ParenExpr{X: expr}
+	}
+	expr.Decorations().Before = call.Decs.Before
+	expr.Decorations().After = call.Decs.After
+	expr.Decorations().Start.Prepend(call.Decs.Start...)
+	expr.Decorations().End.Append(call.Decs.End...)
+	return expr, preamble, nil
+}
+
+// substitute replaces the identifiers in e that are keys of replace with a clone of the value.
+// Selectors and the field names of struct literals are not replaced.
+func substitute(e dst.Expr, replace map[string]dst.Expr) dst.Expr {
+	skip := selectorIdents(e)
+	return Apply(e, func(c *Cursor) bool {
+		id, ok := c.Node().(*dst.Ident)
+		if !ok || id.Path != "" || skip[id] {
+			return true
+		}
+		r, ok := replace[id.Name]
+		if !ok {
+			return true
+		}
+		r = dst.Clone(r).(dst.Expr)
+		r.Decorations().Start.Prepend(id.Decs.Start...)
+		r.Decorations().End.Append(id.Decs.End...)
+		c.Replace(r)
+		return false
+	}, nil).(dst.Expr)
+}
+
+// selectorIdents returns the identifiers in root that never refer to a variable or a package level
+// declaration: the selectors, and the field names of struct literals.
+func selectorIdents(root dst.Node) map[*dst.Ident]bool {
+	skip := map[*dst.Ident]bool{}
+	dst.Inspect(root, func(n dst.Node) bool {
+		switch n := n.(type) {
+		case *dst.SelectorExpr:
+			skip[n.Sel] = true
+		case *dst.CompositeLit:
+			if _, isMap := Unparen(n.Type).(*dst.MapType); isMap {
+				break
+			}
+			for _, elt := range n.Elts {
+				if kv, ok := elt.(*dst.KeyValueExpr); ok {
+					if id, ok := kv.Key.(*dst.Ident); ok {
+						skip[id] = true
+					}
+				}
+			}
+		}
+		return true
+	})
+	return skip
+}
+
+// isSimpleExpr returns true if e is an identifier or a selector on an identifier (e.g. a.b.c),
+// which can be evaluated more than once, or not at all, without side effects.
+func isSimpleExpr(e dst.Expr) bool {
+	switch e := Unparen(e).(type) {
+	case *dst.Ident:
+		return true
+	case *dst.SelectorExpr:
+		return isSimpleExpr(e.X)
+	}
+	return false
+}
+
+// captured returns true if the identifier of a simple expression (see isSimpleExpr), e.g. a in
+// a.b.c, is unqualified and has one of the free names.
+func captured(e dst.Expr, free map[string]bool) bool {
+	switch e := Unparen(e).(type) {
+	case *dst.SelectorExpr:
+		return captured(e.X, free)
+	case *dst.Ident:
+		return e.Path == "" && free[e.Name]
+	}
+	return false
+}
+
+// hasType returns true if e is an identifier of a variable that is declared with the type typ.
+func hasType(e dst.Expr, typ dst.Expr) bool {
+	id, ok := Unparen(e).(*dst.Ident)
+	if !ok || id.Obj == nil || id.Obj.Kind != dst.Var {
+		return false
+	}
+	switch decl := id.Obj.Decl.(type) {
+	case *dst.Field:
+		return structurallyEqual(decl.Type, typ)
+	case *dst.ValueSpec:
+		return decl.Type != nil && structurallyEqual(decl.Type, typ)
+	}
+	return false
+}
+
+// conversion returns the conversion of e to the type typ. Pointer, function and channel types are
+// parenthesized, so the conversion isn't parsed as a different expression (e.g. *T(e)).
+func conversion(typ, e dst.Expr) dst.Expr {
+	fun := dst.Clone(typ).(dst.Expr)
+	switch typ.(type) {
+	case *dst.StarExpr, *dst.FuncType, *dst.ChanType:
+		fun = &dst.ParenExpr{X: fun}
+	}
+	return &dst.CallExpr{Fun: fun, Args: []dst.Expr{e}}
+}
+
+// isLiteralExpr returns true if e is a constant expression of literals (e.g. 1 << 2), which is
+// untyped.
+func isLiteralExpr(e dst.Expr) bool {
+	switch e := e.(type) {
+	case *dst.BasicLit:
+		return true
+	case *dst.ParenExpr:
+		return isLiteralExpr(e.X)
+	case *dst.UnaryExpr:
+		return e.Op != token.ARROW && e.Op != token.AND && isLiteralExpr(e.X)
+	case *dst.BinaryExpr:
+		return isLiteralExpr(e.X) && isLiteralExpr(e.Y)
+	}
+	return false
+}
+
+// addressed returns true if e takes the address of the identifier with the name, which must then
+// refer to a variable.
+func addressed(e dst.Expr, name string) bool {
+	var found bool
+	dst.Inspect(e, func(n dst.Node) bool {
+		if u, ok := n.(*dst.UnaryExpr); ok && u.Op == token.AND {
+			if id, ok := Unparen(u.X).(*dst.Ident); ok && id.Path == "" && id.Name == name {
+				found = true
+			}
+		}
+		return !found
+	})
+	return found
+}
+
+// references returns true if e contains an identifier with the name.
+func references(e dst.Expr, name string) bool {
+	var found bool
+	dst.Inspect(e, func(n dst.Node) bool {
+		if id, ok := n.(*dst.Ident); ok && id.Name == name {
+			found = true
+		}
+		return !found
+	})
+	return found
+}
+
+// uniqueName returns name with the lowest numeric suffix that is not in used, and adds it to used.
+func uniqueName(name string, used map[string]bool) string {
+	for i := 1; ; i++ {
+		if n := fmt.Sprintf("%s%d", name, i); !used[n] {
+			used[n] = true
+			return n
+		}
+	}
+}
@@ -0,0 +1,209 @@
+package dstutil_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/dave/dst"
+	"github.com/dave/dst/decorator"
+	"github.com/dave/dst/dstutil"
+)
+
+func TestInlineCall(t *testing.T) {
+	code := `package a
+
+type Point struct{ x, y int }
+
+// X returns the x coordinate.
+func (p *Point) X() int { return p.x }
+
+func Double(n int) int {
+	return n * /* a */ 2
+}
+
+func Half() float64 { return 1 / 2.0 }
+
+func Two() int {
+	print()
+	return 2
+}
+
+func A(pt Point, n1 int) {
+	print(pt.X() /* b */, 3*Double(n1+1), Half())
+}
+`
+	expect := `package a
+
+type Point struct{ x, y int }
+
+// X returns the x coordinate.
+func (p *Point) X() int { return p.x }
+
+func Double(n int) int {
+	return n * /* a */ 2
+}
+
+func Half() float64 { return 1 / 2.0 }
+
+func Two() int {
+	print()
+	return 2
+}
+
+func A(pt Point, n1 int) {
+	var n2 int = n1 + 1
+	print(pt.x /* b */, 3*(n2* /* a */ 2), float64(1/2.0))
+}
+`
+	f, err := decorator.Parse(code)
+	if err != nil {
+		t.Fatal(err)
+	}
+	funcs := map[string]*dst.FuncDecl{}
+	for _, decl := range f.Decls {
+		if fd, ok := decl.(*dst.FuncDecl); ok {
+			funcs[fd.Name.Name] = fd
+		}
+	}
+	body := funcs["A"].Body
+	call := body.List[0].(*dst.ExprStmt).X.(*dst.CallExpr)
+
+	getter, preamble, err := dstutil.InlineCall(call.Args[0].(*dst.CallExpr), funcs["X"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(preamble) != 0 {
+		t.Errorf("unexpected preamble %#v", preamble)
+	}
+	call.Args[0] = getter
+
+	mul := call.Args[1].(*dst.BinaryExpr)
+	double, preamble, err := dstutil.InlineCall(mul.Y.(*dst.CallExpr), funcs["Double"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	mul.Y = double
+	body.List = append(preamble, body.List...)
+
+	half, _, err := dstutil.InlineCall(call.Args[2].(*dst.CallExpr), funcs["Half"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	call.Args[2] = half
+
+	if _, _, err := dstutil.InlineCall(&dst.CallExpr{Fun: dst.NewIdent("Two")}, funcs["Two"]); err == nil {
+		t.Error("expected error, found none")
+	}
+
+	buf := &bytes.Buffer{}
+	if err := decorator.Fprint(buf, f); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != expect {
+		t.Errorf("\nexpect: %q\nfound : %q", expect, buf.String())
+	}
+}
+
+func TestInlineCallCapture(t *testing.T) {
+	code := `package a
+
+var y = 1
+
+func g(x int) int { return x + y }
+
+func A() int {
+	return g(y)
+}
+`
+	expect := `package a
+
+var y = 1
+
+func g(x int) int { return x + y }
+
+func A() int {
+	var x1 int = y
+	return (x1 + y)
+}
+`
+	f, err := decorator.Parse(code)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, a := f.Decls[1].(*dst.FuncDecl), f.Decls[2].(*dst.FuncDecl)
+	ret := a.Body.List[0].(*dst.ReturnStmt)
+	expr, preamble, err := dstutil.InlineCall(ret.Results[0].(*dst.CallExpr), g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ret.Results[0] = expr
+	a.Body.List = append(preamble, a.Body.List...)
+
+	buf := &bytes.Buffer{}
+	if err := decorator.Fprint(buf, f); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != expect {
+		t.Errorf("\nexpect: %q\nfound : %q", expect, buf.String())
+	}
+}
+
+func TestInlineCallConversion(t *testing.T) {
+	code := `package a
+
+const n = 3
+
+func Half(x float64) float64 { return x / 2 }
+
+func IsNil(x interface{}) bool { return x == nil }
+
+func Ptr(x int) *int { return &x }
+
+func A(p *int, f float64) {
+	print(Half(n), IsNil(p), Half(f), Ptr(n))
+}
+`
+	expect := `package a
+
+const n = 3
+
+func Half(x float64) float64 { return x / 2 }
+
+func IsNil(x interface{}) bool { return x == nil }
+
+func Ptr(x int) *int { return &x }
+
+func A(p *int, f float64) {
+	var x1 int = n
+	print((float64(n) / 2), (interface{}(p) == nil), (f / 2), &x1)
+}
+`
+	f, err := decorator.Parse(code)
+	if err != nil {
+		t.Fatal(err)
+	}
+	funcs := map[string]*dst.FuncDecl{}
+	for _, decl := range f.Decls {
+		if fd, ok := decl.(*dst.FuncDecl); ok {
+			funcs[fd.Name.Name] = fd
+		}
+	}
+	body := funcs["A"].Body
+	call := body.List[0].(*dst.ExprStmt).X.(*dst.CallExpr)
+	for i, name := range []string{"Half", "IsNil", "Half", "Ptr"} {
+		expr, preamble, err := dstutil.InlineCall(call.Args[i].(*dst.CallExpr), funcs[name])
+		if err != nil {
+			t.Fatal(err)
+		}
+		call.Args[i] = expr
+		body.List = append(preamble, body.List...)
+	}
+
+	buf := &bytes.Buffer{}
+	if err := decorator.Fprint(buf, f); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != expect {
+		t.Errorf("\nexpect: %q\nfound : %q", expect, buf.String())
+	}
+}
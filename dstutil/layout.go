@@ -0,0 +1,40 @@
+package dstutil
+
+import (
+	"github.com/dave/dst"
+)
+
+// LayoutTypeLiterals renders the fields of each struct type and the methods of each interface type
+// in root on separate lines, as gofmt would lay out a hand written type, when the type has more
+// than one field or method. Types with a single field or method are left unchanged, so stay on one
+// line. The line breaks of fields that already have them (e.g. an empty line separating groups of
+// fields) are preserved. The Opening and Closing flags of the field lists are set.
+func LayoutTypeLiterals(root dst.Node) {
+	dst.Inspect(root, func(n dst.Node) bool {
+		var fields *dst.FieldList
+		switch n := n.(type) {
+		case *dst.StructType:
+			fields = n.Fields
+		case *dst.InterfaceType:
+			fields = n.Methods
+		}
+		if fields == nil {
+			return true
+		}
+		// the braces of a struct or interface type are always rendered, but go/printer only keeps
+		// a type on one line if the field list has positions for both braces
+		fields.Opening, fields.Closing = true, true
+		if len(fields.List) < 2 {
+			return true
+		}
+		for _, field := range fields.List {
+			if field.Decs.Before == dst.None {
+				field.Decs.Before = dst.NewLine
+			}
+			if field.Decs.After == dst.None {
+				field.Decs.After = dst.NewLine
+			}
+		}
+		return true
+	})
+}
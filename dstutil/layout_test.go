@@ -0,0 +1,59 @@
+package dstutil_test
+
+import (
+	"bytes"
+	"go/token"
+	"testing"
+
+	"github.com/dave/dst"
+	"github.com/dave/dst/decorator"
+	"github.com/dave/dst/dstutil"
+)
+
+func TestLayoutTypeLiterals(t *testing.T) {
+	field := func(name string, typ dst.Expr) *dst.Field {
+		return &dst.Field{Names: []*dst.Ident{dst.NewIdent(name)}, Type: typ}
+	}
+	typ := &dst.StructType{Fields: &dst.FieldList{List: []*dst.Field{
+		field("A", dst.NewIdent("int")),
+		field("B", &dst.StructType{Fields: &dst.FieldList{List: []*dst.Field{
+			field("C", dst.NewIdent("string")),
+		}}}),
+		field("D", &dst.InterfaceType{Methods: &dst.FieldList{List: []*dst.Field{
+			field("E", &dst.FuncType{Params: &dst.FieldList{}}),
+			field("F", &dst.FuncType{Params: &dst.FieldList{}, Results: &dst.FieldList{List: []*dst.Field{
+				{Type: dst.NewIdent("error")},
+			}}}),
+		}}}),
+	}}}
+	f := &dst.File{
+		Name: dst.NewIdent("a"),
+		Decls: []dst.Decl{
+			&dst.GenDecl{
+				Tok:   token.VAR,
+				Specs: []dst.Spec{&dst.ValueSpec{Names: []*dst.Ident{dst.NewIdent("v")}, Type: typ}},
+			},
+		},
+	}
+
+	dstutil.LayoutTypeLiterals(f)
+
+	buf := &bytes.Buffer{}
+	if err := decorator.Fprint(buf, f); err != nil {
+		t.Fatal(err)
+	}
+	expect := `package a
+
+var v struct {
+	A int
+	B struct{ C string }
+	D interface {
+		E()
+		F() error
+	}
+}
+`
+	if buf.String() != expect {
+		t.Errorf("\nexpect: %q\nfound : %q", expect, buf.String())
+	}
+}
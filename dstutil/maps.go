@@ -0,0 +1,27 @@
+package dstutil
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/dave/dst"
+)
+
+// SortMapLiteral sorts the elements of a map composite literal by key, using less to compare the
+// keys. The sort is stable. Each element keeps its decorations, so comments move with the element.
+// An error is returned if lit is not a map literal (the type must be a map type), or if an
+// element is not a key-value pair.
+func SortMapLiteral(lit *dst.CompositeLit, less func(a, b dst.Expr) bool) error {
+	if _, ok := Unparen(lit.Type).(*dst.MapType); !ok {
+		return errors.New("composite literal is not a map literal")
+	}
+	for _, elt := range lit.Elts {
+		if _, ok := elt.(*dst.KeyValueExpr); !ok {
+			return errors.New("map literal element is not a key-value pair")
+		}
+	}
+	sort.SliceStable(lit.Elts, func(i, j int) bool {
+		return less(lit.Elts[i].(*dst.KeyValueExpr).Key, lit.Elts[j].(*dst.KeyValueExpr).Key)
+	})
+	return nil
+}
@@ -0,0 +1,59 @@
+package dstutil_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/dave/dst"
+	"github.com/dave/dst/decorator"
+	"github.com/dave/dst/dstutil"
+)
+
+func TestSortMapLiteral(t *testing.T) {
+	src := `package a
+
+var m = map[string]int{
+	"c": 3, // c
+	// b
+	"b": 2,
+	"a": 1,
+}
+
+var s = []int{3, 2, 1}
+`
+	expect := `package a
+
+var m = map[string]int{
+	"a": 1,
+	// b
+	"b": 2,
+	"c": 3, // c
+}
+
+var s = []int{3, 2, 1}
+`
+	f, err := decorator.Parse(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lit := func(i int) *dst.CompositeLit {
+		return f.Decls[i].(*dst.GenDecl).Specs[0].(*dst.ValueSpec).Values[0].(*dst.CompositeLit)
+	}
+	less := func(a, b dst.Expr) bool {
+		return a.(*dst.BasicLit).Value < b.(*dst.BasicLit).Value
+	}
+	if err := dstutil.SortMapLiteral(lit(0), less); err != nil {
+		t.Fatal(err)
+	}
+	buf := &bytes.Buffer{}
+	if err := decorator.Fprint(buf, f); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != expect {
+		t.Errorf("\nexpect: %q\nfound : %q", expect, buf.String())
+	}
+
+	if err := dstutil.SortMapLiteral(lit(1), less); err == nil {
+		t.Error("expected error, found none")
+	}
+}
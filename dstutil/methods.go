@@ -0,0 +1,127 @@
+package dstutil
+
+import (
+	"strings"
+
+	"github.com/dave/dst"
+)
+
+// SetReceiverPointer changes the receiver of the method fn to a pointer receiver (e.g. "func (t T)"
+// becomes "func (t *T)") if pointer is true, or to a value receiver if pointer is false. The
+// receiver name is unchanged, and the decorations of the receiver type are kept (the decorations
+// after the "*" of a pointer receiver are moved to the start of the type). Nothing is changed if fn
+// is not a method or the receiver is already of the requested form.
+func SetReceiverPointer(fn *dst.FuncDecl, pointer bool) {
+	if fn.Recv == nil || len(fn.Recv.List) != 1 {
+		return
+	}
+	field := fn.Recv.List[0]
+	star, isPointer := Unparen(field.Type).(*dst.StarExpr)
+	switch {
+	case pointer && !isPointer:
+		t := field.Type
+		s := &dst.StarExpr{X: t}
+		s.Decs.NodeDecs = *t.Decorations()
+		*t.Decorations() = dst.NodeDecs{}
+		field.Type = s
+	case !pointer && isPointer:
+		t := star.X
+		decs := t.Decorations()
+		decs.Start.Prepend(star.Decs.Star...)
+		decs.Start.Prepend(star.Decs.Start...)
+		decs.End.Append(star.Decs.End...)
+		decs.Before, decs.After = star.Decs.Before, star.Decs.After
+		field.Type = t
+	}
+}
+
+// SetPragmas replaces the compiler directives of fn (e.g. "//go:noinline" or "//go:nosplit") with
+// pragmas. The directives are added to the end of the Start decorations of fn, after the doc
+// comment, with no empty line between them and the function. The "//" prefix is added to each
+// pragma that doesn't have it (e.g. "go:noinline"). If pragmas is empty the existing directives are
+// removed.
+func SetPragmas(fn *dst.FuncDecl, pragmas []string) {
+	var start dst.Decorations
+	for _, d := range fn.Decs.Start {
+		if !IsPragma(d) {
+			start = append(start, d)
+		}
+	}
+	for _, p := range pragmas {
+		if !strings.HasPrefix(p, "//") {
+			p = "//" + p
+		}
+		start = append(start, p)
+	}
+	if len(pragmas) > 0 && fn.Decs.Before == dst.None {
+		fn.Decs.Before = dst.NewLine
+	}
+	fn.Decs.Start = start
+}
+
+// funcPragmas are the compiler directives that apply to the following function. Other directives
+// (e.g. "//go:linkname" or "//go:generate") don't belong to a function.
+var funcPragmas = []string{
+	"go:cgo_unsafe_args",
+	"go:nocheckptr",
+	"go:noescape",
+	"go:noinline",
+	"go:norace",
+	"go:nosplit",
+	"go:nowritebarrier",
+	"go:nowritebarrierrec",
+	"go:registerparams",
+	"go:systemstack",
+	"go:uintptrescapes",
+	"go:uintptrkeepalive",
+	"go:wasmexport",
+	"go:wasmimport",
+	"go:yeswritebarrierrec",
+}
+
+// IsPragma returns true if d is a comment with a compiler directive for the following function
+// (e.g. "//go:noinline" or "//go:nosplit").
+func IsPragma(d string) bool {
+	if !strings.HasPrefix(d, "//go:") {
+		return false
+	}
+	name := strings.TrimPrefix(d, "//")
+	if i := strings.IndexAny(name, " \t"); i >= 0 {
+		name = name[:i]
+	}
+	for _, p := range funcPragmas {
+		if name == p {
+			return true
+		}
+	}
+	return false
+}
+
+// MethodsOf returns the methods declared in f with a receiver of the named type, or a pointer to
+// it, in the order they are declared.
+func MethodsOf(f *dst.File, typeName string) []*dst.FuncDecl {
+	var methods []*dst.FuncDecl
+	for _, decl := range f.Decls {
+		fn, ok := decl.(*dst.FuncDecl)
+		if !ok || fn.Recv == nil || len(fn.Recv.List) != 1 {
+			continue
+		}
+		if receiverTypeName(fn.Recv.List[0].Type) == typeName {
+			methods = append(methods, fn)
+		}
+	}
+	return methods
+}
+
+// receiverTypeName returns the name of the base type of a receiver type expression.
+func receiverTypeName(e dst.Expr) string {
+	switch e := Unparen(e).(type) {
+	case *dst.StarExpr:
+		return receiverTypeName(e.X)
+	case *dst.IndexExpr:
+		return receiverTypeName(e.X)
+	case *dst.Ident:
+		return e.Name
+	}
+	return ""
+}
@@ -0,0 +1,171 @@
+package dstutil_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/dave/dst"
+	"github.com/dave/dst/decorator"
+	"github.com/dave/dst/dstutil"
+)
+
+func TestSetReceiverPointer(t *testing.T) {
+	code := `package a
+
+type T struct{}
+
+func (t T) A() {}
+
+func (t * /* a */ T) B() {}
+
+func (T) C() {}
+
+func (t /* b */ T /* c */) D() {}
+
+func E() {}
+
+type U int
+
+func (u U) F() {}
+`
+	f, err := decorator.Parse(code)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	methods := dstutil.MethodsOf(f, "T")
+	for _, fn := range methods {
+		names = append(names, fn.Name.Name)
+	}
+	if len(names) != 4 || names[0] != "A" || names[1] != "B" || names[2] != "C" || names[3] != "D" {
+		t.Fatalf("unexpected methods %q", names)
+	}
+
+	fprint := func() string {
+		t.Helper()
+		buf := &bytes.Buffer{}
+		if err := decorator.Fprint(buf, f); err != nil {
+			t.Fatal(err)
+		}
+		return buf.String()
+	}
+
+	for _, fn := range methods {
+		dstutil.SetReceiverPointer(fn, true)
+	}
+	dstutil.SetReceiverPointer(f.Decls[5].(*dst.FuncDecl), true)
+	expect := `package a
+
+type T struct{}
+
+func (t *T) A() {}
+
+func (t * /* a */ T) B() {}
+
+func (*T) C() {}
+
+func (t /* b */ *T /* c */) D() {}
+
+func E() {}
+
+type U int
+
+func (u U) F() {}
+`
+	if found := fprint(); found != expect {
+		t.Errorf("\nexpect: %q\nfound : %q", expect, found)
+	}
+
+	for _, fn := range methods {
+		dstutil.SetReceiverPointer(fn, false)
+	}
+	expect = `package a
+
+type T struct{}
+
+func (t T) A() {}
+
+func (t /* a */ T) B() {}
+
+func (T) C() {}
+
+func (t /* b */ T /* c */) D() {}
+
+func E() {}
+
+type U int
+
+func (u U) F() {}
+`
+	if found := fprint(); found != expect {
+		t.Errorf("\nexpect: %q\nfound : %q", expect, found)
+	}
+}
+
+func TestSetPragmas(t *testing.T) {
+	code := `package a
+
+// A does things.
+func A() {}
+
+// the linkname directive isn't a function pragma
+//go:linkname B runtime.B
+//go:nosplit
+func B() {}
+
+func C() {}
+`
+	f, err := decorator.Parse(code)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fprint := func() string {
+		t.Helper()
+		buf := &bytes.Buffer{}
+		if err := decorator.Fprint(buf, f); err != nil {
+			t.Fatal(err)
+		}
+		return buf.String()
+	}
+	a, b, c := f.Decls[0].(*dst.FuncDecl), f.Decls[1].(*dst.FuncDecl), f.Decls[2].(*dst.FuncDecl)
+
+	dstutil.SetPragmas(a, []string{"//go:noinline"})
+	dstutil.SetPragmas(b, []string{"go:noinline", "//go:norace"})
+	dstutil.SetPragmas(c, []string{"//go:noinline"})
+	expect := `package a
+
+// A does things.
+//go:noinline
+func A() {}
+
+// the linkname directive isn't a function pragma
+//go:linkname B runtime.B
+//go:noinline
+//go:norace
+func B() {}
+
+//go:noinline
+func C() {}
+`
+	if found := fprint(); found != expect {
+		t.Errorf("\nexpect: %q\nfound : %q", expect, found)
+	}
+
+	for _, fn := range []*dst.FuncDecl{a, b, c} {
+		dstutil.SetPragmas(fn, nil)
+	}
+	expect = `package a
+
+// A does things.
+func A() {}
+
+// the linkname directive isn't a function pragma
+//go:linkname B runtime.B
+func B() {}
+
+func C() {}
+`
+	if found := fprint(); found != expect {
+		t.Errorf("\nexpect: %q\nfound : %q", expect, found)
+	}
+}
@@ -0,0 +1,115 @@
+package dstutil
+
+import "github.com/dave/dst"
+
+// NameParams adds names to the parameters and results of a function type that are unnamed (e.g.
+// "func(int, string) error" becomes "func(a int, b string) (err error)"). namer is called with the
+// index and type of each parameter, and then each result (the index of the first result is the
+// number of parameters), and returns the name. An empty name is replaced with "_". A list of
+// parameters or results that is already named is not changed, and the decorations of the fields
+// are kept.
+func NameParams(ft *dst.FuncType, namer func(i int, t dst.Expr) string) {
+	var i int
+	for _, fl := range []*dst.FieldList{ft.Params, ft.Results} {
+		if fl == nil {
+			continue
+		}
+		if len(fl.List) == 0 || len(fl.List[0].Names) > 0 {
+			i += fl.NumFields()
+			continue
+		}
+		for _, field := range fl.List {
+			name := namer(i, field.Type)
+			if name == "" {
+				name = "_"
+			}
+			field.Names = []*dst.Ident{dst.NewIdent(name)}
+			i++
+		}
+		fl.Opening, fl.Closing = true, true
+	}
+}
+
+// StripParamNames removes the names of the parameters and results of a function type (e.g.
+// "func(a, b int) (err error)" becomes "func(int, int) error"). A field with several names is
+// split into a field for each name, with copies of the type without decorations for all but the
+// last, and the decorations of the names are moved to the start of the fields. The parentheses of
+// a single result are removed if there are no decorations after the opening parenthesis.
+func StripParamNames(ft *dst.FuncType) {
+	for _, fl := range []*dst.FieldList{ft.Params, ft.Results} {
+		if fl == nil {
+			continue
+		}
+		var list []*dst.Field
+		for _, field := range fl.List {
+			if len(field.Names) == 0 {
+				list = append(list, field)
+				continue
+			}
+			for j, name := range field.Names {
+				f := &dst.Field{Type: field.Type}
+				if j < len(field.Names)-1 {
+					f.Type = stripped(field.Type).(dst.Expr)
+				} else {
+					f.Type.Decorations().Start.Prepend(field.Decs.Type...)
+					f.Decs.End = field.Decs.End
+					f.Decs.After = field.Decs.After
+				}
+				if j == 0 {
+					f.Decs.Before = field.Decs.Before
+					f.Decs.Start = field.Decs.Start
+				} else {
+					f.Decs.Before = name.Decs.Before
+				}
+				f.Decs.Start.Append(name.Decs.Start...)
+				f.Decs.Start.Append(name.Decs.End...)
+				list = append(list, f)
+			}
+		}
+		fl.List = list
+	}
+	if ft.Results != nil && len(ft.Results.List) == 1 && len(ft.Results.Decs.Opening) == 0 {
+		ft.Results.Opening, ft.Results.Closing = false, false
+	}
+}
+
+// SetVariadic changes the type T of the last parameter of a function type to the variadic "...T"
+// (e.g. "func(a int)" becomes "func(a ...int)") if variadic is true, or the variadic "...T" to T if
+// variadic is false. If the last field has several names, the last name is split into a separate
+// field first. The decorations of the type are moved to the *dst.Ellipsis, and when it's removed
+// the decorations after the "..." are moved to the start of the type. Nothing is changed if the
+// function has no parameters or the last parameter is already of the requested form.
+func SetVariadic(ft *dst.FuncType, variadic bool) {
+	if ft.Params == nil || len(ft.Params.List) == 0 {
+		return
+	}
+	field := ft.Params.List[len(ft.Params.List)-1]
+	ellipsis, isVariadic := field.Type.(*dst.Ellipsis)
+	if variadic == isVariadic {
+		return
+	}
+	if len(field.Names) > 1 {
+		last := &dst.Field{Names: field.Names[len(field.Names)-1:], Type: field.Type}
+		last.Decs.End, last.Decs.After = field.Decs.End, field.Decs.After
+		field.Names = field.Names[:len(field.Names)-1]
+		field.Type = stripped(field.Type).(dst.Expr)
+		field.Decs.End, field.Decs.After = nil, dst.None
+		ft.Params.List = append(ft.Params.List, last)
+		field = last
+	}
+	if variadic {
+		t := field.Type
+		e := &dst.Ellipsis{Elt: t}
+		e.Decs.NodeDecs = *t.Decorations()
+		*t.Decorations() = dst.NodeDecs{}
+		field.Type = e
+		return
+	}
+	t := ellipsis.Elt
+	decs := t.Decorations()
+	decs.Start.Prepend(ellipsis.Decs.Ellipsis...)
+	decs.Start.Prepend(ellipsis.Decs.Start...)
+	decs.End.Append(ellipsis.Decs.End...)
+	decs.Before, decs.After = ellipsis.Decs.Before, ellipsis.Decs.After
+	field.Type = t
+}
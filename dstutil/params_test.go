@@ -0,0 +1,146 @@
+package dstutil_test
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/dave/dst"
+	"github.com/dave/dst/decorator"
+	"github.com/dave/dst/dstutil"
+)
+
+func TestNameParams(t *testing.T) {
+	code := `package a
+
+type I interface {
+	A(int, string /* s */, ...bool) error
+	B(x, y int) (int, error)
+	C()
+}
+`
+	f, err := decorator.Parse(code)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fprint := func() string {
+		t.Helper()
+		buf := &bytes.Buffer{}
+		if err := decorator.Fprint(buf, f); err != nil {
+			t.Fatal(err)
+		}
+		return buf.String()
+	}
+	methods := f.Decls[0].(*dst.GenDecl).Specs[0].(*dst.TypeSpec).Type.(*dst.InterfaceType).Methods.List
+
+	for _, m := range methods {
+		dstutil.NameParams(m.Type.(*dst.FuncType), func(i int, t dst.Expr) string {
+			if id, ok := t.(*dst.Ident); ok && id.Name == "error" {
+				return "err"
+			}
+			return fmt.Sprintf("p%d", i)
+		})
+	}
+	expect := `package a
+
+type I interface {
+	A(p0 int, p1 string /* s */, p2 ...bool) (err error)
+	B(x, y int) (p2 int, err error)
+	C()
+}
+`
+	if found := fprint(); found != expect {
+		t.Errorf("\nexpect: %q\nfound : %q", expect, found)
+	}
+
+	for _, m := range methods {
+		dstutil.StripParamNames(m.Type.(*dst.FuncType))
+	}
+	expect = `package a
+
+type I interface {
+	A(int, string /* s */, ...bool) error
+	B(int, int) (int, error)
+	C()
+}
+`
+	if found := fprint(); found != expect {
+		t.Errorf("\nexpect: %q\nfound : %q", expect, found)
+	}
+}
+
+func TestStripParamNames(t *testing.T) {
+	code := `package a
+
+func A(a /* a */, b /* b */ []int, c ...string) (err error) {}
+`
+	f, err := decorator.Parse(code)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dstutil.StripParamNames(f.Decls[0].(*dst.FuncDecl).Type)
+	expect := `package a
+
+func A( /* a */ []int /* b */, []int, ...string) error {}
+`
+	buf := &bytes.Buffer{}
+	if err := decorator.Fprint(buf, f); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != expect {
+		t.Errorf("\nexpect: %q\nfound : %q", expect, buf.String())
+	}
+}
+
+func TestSetVariadic(t *testing.T) {
+	code := `package a
+
+func A(a /* a */ ... /* b */ int) {}
+
+func B(a string, b, c []int /* c */) {}
+
+func C() {}
+`
+	f, err := decorator.Parse(code)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fprint := func() string {
+		t.Helper()
+		buf := &bytes.Buffer{}
+		if err := decorator.Fprint(buf, f); err != nil {
+			t.Fatal(err)
+		}
+		return buf.String()
+	}
+	a, b, c := f.Decls[0].(*dst.FuncDecl).Type, f.Decls[1].(*dst.FuncDecl).Type, f.Decls[2].(*dst.FuncDecl).Type
+
+	dstutil.SetVariadic(a, false)
+	dstutil.SetVariadic(b, true)
+	dstutil.SetVariadic(c, true)
+	expect := `package a
+
+func A(a /* a */ /* b */ int) {}
+
+func B(a string, b []int, c ...[]int /* c */) {}
+
+func C() {}
+`
+	if found := fprint(); found != expect {
+		t.Errorf("\nexpect: %q\nfound : %q", expect, found)
+	}
+
+	dstutil.SetVariadic(a, true)
+	dstutil.SetVariadic(b, false)
+	expect = `package a
+
+func A(a /* a */ /* b */ ...int) {}
+
+func B(a string, b []int, c []int /* c */) {}
+
+func C() {}
+`
+	if found := fprint(); found != expect {
+		t.Errorf("\nexpect: %q\nfound : %q", expect, found)
+	}
+}
@@ -0,0 +1,281 @@
+package dstutil
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"reflect"
+	"unicode"
+
+	"github.com/dave/dst"
+)
+
+// Rewrite replaces each expression in root that matches pattern with replacement, in the same way
+// as a gofmt rewrite rule (gofmt -r 'pattern -> replacement'), and returns the number of
+// expressions replaced. The pattern and replacement are Go expressions, and single-letter lowercase
+// identifiers in them are wildcards: a wildcard in the pattern matches any expression, all
+// occurrences of a wildcard must match structurally equal expressions (ignoring decorations), and
+// a wildcard in the replacement is replaced by a clone of the expression it matched. An error is
+// returned if pattern or replacement can't be parsed, contains a function literal, or replacement
+// contains a wildcard that isn't in pattern.
+//
+// Matches are rewritten bottom-up, so the children of an expression are rewritten before the
+// expression itself is matched. Identifiers are matched by name and Path, so a qualified
+// identifier in the pattern (e.g. "strings.Index(a, b)") only matches selector expressions, as in
+// a file decorated without import management.
+//
+// The replaced expression's line spacing and Start and End decorations are moved to the
+// replacement, and the expressions matched by wildcards keep their decorations. Decorations inside
+// the matched expression that aren't in a wildcard's expression are removed.
+func Rewrite(root dst.Node, pattern, replacement string) (int, error) {
+	p, err := parsePattern(pattern)
+	if err != nil {
+		return 0, fmt.Errorf("parsing pattern %q: %v", pattern, err)
+	}
+	r, err := parsePattern(replacement)
+	if err != nil {
+		return 0, fmt.Errorf("parsing replacement %q: %v", replacement, err)
+	}
+	inPattern := map[string]bool{}
+	dst.Inspect(p, func(n dst.Node) bool {
+		if id, ok := n.(*dst.Ident); ok && isWildcard(id.Name) {
+			inPattern[id.Name] = true
+		}
+		return true
+	})
+	var missing string
+	dst.Inspect(r, func(n dst.Node) bool {
+		if id, ok := n.(*dst.Ident); ok && isWildcard(id.Name) && !inPattern[id.Name] {
+			missing = id.Name
+		}
+		return missing == ""
+	})
+	if missing != "" {
+		return 0, fmt.Errorf("replacement %q contains wildcard %q that isn't in the pattern", replacement, missing)
+	}
+
+	var count int
+	Apply(root, nil, func(c *Cursor) bool {
+		e, ok := c.Node().(dst.Expr)
+		if !ok {
+			return true
+		}
+		m := map[string]dst.Node{}
+		if !matchPattern(m, reflect.ValueOf(p), reflect.ValueOf(e)) {
+			return true
+		}
+		out := substitutePattern(m, r)
+		if !canReplace(c, out) {
+			return true
+		}
+		decs, matched := out.Decorations(), e.Decorations()
+		decs.Before, decs.After = matched.Before, matched.After
+		decs.Start.Prepend(matched.Start...)
+		decs.End.Append(matched.End...)
+		c.Replace(out)
+		count++
+		return true
+	})
+	return count, nil
+}
+
+// isWildcard returns true if name is a single lowercase letter.
+func isWildcard(name string) bool {
+	return len(name) == 1 && unicode.IsLower(rune(name[0]))
+}
+
+// matchPattern returns true if the value v matches the pattern p, adding the expressions matched
+// by wildcards to m.
+func matchPattern(m map[string]dst.Node, p, v reflect.Value) bool {
+	if p.IsValid() && p.Kind() == reflect.Ptr && !p.IsNil() {
+		if id, ok := p.Interface().(*dst.Ident); ok && isWildcard(id.Name) {
+			if !v.IsValid() || (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) && v.IsNil() {
+				return false
+			}
+			n, ok := v.Interface().(dst.Expr)
+			if !ok {
+				return false
+			}
+			if old, ok := m[id.Name]; ok {
+				return structurallyEqual(old.(dst.Expr), n)
+			}
+			m[id.Name] = n
+			return true
+		}
+	}
+	if !p.IsValid() || !v.IsValid() {
+		return !p.IsValid() && !v.IsValid()
+	}
+	if p.Kind() == reflect.Interface {
+		if p.IsNil() || v.IsNil() {
+			return p.IsNil() && v.IsNil()
+		}
+		return matchPattern(m, p.Elem(), v.Elem())
+	}
+	if p.Type() != v.Type() {
+		return false
+	}
+	switch p.Kind() {
+	case reflect.Ptr:
+		if p.IsNil() || v.IsNil() {
+			return p.IsNil() && v.IsNil()
+		}
+		return matchPattern(m, p.Elem(), v.Elem())
+	case reflect.Slice:
+		if p.Len() != v.Len() {
+			return false
+		}
+		for i := 0; i < p.Len(); i++ {
+			if !matchPattern(m, p.Index(i), v.Index(i)) {
+				return false
+			}
+		}
+		return true
+	case reflect.Struct:
+		for i := 0; i < p.NumField(); i++ {
+			switch p.Type().Field(i).Name {
+			case "Decs", "Obj":
+				continue
+			}
+			if !matchPattern(m, p.Field(i), v.Field(i)) {
+				return false
+			}
+		}
+		return true
+	}
+	return p.Interface() == v.Interface()
+}
+
+// substitutePattern returns a clone of the replacement r, with the wildcards replaced by clones of
+// the expressions in m.
+func substitutePattern(m map[string]dst.Node, r dst.Expr) dst.Expr {
+	return Apply(dst.Clone(r), func(c *Cursor) bool {
+		id, ok := c.Node().(*dst.Ident)
+		if !ok || !isWildcard(id.Name) {
+			return true
+		}
+		n := dst.Clone(m[id.Name])
+		if !canReplace(c, n) {
+			return true
+		}
+		c.Replace(n)
+		return false
+	}, nil).(dst.Expr)
+}
+
+// canReplace returns true if the node at the cursor can be replaced by n, which must be assignable
+// to the field (e.g. the Sel field of a selector expression is an identifier).
+func canReplace(c *Cursor, n dst.Node) bool {
+	t := c.field().Type()
+	if c.Index() >= 0 {
+		t = t.Elem()
+	}
+	return reflect.TypeOf(n).AssignableTo(t)
+}
+
+// parsePattern parses and converts a pattern or replacement expression.
+func parsePattern(src string) (dst.Expr, error) {
+	e, err := parser.ParseExprFrom(token.NewFileSet(), "", src, 0)
+	if err != nil {
+		return nil, err
+	}
+	return convertPattern(e)
+}
+
+// convertPattern converts an *ast.Expr to the equivalent dst.Expr without decorations. Function
+// literals are not supported.
+func convertPattern(e ast.Expr) (dst.Expr, error) {
+	if e == nil {
+		return nil, nil
+	}
+	var err error
+	convert := func(e ast.Expr) dst.Expr {
+		if err != nil {
+			return nil
+		}
+		var out dst.Expr
+		out, err = convertPattern(e)
+		return out
+	}
+	convertList := func(list []ast.Expr) []dst.Expr {
+		var out []dst.Expr
+		for _, e := range list {
+			out = append(out, convert(e))
+		}
+		return out
+	}
+	convertFields := func(fl *ast.FieldList) *dst.FieldList {
+		if fl == nil {
+			return nil
+		}
+		out := &dst.FieldList{Opening: fl.Opening.IsValid(), Closing: fl.Closing.IsValid()}
+		for _, f := range fl.List {
+			field := &dst.Field{Type: convert(f.Type)}
+			for _, name := range f.Names {
+				field.Names = append(field.Names, dst.NewIdent(name.Name))
+			}
+			if f.Tag != nil {
+				field.Tag = &dst.BasicLit{Kind: f.Tag.Kind, Value: f.Tag.Value}
+			}
+			out.List = append(out.List, field)
+		}
+		return out
+	}
+	ident := func(id *ast.Ident) *dst.Ident {
+		if id == nil {
+			return nil
+		}
+		return dst.NewIdent(id.Name)
+	}
+
+	var out dst.Expr
+	switch e := e.(type) {
+	case *ast.Ident:
+		out = ident(e)
+	case *ast.BasicLit:
+		out = &dst.BasicLit{Kind: e.Kind, Value: e.Value}
+	case *ast.Ellipsis:
+		out = &dst.Ellipsis{Elt: convert(e.Elt)}
+	case *ast.CompositeLit:
+		out = &dst.CompositeLit{Type: convert(e.Type), Elts: convertList(e.Elts), Incomplete: e.Incomplete}
+	case *ast.ParenExpr:
+		out = &dst.ParenExpr{X: convert(e.X)}
+	case *ast.SelectorExpr:
+		out = &dst.SelectorExpr{X: convert(e.X), Sel: ident(e.Sel)}
+	case *ast.IndexExpr:
+		out = &dst.IndexExpr{X: convert(e.X), Index: convert(e.Index)}
+	case *ast.SliceExpr:
+		out = &dst.SliceExpr{X: convert(e.X), Low: convert(e.Low), High: convert(e.High), Max: convert(e.Max), Slice3: e.Slice3}
+	case *ast.TypeAssertExpr:
+		out = &dst.TypeAssertExpr{X: convert(e.X), Type: convert(e.Type)}
+	case *ast.CallExpr:
+		out = &dst.CallExpr{Fun: convert(e.Fun), Args: convertList(e.Args), Ellipsis: e.Ellipsis.IsValid()}
+	case *ast.StarExpr:
+		out = &dst.StarExpr{X: convert(e.X)}
+	case *ast.UnaryExpr:
+		out = &dst.UnaryExpr{Op: e.Op, X: convert(e.X)}
+	case *ast.BinaryExpr:
+		out = &dst.BinaryExpr{X: convert(e.X), Op: e.Op, Y: convert(e.Y)}
+	case *ast.KeyValueExpr:
+		out = &dst.KeyValueExpr{Key: convert(e.Key), Value: convert(e.Value)}
+	case *ast.ArrayType:
+		out = &dst.ArrayType{Len: convert(e.Len), Elt: convert(e.Elt)}
+	case *ast.StructType:
+		out = &dst.StructType{Fields: convertFields(e.Fields), Incomplete: e.Incomplete}
+	case *ast.FuncType:
+		out = &dst.FuncType{Func: e.Func.IsValid(), Params: convertFields(e.Params), Results: convertFields(e.Results)}
+	case *ast.InterfaceType:
+		out = &dst.InterfaceType{Methods: convertFields(e.Methods), Incomplete: e.Incomplete}
+	case *ast.MapType:
+		out = &dst.MapType{Key: convert(e.Key), Value: convert(e.Value)}
+	case *ast.ChanType:
+		out = &dst.ChanType{Dir: dst.ChanDir(e.Dir), Value: convert(e.Value)}
+	default:
+		return nil, fmt.Errorf("unsupported expression %T", e)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
@@ -0,0 +1,126 @@
+package dstutil_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/dave/dst/decorator"
+	"github.com/dave/dst/dstutil"
+)
+
+func TestRewritePattern(t *testing.T) {
+	tests := []struct {
+		name, pattern, replacement, code, expect string
+		count                                    int
+	}{
+		{
+			name:        "slice",
+			pattern:     "a[b:len(a)]",
+			replacement: "a[b:]",
+			code: `package a
+
+func f(s, t []int, i int) {
+	_ = s[i:len(s)] // a
+	_ = s[f( /* b */ i):len(s)]
+	_ = s[i:len(t)]
+	_ = s[0:len(s)][0:len(s[0:len(s)])]
+}
+`,
+			expect: `package a
+
+func f(s, t []int, i int) {
+	_ = s[i:] // a
+	_ = s[f( /* b */ i):]
+	_ = s[i:len(t)]
+	_ = s[0:][0:]
+}
+`,
+			count: 5,
+		},
+		{
+			name:        "equal",
+			pattern:     "bytes.Compare(a, b) == 0",
+			replacement: "bytes.Equal(a, b)",
+			code: `package a
+
+import "bytes"
+
+func f(x, y []byte) bool {
+	if /* a */ bytes.Compare(x, y /* b */) == 0 {
+		return bytes.Compare(x, y) != 0
+	}
+	return false
+}
+`,
+			expect: `package a
+
+import "bytes"
+
+func f(x, y []byte) bool {
+	if /* a */ bytes.Equal(x, y /* b */) {
+		return bytes.Compare(x, y) != 0
+	}
+	return false
+}
+`,
+			count: 1,
+		},
+		{
+			name:        "swap",
+			pattern:     "strings.Index(a, b) >= 0",
+			replacement: "strings.Contains(a, b)",
+			code: `package a
+
+import "strings"
+
+var x = strings.Index("a", "b") >= 0 && strings.Index(s, "c") >= 0
+`,
+			expect: `package a
+
+import "strings"
+
+var x = strings.Contains("a", "b") && strings.Contains(s, "c")
+`,
+			count: 2,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			f, err := decorator.Parse(test.code)
+			if err != nil {
+				t.Fatal(err)
+			}
+			count, err := dstutil.Rewrite(f, test.pattern, test.replacement)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if count != test.count {
+				t.Errorf("expected %d rewrites, found %d", test.count, count)
+			}
+			buf := &bytes.Buffer{}
+			if err := decorator.Fprint(buf, f); err != nil {
+				t.Fatal(err)
+			}
+			if buf.String() != test.expect {
+				t.Errorf("\nexpect: %q\nfound : %q", test.expect, buf.String())
+			}
+		})
+	}
+}
+
+func TestRewritePatternErrors(t *testing.T) {
+	f, err := decorator.Parse("package a\n\nvar a = b\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, rule := range [][2]string{
+		{"a[", "a"},
+		{"a", "a +"},
+		{"a + b", "a + c"},
+		{"func() {}", "a"},
+	} {
+		if _, err := dstutil.Rewrite(f, rule[0], rule[1]); err == nil {
+			t.Errorf("expected error for %q -> %q, found none", rule[0], rule[1])
+		}
+	}
+}
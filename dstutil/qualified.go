@@ -0,0 +1,241 @@
+package dstutil
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"strconv"
+	"strings"
+
+	"github.com/dave/dst"
+	"github.com/dave/dst/decorator/resolver"
+	"github.com/dave/dst/decorator/resolver/goast"
+	"github.com/dave/dst/decorator/resolver/guess"
+)
+
+// ExpandQualifiedIdents replaces each identifier with Path set by the equivalent *dst.SelectorExpr
+// (e.g. Ident{Name: "Println", Path: "fmt"} becomes fmt.Println), so the file can be processed by
+// code that expects the qualified identifiers of a file decorated without import management. The
+// package name is the alias of the matching import spec, or if the import isn't aliased the name
+// resolved by r (guess.New() is used if r is nil). Identifiers from dot-imported packages have Path
+// cleared. The decorations of the identifier are moved to the selector expression. An error is
+// returned if the file has no import spec for a path.
+func ExpandQualifiedIdents(f *dst.File, r resolver.RestorerResolver) error {
+
+	if r == nil {
+		r = guess.New()
+	}
+
+	// aliases maps the import path to the import alias, or to an empty string for imports that
+	// aren't aliased
+	aliases := map[string]string{}
+	for _, decl := range f.Decls {
+		gd, ok := decl.(*dst.GenDecl)
+		if !ok || gd.Tok != token.IMPORT {
+			continue
+		}
+		for _, spec := range gd.Specs {
+			spec := spec.(*dst.ImportSpec)
+			path, err := strconv.Unquote(spec.Path.Value)
+			if err != nil {
+				return err
+			}
+			switch {
+			case spec.Name == nil:
+				aliases[path] = ""
+			case spec.Name.Name != "_":
+				aliases[path] = spec.Name.Name
+			}
+		}
+	}
+
+	var err error
+	Apply(f, nil, func(c *Cursor) bool {
+		id, ok := c.Node().(*dst.Ident)
+		if !ok || id.Path == "" {
+			return true
+		}
+		alias, ok := aliases[id.Path]
+		if !ok {
+			err = fmt.Errorf("no import spec for %q", id.Path)
+			return false
+		}
+		if alias == "." {
+			id.Path = ""
+			return true
+		}
+		name := alias
+		if name == "" {
+			name, err = r.ResolvePackage(id.Path)
+			if err != nil {
+				return false
+			}
+			aliases[id.Path] = name
+		}
+		sel := &dst.SelectorExpr{
+			X:   dst.NewIdent(name),
+			Sel: dst.NewIdent(id.Name),
+		}
+		sel.Decs.NodeDecs = id.Decs.NodeDecs
+		sel.Decs.X = id.Decs.X
+		c.Replace(sel)
+		return true
+	})
+	return err
+}
+
+// CollapseQualifiedSelectors is the inverse of ExpandQualifiedIdents: each qualified identifier
+// (e.g. fmt.Println) is replaced by an identifier with Path set. Selector expressions are resolved
+// with r, which is passed the import specs of the file in a synthetic *ast.File, so must resolve
+// identifiers from the imports (goast.New() is used if r is nil). Only selector expressions with a
+// package identifier that has no Obj and no Path are considered, and references to the cgo "C"
+// package are left unchanged. The decorations of the selector expression are moved to the
+// identifier.
+func CollapseQualifiedSelectors(f *dst.File, r resolver.DecoratorResolver) error {
+
+	if r == nil {
+		r = goast.New()
+	}
+
+	af := importsFile(f)
+
+	var err error
+	Apply(f, nil, func(c *Cursor) bool {
+		n, ok := c.Node().(*dst.SelectorExpr)
+		if !ok {
+			return true
+		}
+		x, ok := n.X.(*dst.Ident)
+		if !ok || x.Path != "" || x.Obj != nil || x.Name == "C" {
+			return true
+		}
+		sel := ast.NewIdent(n.Sel.Name)
+		var path string
+		path, err = r.ResolveIdent(af, &ast.SelectorExpr{X: ast.NewIdent(x.Name), Sel: sel}, "Sel", sel)
+		if err != nil {
+			return false
+		}
+		if path == "" {
+			return true
+		}
+		id := &dst.Ident{Name: n.Sel.Name, Path: path, Obj: n.Sel.Obj}
+		id.Decs.NodeDecs = n.Decs.NodeDecs
+		id.Decs.Start.Append(x.Decs.Start...)
+		id.Decs.X.Append(x.Decs.End...)
+		id.Decs.X.Append(n.Decs.X...)
+		id.Decs.X.Append(n.Sel.Decs.Start...)
+		id.Decs.End.Replace(n.Sel.Decs.End...)
+		id.Decs.End.Append(n.Decs.End...)
+		c.Replace(id)
+		return true
+	})
+	return err
+}
+
+// QualifyDotImports converts each dot-import (import . "pkg") in f to a normal import, and the
+// identifiers from the dot-imported packages to qualified identifiers. The package name is
+// resolved with guess.New(), and the import is given an explicit alias if the name doesn't match
+// the last element of the import path.
+//
+// Identifiers with Path set are left unchanged, and are qualified by the restorer once the import
+// isn't a dot-import. The identifiers from dot-imported packages only have Path set in a file
+// decorated with the gotypes resolver, so with gotypes exports can be nil, and the file is restored
+// with import management.
+//
+// Other identifiers are looked up in the exported names of the dot-imported packages, which are
+// returned by exports, and those found are replaced by a *dst.SelectorExpr (e.g. Println becomes
+// fmt.Println), with the decorations of the identifier moved to the selector expression. The names
+// can be found from the scope of the package loaded with go/importer or go/packages, e.g.:
+//
+//	exports := func(path string) ([]string, error) {
+//		pkg, err := importer.ForCompiler(fset, "source", nil).Import(path)
+//		if err != nil {
+//			return nil, err
+//		}
+//		return pkg.Scope().Names(), nil
+//	}
+//
+// If exports is nil these identifiers are left unchanged. Identifiers with an Obj or with the name
+// of a top-level declaration of f are declared locally, so are never changed, and neither are
+// declared names, labels, selectors and the field names of struct literals.
+func QualifyDotImports(f *dst.File, exports func(path string) ([]string, error)) error {
+
+	// names maps the paths of the dot-imported packages to the package names, and paths maps the
+	// exported names of the packages to the paths
+	names := map[string]string{}
+	paths := map[string]string{}
+	var specs []*dst.ImportSpec
+	for _, decl := range f.Decls {
+		gd, ok := decl.(*dst.GenDecl)
+		if !ok || gd.Tok != token.IMPORT {
+			continue
+		}
+		for _, spec := range gd.Specs {
+			spec := spec.(*dst.ImportSpec)
+			if spec.Name == nil || spec.Name.Name != "." {
+				continue
+			}
+			path, err := strconv.Unquote(spec.Path.Value)
+			if err != nil {
+				return err
+			}
+			name, err := guess.New().ResolvePackage(path)
+			if err != nil {
+				return err
+			}
+			names[path] = name
+			specs = append(specs, spec)
+			if exports == nil {
+				continue
+			}
+			exported, err := exports(path)
+			if err != nil {
+				return err
+			}
+			for _, name := range exported {
+				if _, ok := paths[name]; !ok && token.IsExported(name) {
+					paths[name] = path
+				}
+			}
+		}
+	}
+	if len(specs) == 0 {
+		return nil
+	}
+
+	if len(paths) > 0 {
+		declared := topLevelNames(f)
+		fields := selectorIdents(f)
+		Apply(f, nil, func(c *Cursor) bool {
+			id, ok := c.Node().(*dst.Ident)
+			if !ok || id.Path != "" || id.Obj != nil || declared[id.Name] || fields[id] || !isReference(c) {
+				return true
+			}
+			if _, ok := c.Parent().(*dst.ImportSpec); ok {
+				return true
+			}
+			path, ok := paths[id.Name]
+			if !ok {
+				return true
+			}
+			sel := &dst.SelectorExpr{
+				X:   dst.NewIdent(names[path]),
+				Sel: dst.NewIdent(id.Name),
+			}
+			sel.Decs.NodeDecs = id.Decs.NodeDecs
+			sel.Decs.X = id.Decs.X
+			c.Replace(sel)
+			return true
+		})
+	}
+
+	for _, spec := range specs {
+		path, _ := strconv.Unquote(spec.Path.Value)
+		if names[path] == path[strings.LastIndex(path, "/")+1:] {
+			spec.Name = nil
+		} else {
+			spec.Name.Name = names[path]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,230 @@
+package dstutil_test
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"strings"
+	"testing"
+
+	"github.com/dave/dst"
+	"github.com/dave/dst/decorator"
+	"github.com/dave/dst/decorator/resolver/goast"
+	"github.com/dave/dst/decorator/resolver/gotypes"
+	"github.com/dave/dst/decorator/resolver/guess"
+	"github.com/dave/dst/dstutil"
+)
+
+func TestQualifiedIdents(t *testing.T) {
+	code := `package a
+
+import (
+	"fmt"
+	z "github.com/a/b-go"
+)
+
+func A() {
+	fmt.Println( /* a */ z.B)
+	fmt. /* b */ Println()
+	var C struct{ D int }
+	C.D = 1
+}
+`
+	fprint := func(f *dst.File) string {
+		t.Helper()
+		buf := &bytes.Buffer{}
+		if err := decorator.Fprint(buf, f); err != nil {
+			t.Fatal(err)
+		}
+		return buf.String()
+	}
+
+	// decorate with import management, so qualified identifiers have Path set
+	dec := decorator.NewDecoratorWithImports(nil, "a", goast.New())
+	f, err := dec.Parse(code)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var paths []string
+	dst.Inspect(f, func(n dst.Node) bool {
+		if id, ok := n.(*dst.Ident); ok && id.Path != "" {
+			paths = append(paths, id.Path)
+		}
+		return true
+	})
+	if len(paths) != 3 {
+		t.Fatalf("expected 3 identifiers with Path, found %q", paths)
+	}
+
+	if err := dstutil.ExpandQualifiedIdents(f, guess.New()); err != nil {
+		t.Fatal(err)
+	}
+	dst.Inspect(f, func(n dst.Node) bool {
+		if id, ok := n.(*dst.Ident); ok && id.Path != "" {
+			t.Errorf("unexpected Path %q on %s", id.Path, id.Name)
+		}
+		return true
+	})
+	if found := fprint(f); found != code {
+		t.Errorf("\nexpect: %q\nfound : %q", code, found)
+	}
+
+	if err := dstutil.CollapseQualifiedSelectors(f, nil); err != nil {
+		t.Fatal(err)
+	}
+	var collapsed []string
+	dst.Inspect(f, func(n dst.Node) bool {
+		if id, ok := n.(*dst.Ident); ok && id.Path != "" {
+			collapsed = append(collapsed, id.Path)
+		}
+		return true
+	})
+	expect := []string{"fmt", "github.com/a/b-go", "fmt"}
+	if len(collapsed) != len(expect) {
+		t.Fatalf("\nexpect: %q\nfound : %q", expect, collapsed)
+	}
+	for i := range expect {
+		if collapsed[i] != expect[i] {
+			t.Fatalf("\nexpect: %q\nfound : %q", expect, collapsed)
+		}
+	}
+
+	// restore with import management to check the round trip
+	buf := &bytes.Buffer{}
+	if err := decorator.NewRestorerWithImports("a", guess.New()).Fprint(buf, f); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != code {
+		t.Errorf("\nexpect: %q\nfound : %q", code, buf.String())
+	}
+}
+
+func TestExpandQualifiedIdentsDotImport(t *testing.T) {
+	f, err := decorator.Parse("package a\n\nimport . \"strings\"\n\nvar A = 1\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	spec := f.Decls[1].(*dst.GenDecl).Specs[0].(*dst.ValueSpec)
+	spec.Values[0] = &dst.Ident{Name: "ToUpper", Path: "strings"}
+	if err := dstutil.ExpandQualifiedIdents(f, nil); err != nil {
+		t.Fatal(err)
+	}
+	if id, ok := spec.Values[0].(*dst.Ident); !ok || id.Name != "ToUpper" || id.Path != "" {
+		t.Errorf("unexpected value %#v", spec.Values[0])
+	}
+
+	spec.Values[0] = &dst.Ident{Name: "B", Path: "github.com/a/b"}
+	if err := dstutil.ExpandQualifiedIdents(f, nil); err == nil {
+		t.Error("expected error, found none")
+	}
+}
+
+func TestQualifyDotImports(t *testing.T) {
+	code := `package a
+
+import (
+	"fmt"
+	. "root/b"
+)
+
+type T struct{ C int }
+
+var D = C
+
+var E = T{C: C}
+
+func F() {
+	B( /* b */ )
+	fmt.Println(C)
+	C := 1
+	_ = C
+}
+`
+	expect := `package a
+
+import (
+	"fmt"
+	"root/b"
+)
+
+type T struct{ C int }
+
+var D = b.C
+
+var E = T{C: b.C}
+
+func F() {
+	b.B( /* b */ )
+	fmt.Println(b.C)
+	C := 1
+	_ = C
+}
+`
+	t.Run("exports", func(t *testing.T) {
+		f, err := decorator.Parse(code)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := dstutil.QualifyDotImports(f, func(path string) ([]string, error) {
+			if path != "root/b" {
+				return nil, fmt.Errorf("unexpected path %s", path)
+			}
+			return []string{"B", "C", "d"}, nil
+		}); err != nil {
+			t.Fatal(err)
+		}
+		buf := &bytes.Buffer{}
+		if err := decorator.Fprint(buf, f); err != nil {
+			t.Fatal(err)
+		}
+		if buf.String() != expect {
+			t.Errorf("\nexpect: %q\nfound : %q", expect, buf.String())
+		}
+	})
+
+	t.Run("gotypes", func(t *testing.T) {
+		fset := token.NewFileSet()
+		af, err := parser.ParseFile(fset, "a.go", code, parser.ParseComments)
+		if err != nil {
+			t.Fatal(err)
+		}
+		info := &types.Info{Uses: map[*ast.Ident]types.Object{}}
+		conf := types.Config{Importer: importerFunc(func(path string) (*types.Package, error) {
+			pkg := types.NewPackage(path, path[strings.LastIndex(path, "/")+1:])
+			if path == "root/b" {
+				pkg.Scope().Insert(types.NewFunc(token.NoPos, pkg, "B", types.NewSignature(nil, nil, nil, false)))
+				pkg.Scope().Insert(types.NewConst(token.NoPos, pkg, "C", types.Typ[types.UntypedInt], constant.MakeInt64(1)))
+			} else {
+				sig := types.NewSignature(nil, types.NewTuple(types.NewVar(token.NoPos, pkg, "a", types.NewSlice(types.NewInterfaceType(nil, nil)))), nil, true)
+				pkg.Scope().Insert(types.NewFunc(token.NoPos, pkg, "Println", sig))
+			}
+			pkg.MarkComplete()
+			return pkg, nil
+		})}
+		if _, err := conf.Check("a", fset, []*ast.File{af}, info); err != nil {
+			t.Fatal(err)
+		}
+		f, err := decorator.NewDecoratorWithImports(fset, "a", gotypes.New(info.Uses)).DecorateFile(af)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := dstutil.QualifyDotImports(f, nil); err != nil {
+			t.Fatal(err)
+		}
+		buf := &bytes.Buffer{}
+		if err := decorator.NewRestorerWithImports("a", guess.New()).Fprint(buf, f); err != nil {
+			t.Fatal(err)
+		}
+		if buf.String() != expect {
+			t.Errorf("\nexpect: %q\nfound : %q", expect, buf.String())
+		}
+	})
+}
+
+type importerFunc func(path string) (*types.Package, error)
+
+func (f importerFunc) Import(path string) (*types.Package, error) { return f(path) }
@@ -0,0 +1,83 @@
+package dstutil
+
+import (
+	"go/token"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/dave/dst"
+)
+
+// QuoteStyle is the quoting used for a string literal.
+type QuoteStyle int
+
+const (
+	DoubleQuote QuoteStyle = iota // DoubleQuote is an interpreted string literal: "foo"
+	RawQuote                      // RawQuote is a raw string literal: `foo`
+)
+
+// NormalizeStringQuotes converts all string literals in root to the preferred quote style where
+// this can be done without changing the value of the string. Struct tags and import paths are
+// never changed. Strings that contain newlines are never changed, so the layout of multi-line raw
+// strings is preserved. A string can only be converted to a raw string if it contains no back
+// quotes, carriage returns or non-printable characters (tabs are allowed).
+func NormalizeStringQuotes(root dst.Node, prefer QuoteStyle) {
+	skip := map[*dst.BasicLit]bool{}
+	dst.Inspect(root, func(n dst.Node) bool {
+		switch n := n.(type) {
+		case *dst.Field:
+			if n.Tag != nil {
+				skip[n.Tag] = true
+			}
+		case *dst.ImportSpec:
+			if n.Path != nil {
+				skip[n.Path] = true
+			}
+		case *dst.BasicLit:
+			if n.Kind != token.STRING || skip[n] {
+				return true
+			}
+			if value, ok := requote(n.Value, prefer); ok {
+				n.Value = value
+			}
+		}
+		return true
+	})
+}
+
+// requote returns the literal converted to the preferred quote style, or false if this can't be
+// done safely.
+func requote(literal string, prefer QuoteStyle) (string, bool) {
+	if literal == "" {
+		return "", false
+	}
+	raw := literal[0] == '`'
+	if raw == (prefer == RawQuote) {
+		// already in the preferred style
+		return "", false
+	}
+	s, err := strconv.Unquote(literal)
+	if err != nil {
+		return "", false
+	}
+	if strings.Contains(s, "\n") {
+		return "", false
+	}
+	switch prefer {
+	case DoubleQuote:
+		return strconv.Quote(s), true
+	case RawQuote:
+		if !utf8.ValidString(s) {
+			return "", false
+		}
+		for _, r := range s {
+			if r == '`' || r == '\r' || (r != '\t' && !unicode.IsPrint(r)) {
+				return "", false
+			}
+		}
+		return "`" + s + "`", true
+	}
+	return "", false
+}
@@ -0,0 +1,54 @@
+package dstutil_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/dave/dst/decorator"
+	"github.com/dave/dst/dstutil"
+)
+
+func TestNormalizeStringQuotes(t *testing.T) {
+	tests := []struct {
+		name         string
+		prefer       dstutil.QuoteStyle
+		code, expect string
+	}{
+		{
+			name:   "to-double",
+			prefer: dstutil.DoubleQuote,
+			code: "package a\n\nimport \"fmt\"\n\n" +
+				"type T struct {\n\tA string `json:\"a\"`\n}\n\n" +
+				"var (\n\ta = `foo`\n\tb = `C:\\dir\\\"x\"`\n\tc = `multi\nline`\n\td = 'x'\n)\n",
+			expect: "package a\n\nimport \"fmt\"\n\n" +
+				"type T struct {\n\tA string `json:\"a\"`\n}\n\n" +
+				"var (\n\ta = \"foo\"\n\tb = \"C:\\\\dir\\\\\\\"x\\\"\"\n\tc = `multi\nline`\n\td = 'x'\n)\n",
+		},
+		{
+			name:   "to-raw",
+			prefer: dstutil.RawQuote,
+			code: "package a\n\nimport \"fmt\"\n\n" +
+				"type T struct {\n\tA string \"json:\\\"a\\\"\"\n}\n\n" +
+				"var (\n\ta = \"foo\"\n\tb = \"C:\\\\dir\\t\\\"x\\\"\"\n\tc = \"back`quote\"\n\td = \"new\\nline\"\n\te = \"bell\\a\"\n\tf = \"\\xff\"\n)\n",
+			expect: "package a\n\nimport \"fmt\"\n\n" +
+				"type T struct {\n\tA string \"json:\\\"a\\\"\"\n}\n\n" +
+				"var (\n\ta = `foo`\n\tb = `C:\\dir\t\"x\"`\n\tc = \"back`quote\"\n\td = \"new\\nline\"\n\te = \"bell\\a\"\n\tf = \"\\xff\"\n)\n",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			f, err := decorator.Parse(test.code)
+			if err != nil {
+				t.Fatal(err)
+			}
+			dstutil.NormalizeStringQuotes(f, test.prefer)
+			buf := &bytes.Buffer{}
+			if err := decorator.Fprint(buf, f); err != nil {
+				t.Fatal(err)
+			}
+			if buf.String() != test.expect {
+				t.Errorf("\nexpect: %q\nfound : %q", test.expect, buf.String())
+			}
+		})
+	}
+}
@@ -43,6 +43,14 @@ type ApplyFunc func(*Cursor) bool
 // traversed in the filenames' alphabetical order.
 //
 func Apply(root dst.Node, pre, post ApplyFunc) (result dst.Node) {
+	return ApplyWithChanges(root, pre, post, nil)
+}
+
+// ApplyWithChanges is the same as Apply, but additionally records in changes each file that is
+// modified by the Replace, Delete, InsertAfter or InsertBefore Cursor methods. Files are only
+// recorded if root is a *dst.File or *dst.Package. A file replaced with Replace is recorded as the
+// replacement file, including when root is the file, in which case the replacement is returned.
+func ApplyWithChanges(root dst.Node, pre, post ApplyFunc, changes *Changes) (result dst.Node) {
 	parent := &struct{ dst.Node }{root}
 	defer func() {
 		if r := recover(); r != nil && r != abort {
@@ -50,7 +58,8 @@ func Apply(root dst.Node, pre, post ApplyFunc) (result dst.Node) {
 		}
 		result = parent.Node
 	}()
-	a := &application{pre: pre, post: post}
+	a := &application{pre: pre, post: post, changes: changes}
+	a.cursor.app = a
 	a.apply(parent, "Node", nil, root)
 	return
 }
@@ -75,6 +84,7 @@ type Cursor struct {
 	name   string
 	iter   *iterator // valid if non-nil
 	node   dst.Node
+	app    *application
 }
 
 // Node returns the current Node.
@@ -112,9 +122,16 @@ func (c *Cursor) Replace(n dst.Node) {
 		if !ok {
 			panic("attempt to replace *dst.File with non-*dst.File")
 		}
-		c.parent.(*dst.Package).Files[c.name] = file
+		if pkg, ok := c.parent.(*dst.Package); ok {
+			pkg.Files[c.name] = file
+		} else {
+			// the root node, which is returned by Apply
+			c.field().Set(reflect.ValueOf(n))
+		}
+		c.app.changes.mark(file)
 		return
 	}
+	c.changed()
 
 	v := c.field()
 	if i := c.Index(); i >= 0 {
@@ -137,6 +154,7 @@ func (c *Cursor) Delete() {
 	if i < 0 {
 		panic("Delete node not contained in slice")
 	}
+	c.changed()
 	v := c.field()
 	l := v.Len()
 	reflect.Copy(v.Slice(i, l), v.Slice(i+1, l))
@@ -153,6 +171,7 @@ func (c *Cursor) InsertAfter(n dst.Node) {
 	if i < 0 {
 		panic("InsertAfter node not contained in slice")
 	}
+	c.changed()
 	v := c.field()
 	v.Set(reflect.Append(v, reflect.Zero(v.Type().Elem())))
 	l := v.Len()
@@ -169,6 +188,7 @@ func (c *Cursor) InsertBefore(n dst.Node) {
 	if i < 0 {
 		panic("InsertBefore node not contained in slice")
 	}
+	c.changed()
 	v := c.field()
 	v.Set(reflect.Append(v, reflect.Zero(v.Type().Elem())))
 	l := v.Len()
@@ -177,11 +197,18 @@ func (c *Cursor) InsertBefore(n dst.Node) {
 	c.iter.index++
 }
 
+// changed records that the file containing the current node has been modified.
+func (c *Cursor) changed() {
+	c.app.changes.mark(c.app.file)
+}
+
 // application carries all the shared data so we can pass it around cheaply.
 type application struct {
 	pre, post ApplyFunc
 	cursor    Cursor
 	iter      iterator
+	changes   *Changes
+	file      *dst.File // file currently being walked; or nil
 }
 
 func (a *application) apply(parent dst.Node, name string, iter *iterator, n dst.Node) {
@@ -402,6 +429,9 @@ func (a *application) apply(parent dst.Node, name string, iter *iterator, n dst.
 
 	// Files and packages
 	case *dst.File:
+		savedFile := a.file
+		a.file = n
+		defer func() { a.file = savedFile }()
 		a.apply(n, "Name", nil, n.Name)
 		a.applyList(n, "Decls")
 		// Don't walk n.Comments; they have either been walked already if
@@ -262,3 +262,28 @@ func BenchmarkRewrite(b *testing.B) {
 		})
 	}
 }
+
+func TestApplyWithChangesReplaceFile(t *testing.T) {
+	f, err := decorator.Parse("package a\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	replacement := &dst.File{Name: dst.NewIdent("b")}
+	changes := &dstutil.Changes{}
+	result := dstutil.ApplyWithChanges(f, func(c *dstutil.Cursor) bool {
+		if _, ok := c.Node().(*dst.File); ok {
+			c.Replace(replacement)
+			return false
+		}
+		return true
+	}, nil, changes)
+	if result != replacement {
+		t.Fatalf("expected the replacement file, found %v", result)
+	}
+	if !changes.Changed(replacement) {
+		t.Error("expected the replacement file to be changed")
+	}
+	if changes.Changed(f) {
+		t.Error("expected the original file to be unchanged")
+	}
+}
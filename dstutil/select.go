@@ -0,0 +1,34 @@
+package dstutil
+
+import "github.com/dave/dst"
+
+// AddCommClause appends a clause to a select statement. The clause and its statements are rendered
+// on their own lines unless spacing is already set, and the order of the existing clauses and all
+// their decorations are left unchanged.
+func AddCommClause(sel *dst.SelectStmt, clause *dst.CommClause) {
+	if sel.Body == nil {
+		sel.Body = &dst.BlockStmt{}
+	}
+	list := sel.Body.List
+	if len(list) > 0 {
+		if last := list[len(list)-1].Decorations(); last.After == dst.None {
+			last.After = dst.NewLine
+		}
+	}
+	if clause.Decs.Before == dst.None {
+		clause.Decs.Before = dst.NewLine
+	}
+	if clause.Decs.After == dst.None {
+		clause.Decs.After = dst.NewLine
+	}
+	for _, stmt := range clause.Body {
+		decs := stmt.Decorations()
+		if decs.Before == dst.None {
+			decs.Before = dst.NewLine
+		}
+		if decs.After == dst.None {
+			decs.After = dst.NewLine
+		}
+	}
+	sel.Body.List = append(list, clause)
+}
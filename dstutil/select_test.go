@@ -0,0 +1,54 @@
+package dstutil_test
+
+import (
+	"bytes"
+	"go/token"
+	"testing"
+
+	"github.com/dave/dst"
+	"github.com/dave/dst/decorator"
+	"github.com/dave/dst/dstutil"
+)
+
+func TestAddCommClause(t *testing.T) {
+	code := `package a
+
+func a(c chan int) {
+	select {
+	// a
+	case v := <-c: // b
+		print(v)
+	default:
+	}
+}
+`
+	expect := `package a
+
+func a(c chan int) {
+	select {
+	// a
+	case v := <-c: // b
+		print(v)
+	default:
+	case c <- 1:
+		return
+	}
+}
+`
+	f, err := decorator.Parse(code)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sel := f.Decls[0].(*dst.FuncDecl).Body.List[0].(*dst.SelectStmt)
+	dstutil.AddCommClause(sel, &dst.CommClause{
+		Comm: &dst.SendStmt{Chan: dst.NewIdent("c"), Value: &dst.BasicLit{Kind: token.INT, Value: "1"}},
+		Body: []dst.Stmt{&dst.ReturnStmt{}},
+	})
+	buf := &bytes.Buffer{}
+	if err := decorator.Fprint(buf, f); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != expect {
+		t.Errorf("\nexpect: %q\nfound : %q", expect, buf.String())
+	}
+}
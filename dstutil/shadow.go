@@ -0,0 +1,165 @@
+package dstutil
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"strconv"
+
+	"github.com/dave/dst"
+	"github.com/dave/dst/decorator/resolver"
+	"github.com/dave/dst/decorator/resolver/goast"
+	"github.com/dave/dst/decorator/resolver/guess"
+)
+
+// ShadowWarning describes a local declaration found by DetectShadowedImports that shadows the name
+// of an imported package.
+type ShadowWarning struct {
+	Ident *dst.Ident // the declared identifier
+	Func  dst.Node   // the *dst.FuncDecl or *dst.FuncLit the identifier is declared in
+	Path  string     // the import path of the shadowed package
+}
+
+func (w ShadowWarning) String() string {
+	return fmt.Sprintf("%s shadows the import of %q", w.Ident.Name, w.Path)
+}
+
+// DetectShadowedImports returns a ShadowWarning for each declaration inside a function (including
+// parameters, results and receivers) that has the name of a package that is used qualified in f,
+// in source order. Inside the scope of the declaration the name refers to the local declaration,
+// so a transform that adds a qualified identifier there (e.g. by restoring an identifier with Path
+// set) would produce code that doesn't compile, or silently refers to the wrong thing.
+//
+// The packages used qualified are the selector expressions (e.g. fmt.Println) resolved with r,
+// which is passed the import specs of f in a synthetic *ast.File, so must resolve identifiers from
+// the imports (goast.New() is used if r is nil), and the identifiers with Path set, which have the
+// name of the alias of the import spec or the name resolved by guess.New(). Selector expressions
+// with a package identifier that has an Obj or Path set are not considered.
+func DetectShadowedImports(f *dst.File, r resolver.DecoratorResolver) ([]ShadowWarning, error) {
+
+	if r == nil {
+		r = goast.New()
+	}
+
+	aliases := map[string]string{}
+	for _, decl := range f.Decls {
+		gd, ok := decl.(*dst.GenDecl)
+		if !ok || gd.Tok != token.IMPORT {
+			continue
+		}
+		for _, spec := range gd.Specs {
+			spec := spec.(*dst.ImportSpec)
+			if spec.Name == nil {
+				continue
+			}
+			path, err := strconv.Unquote(spec.Path.Value)
+			if err != nil {
+				return nil, err
+			}
+			aliases[path] = spec.Name.Name
+		}
+	}
+
+	// used maps the names of the packages that are used qualified to the import paths
+	used := map[string]string{}
+	af := importsFile(f)
+	var err error
+	dst.Inspect(f, func(n dst.Node) bool {
+		if err != nil {
+			return false
+		}
+		switch n := n.(type) {
+		case *dst.Ident:
+			if n.Path == "" {
+				return true
+			}
+			name, ok := aliases[n.Path]
+			if !ok {
+				if name, err = guess.New().ResolvePackage(n.Path); err != nil {
+					return false
+				}
+			}
+			if name != "_" && name != "." {
+				used[name] = n.Path
+			}
+		case *dst.SelectorExpr:
+			x, ok := n.X.(*dst.Ident)
+			if !ok || x.Obj != nil || x.Path != "" {
+				return true
+			}
+			sel := ast.NewIdent(n.Sel.Name)
+			var path string
+			path, err = r.ResolveIdent(af, &ast.SelectorExpr{X: ast.NewIdent(x.Name), Sel: sel}, "Sel", sel)
+			if path != "" {
+				used[x.Name] = path
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(used) == 0 {
+		return nil, nil
+	}
+
+	var warnings []ShadowWarning
+	var funcs []dst.Node
+	declare := func(id *dst.Ident) {
+		if path, ok := used[id.Name]; ok {
+			warnings = append(warnings, ShadowWarning{Ident: id, Func: funcs[len(funcs)-1], Path: path})
+		}
+	}
+	declareFields := func(fields ...*dst.FieldList) {
+		for _, fl := range fields {
+			if fl == nil {
+				continue
+			}
+			for _, field := range fl.List {
+				for _, id := range field.Names {
+					declare(id)
+				}
+			}
+		}
+	}
+	Apply(f, func(c *Cursor) bool {
+		switch n := c.Node().(type) {
+		case *dst.FuncDecl:
+			funcs = append(funcs, n)
+			declareFields(n.Recv, n.Type.Params, n.Type.Results)
+		case *dst.FuncLit:
+			funcs = append(funcs, n)
+			declareFields(n.Type.Params, n.Type.Results)
+		case *dst.Ident:
+			if len(funcs) == 0 {
+				return true
+			}
+			switch p := c.Parent().(type) {
+			case *dst.AssignStmt:
+				if p.Tok == token.DEFINE && c.Name() == "Lhs" {
+					declare(n)
+				}
+			case *dst.RangeStmt:
+				if p.Tok == token.DEFINE && (c.Name() == "Key" || c.Name() == "Value") {
+					declare(n)
+				}
+			case *dst.ValueSpec:
+				if c.Name() == "Names" {
+					declare(n)
+				}
+			case *dst.TypeSpec:
+				if c.Name() == "Name" {
+					declare(n)
+				}
+			}
+		}
+		return true
+	}, func(c *Cursor) bool {
+		switch c.Node().(type) {
+		case *dst.FuncDecl, *dst.FuncLit:
+			funcs = funcs[:len(funcs)-1]
+		}
+		return true
+	})
+	return warnings, nil
+}
@@ -0,0 +1,81 @@
+package dstutil_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/dave/dst"
+	"github.com/dave/dst/decorator"
+	"github.com/dave/dst/decorator/resolver/goast"
+	"github.com/dave/dst/dstutil"
+)
+
+func TestDetectShadowedImports(t *testing.T) {
+	code := `package a
+
+import (
+	"fmt"
+	s "strings"
+)
+
+func A() {
+	fmt.Println(s.ToUpper("a"))
+}
+
+func B(s string) {
+	var fmt = 1
+	_ = fmt
+	func() {
+		for s := range []int{} {
+			_ = s
+		}
+	}()
+}
+
+func C(strings string) {
+	type fmt struct{}
+}
+`
+	f, err := decorator.Parse(code)
+	if err != nil {
+		t.Fatal(err)
+	}
+	warnings, err := dstutil.DetectShadowedImports(f, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var found []string
+	for _, w := range warnings {
+		var fn string
+		switch n := w.Func.(type) {
+		case *dst.FuncDecl:
+			fn = n.Name.Name
+		case *dst.FuncLit:
+			fn = "func literal"
+		}
+		found = append(found, fn+": "+w.String())
+	}
+	expect := []string{
+		`B: s shadows the import of "strings"`,
+		`B: fmt shadows the import of "fmt"`,
+		`func literal: s shadows the import of "strings"`,
+		`C: fmt shadows the import of "fmt"`,
+	}
+	if !reflect.DeepEqual(expect, found) {
+		t.Errorf("\nexpect: %q\nfound : %q", expect, found)
+	}
+
+	// identifiers with Path set use the name of the import spec
+	d := decorator.NewDecoratorWithImports(nil, "a", goast.New())
+	f, err = d.Parse(code)
+	if err != nil {
+		t.Fatal(err)
+	}
+	warnings, err = dstutil.DetectShadowedImports(f, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(warnings) != len(expect) {
+		t.Errorf("expected %d warnings, found %d", len(expect), len(warnings))
+	}
+}
@@ -0,0 +1,241 @@
+package dstutil
+
+import (
+	"go/ast"
+	"go/token"
+	"sort"
+	"strconv"
+
+	"github.com/dave/dst"
+	"github.com/dave/dst/decorator/resolver"
+	"github.com/dave/dst/decorator/resolver/goast"
+)
+
+// SplitFile splits a file into several files, grouping the declarations by the key returned by
+// partition. Each file has a copy of the package clause and the file comments, and the subset of
+// the import specs that is used by its declarations. The package doc comment is only kept in the
+// file with the lowest key. The original file is not modified.
+//
+// Identifiers with Path set are matched to the import specs by path. Other qualified identifiers
+// are resolved with r, which is passed the import specs of the file (excluding dot-imports) in a
+// synthetic *ast.File, so must resolve identifiers from the imports (e.g. goast.DecoratorResolver,
+// which is used if r is nil). Anonymous imports are kept in the file with the lowest key. The
+// identifiers from dot-imported packages can't be resolved without Path, so the dot-imports are
+// kept in each file with a reference to an identifier that isn't declared at the top level of f,
+// in a local scope or in the universe scope.
+func SplitFile(f *dst.File, partition func(dst.Decl) int, r resolver.DecoratorResolver) (map[int]*dst.File, error) {
+
+	if r == nil {
+		r = goast.New()
+	}
+
+	// the synthetic *ast.File used to resolve identifiers, without the dot-imports which the
+	// resolvers can't use
+	af := importsFile(f)
+	specs := af.Imports
+	importDecl := af.Decls[0].(*ast.GenDecl)
+	importDecl.Specs, af.Imports = nil, nil
+	for _, is := range specs {
+		if is.Name == nil || is.Name.Name != "." {
+			importDecl.Specs = append(importDecl.Specs, is)
+			af.Imports = append(af.Imports, is)
+		}
+	}
+	declared := topLevelNames(f)
+
+	// start are the file decorations, and doc the index of the package doc comment in start
+	start := f.Decs.Start
+	doc := len(start)
+	for doc > 0 && start[doc-1] != "\n" {
+		doc--
+	}
+
+	// the import declarations at the top of the file
+	var imports []*dst.GenDecl
+	var cgo *dst.GenDecl
+
+	parts := map[int][]dst.Decl{}
+	for _, decl := range f.Decls {
+		if gd, ok := decl.(*dst.GenDecl); ok && gd.Tok == token.IMPORT {
+			if isCgoImport(gd) {
+				cgo = gd
+			} else {
+				imports = append(imports, gd)
+			}
+			continue
+		}
+		key := partition(decl)
+		parts[key] = append(parts[key], decl)
+	}
+
+	var keys []int
+	for key := range parts {
+		keys = append(keys, key)
+	}
+	sort.Ints(keys)
+
+	out := map[int]*dst.File{}
+	for i, key := range keys {
+		used, err := usedImports(af, parts[key], r, declared)
+		if err != nil {
+			return nil, err
+		}
+
+		file := &dst.File{
+			Name: dst.NewIdent(f.Name.Name),
+		}
+		if i == 0 {
+			file.Decs.Start = append(dst.Decorations{}, start...)
+		} else if doc > 0 {
+			// the file comments before the package doc comment, with the empty line between them
+			file.Decs.Start = append(dst.Decorations{}, start[:doc]...)
+		}
+		file.Decs.Name = append(dst.Decorations{}, f.Decs.Name...)
+
+		if cgo != nil && used["C"] {
+			file.Decls = append(file.Decls, dst.Clone(cgo).(*dst.GenDecl))
+		}
+
+		gd := &dst.GenDecl{
+			Tok:  token.IMPORT,
+			Decs: dst.GenDeclDecorations{NodeDecs: dst.NodeDecs{Before: dst.EmptyLine, After: dst.EmptyLine}},
+		}
+		for _, block := range imports {
+			for _, spec := range block.Specs {
+				spec := spec.(*dst.ImportSpec)
+				path, err := strconv.Unquote(spec.Path.Value)
+				if err != nil {
+					return nil, err
+				}
+				anonymous := spec.Name != nil && spec.Name.Name == "_"
+				dot := spec.Name != nil && spec.Name.Name == "."
+				if !used[path] && !(anonymous && i == 0) && !(dot && used["."]) {
+					continue
+				}
+				gd.Specs = append(gd.Specs, dst.Clone(spec).(*dst.ImportSpec))
+			}
+		}
+		if len(gd.Specs) > 0 {
+			gd.Lparen = len(gd.Specs) > 1
+			gd.Rparen = gd.Lparen
+			for _, spec := range gd.Specs {
+				decs := spec.Decorations()
+				if gd.Lparen && decs.Before == dst.None {
+					decs.Before = dst.NewLine
+				}
+				if gd.Lparen && decs.After == dst.None {
+					decs.After = dst.NewLine
+				}
+				if !gd.Lparen {
+					decs.Before, decs.After = dst.None, dst.None
+				}
+			}
+			file.Decls = append(file.Decls, gd)
+		}
+
+		for j, decl := range parts[key] {
+			decl := dst.Clone(decl).(dst.Decl)
+			if j == 0 && len(file.Decls) > 0 {
+				decl.Decorations().Before = dst.EmptyLine
+			}
+			file.Decls = append(file.Decls, decl)
+		}
+
+		for _, decl := range file.Decls {
+			if gd, ok := decl.(*dst.GenDecl); ok && gd.Tok == token.IMPORT {
+				for _, spec := range gd.Specs {
+					file.Imports = append(file.Imports, spec.(*dst.ImportSpec))
+				}
+			}
+		}
+
+		out[key] = file
+	}
+	return out, nil
+}
+
+// usedImports returns the paths of the packages referred to by the qualified identifiers in
+// decls. Qualified identifiers that refer to the cgo "C" pseudo-package are returned as "C". If
+// decls refer to an identifier that may be from a dot-imported package (it has no Path, and isn't
+// declared in declared, in a local scope or in the universe scope), "." is returned. The local
+// scopes are analyzed, so this works without object resolution.
+func usedImports(af *ast.File, decls []dst.Decl, r resolver.DecoratorResolver, declared map[string]bool) (map[string]bool, error) {
+	used := map[string]bool{}
+	for _, decl := range decls {
+		dst.Inspect(decl, func(n dst.Node) bool {
+			switch n := n.(type) {
+			case *dst.Ident:
+				if n.Path != "" {
+					used[n.Path] = true
+				}
+			case *dst.SelectorExpr:
+				if x, ok := n.X.(*dst.Ident); ok && x.Path == "" && x.Name == "C" {
+					used["C"] = true
+				}
+			}
+			return true
+		})
+		v := newFreeVars(r)
+		v.af, v.paths = af, used
+		v.walk(decl, &scope{names: declared})
+		if v.err != nil {
+			return nil, v.err
+		}
+		for _, name := range v.names {
+			// the cgo "C" pseudo-package can't be used with a dot-imported C
+			if name != "C" || !used["C"] {
+				used["."] = true
+			}
+		}
+	}
+	return used, nil
+}
+
+// topLevelNames returns the names declared at the top level of f, excluding methods.
+func topLevelNames(f *dst.File) map[string]bool {
+	declared := map[string]bool{}
+	for _, decl := range f.Decls {
+		switch decl := decl.(type) {
+		case *dst.FuncDecl:
+			if decl.Recv == nil {
+				declared[decl.Name.Name] = true
+			}
+		case *dst.GenDecl:
+			for _, spec := range decl.Specs {
+				switch spec := spec.(type) {
+				case *dst.ValueSpec:
+					for _, name := range spec.Names {
+						declared[name.Name] = true
+					}
+				case *dst.TypeSpec:
+					declared[spec.Name.Name] = true
+				}
+			}
+		}
+	}
+	return declared
+}
+
+// importsFile returns a synthetic *ast.File with the package clause and the import specs of f,
+// excluding the cgo "C" import. This is used to resolve identifiers with a DecoratorResolver.
+func importsFile(f *dst.File) *ast.File {
+	af := &ast.File{Name: ast.NewIdent(f.Name.Name)}
+	importDecl := &ast.GenDecl{Tok: token.IMPORT}
+	af.Decls = []ast.Decl{importDecl}
+	for _, decl := range f.Decls {
+		gd, ok := decl.(*dst.GenDecl)
+		if !ok || gd.Tok != token.IMPORT || isCgoImport(gd) {
+			continue
+		}
+		for _, spec := range gd.Specs {
+			spec := spec.(*dst.ImportSpec)
+			is := &ast.ImportSpec{Path: &ast.BasicLit{Kind: token.STRING, Value: spec.Path.Value}}
+			if spec.Name != nil {
+				is.Name = ast.NewIdent(spec.Name.Name)
+			}
+			importDecl.Specs = append(importDecl.Specs, is)
+			af.Imports = append(af.Imports, is)
+		}
+	}
+	return af
+}
@@ -0,0 +1,191 @@
+package dstutil_test
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"testing"
+
+	"github.com/dave/dst"
+	"github.com/dave/dst/decorator"
+	"github.com/dave/dst/dstutil"
+)
+
+func TestSplitFile(t *testing.T) {
+	code := `// Package a does things.
+package a
+
+import (
+	"fmt"
+	"strings"
+
+	_ "embed"
+	z "github.com/a/b"
+)
+
+// A prints.
+func A() {
+	fmt.Println("a")
+}
+
+// B joins.
+func B(fmt string) string {
+	return strings.Join([]string{fmt}, z.Sep)
+}
+
+var C = 1
+`
+	expect := map[int]string{
+		0: `// Package a does things.
+package a
+
+import (
+	"fmt"
+
+	_ "embed"
+)
+
+// A prints.
+func A() {
+	fmt.Println("a")
+}
+`,
+		1: `package a
+
+import (
+	"strings"
+
+	z "github.com/a/b"
+)
+
+// B joins.
+func B(fmt string) string {
+	return strings.Join([]string{fmt}, z.Sep)
+}
+
+var C = 1
+`,
+	}
+	f, err := decorator.Parse(code)
+	if err != nil {
+		t.Fatal(err)
+	}
+	files, err := dstutil.SplitFile(f, func(decl dst.Decl) int {
+		if fd, ok := decl.(*dst.FuncDecl); ok && fd.Name.Name == "A" {
+			return 0
+		}
+		return 1
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != len(expect) {
+		t.Fatalf("expected %d files, found %d", len(expect), len(files))
+	}
+	for key, file := range files {
+		buf := &bytes.Buffer{}
+		if err := decorator.Fprint(buf, file); err != nil {
+			t.Fatal(err)
+		}
+		if buf.String() != expect[key] {
+			t.Errorf("%d:\nexpect: %q\nfound : %q", key, expect[key], buf.String())
+		}
+		if _, err := format.Source(buf.Bytes()); err != nil {
+			t.Errorf("%d: %v", key, err)
+		}
+	}
+
+	// the original file is not modified
+	buf := &bytes.Buffer{}
+	if err := decorator.Fprint(buf, f); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != code {
+		t.Errorf("\nexpect: %q\nfound : %q", code, buf.String())
+	}
+}
+
+func TestSplitFileDotImports(t *testing.T) {
+	code := `// Copyright 2026 The Authors.
+
+// Package a does things.
+package a
+
+import (
+	"fmt"
+	. "strings"
+)
+
+func A() {
+	fmt.Println(ToUpper("a"))
+}
+
+func B(s string) int {
+	for _, r := range s {
+		n := int(r)
+		return n + C
+	}
+	return len(s)
+}
+
+const C = 1
+`
+	expect := map[int]string{
+		0: `// Copyright 2026 The Authors.
+
+// Package a does things.
+package a
+
+import (
+	"fmt"
+	. "strings"
+)
+
+func A() {
+	fmt.Println(ToUpper("a"))
+}
+`,
+		1: `// Copyright 2026 The Authors.
+
+package a
+
+func B(s string) int {
+	for _, r := range s {
+		n := int(r)
+		return n + C
+	}
+	return len(s)
+}
+
+const C = 1
+`,
+	}
+	for _, skipObjects := range []bool{false, true} {
+		t.Run(fmt.Sprint("skip-objects-", skipObjects), func(t *testing.T) {
+			d := decorator.NewDecorator(nil)
+			d.SkipObjectResolution = skipObjects
+			f, err := d.Parse(code)
+			if err != nil {
+				t.Fatal(err)
+			}
+			files, err := dstutil.SplitFile(f, func(decl dst.Decl) int {
+				if fd, ok := decl.(*dst.FuncDecl); ok && fd.Name.Name == "A" {
+					return 0
+				}
+				return 1
+			}, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for key, file := range files {
+				buf := &bytes.Buffer{}
+				if err := decorator.Fprint(buf, file); err != nil {
+					t.Fatal(err)
+				}
+				if buf.String() != expect[key] {
+					t.Errorf("%d:\nexpect: %q\nfound : %q", key, expect[key], buf.String())
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,160 @@
+package dstutil
+
+import (
+	"go/token"
+	"strconv"
+	"strings"
+
+	"github.com/dave/dst"
+)
+
+// StructTag is a parsed struct field tag, as returned by ParseStructTag. The key:"value" pairs are
+// kept in order. Use SetStructTag to write it back to a field.
+type StructTag struct {
+	pairs   []tagPair
+	literal string // the original BasicLit value, used by SetStructTag if the tag is unchanged
+}
+
+type tagPair struct {
+	key, value string
+}
+
+// ParseStructTag parses the tag of a struct field. The second result is false if the field has no
+// tag, or if the tag is not in the conventional format of space-separated key:"value" pairs (see
+// reflect.StructTag).
+func ParseStructTag(field *dst.Field) (StructTag, bool) {
+	if field.Tag == nil || field.Tag.Kind != token.STRING {
+		return StructTag{}, false
+	}
+	s, err := strconv.Unquote(field.Tag.Value)
+	if err != nil {
+		return StructTag{}, false
+	}
+	pairs, ok := parseTag(s)
+	if !ok {
+		return StructTag{}, false
+	}
+	return StructTag{pairs: pairs, literal: field.Tag.Value}, true
+}
+
+// SetStructTag sets the tag of a struct field to tag. If tag is unchanged since it was returned
+// by ParseStructTag, the exact text of the original tag is kept. Otherwise the tag is rendered as a
+// raw string literal (or an interpreted string literal if a value contains a back quote). If tag
+// has no keys, the tag is removed from the field.
+func SetStructTag(field *dst.Field, tag StructTag) {
+	if len(tag.pairs) == 0 {
+		field.Tag = nil
+		return
+	}
+	value := tag.literal
+	if value == "" {
+		s := tag.String()
+		if strings.Contains(s, "`") {
+			value = strconv.Quote(s)
+		} else {
+			value = "`" + s + "`"
+		}
+	}
+	if field.Tag == nil {
+		field.Tag = &dst.BasicLit{Kind: token.STRING}
+	}
+	field.Tag.Kind = token.STRING
+	field.Tag.Value = value
+}
+
+// Get returns the value for key, and whether the key is present.
+func (t *StructTag) Get(key string) (string, bool) {
+	for _, p := range t.pairs {
+		if p.key == key {
+			return p.value, true
+		}
+	}
+	return "", false
+}
+
+// Set sets the value for key. An existing key keeps its position, and a new key is added at the
+// end.
+func (t *StructTag) Set(key, value string) {
+	t.literal = ""
+	for i, p := range t.pairs {
+		if p.key == key {
+			t.pairs[i].value = value
+			return
+		}
+	}
+	t.pairs = append(t.pairs, tagPair{key, value})
+}
+
+// Delete removes key.
+func (t *StructTag) Delete(key string) {
+	for i, p := range t.pairs {
+		if p.key == key {
+			t.literal = ""
+			t.pairs = append(t.pairs[:i:i], t.pairs[i+1:]...)
+			return
+		}
+	}
+}
+
+// Keys returns the keys in order.
+func (t *StructTag) Keys() []string {
+	var keys []string
+	for _, p := range t.pairs {
+		keys = append(keys, p.key)
+	}
+	return keys
+}
+
+// String returns the tag in the conventional format, e.g. `json:"a,omitempty" xml:"a"`.
+func (t *StructTag) String() string {
+	parts := make([]string, len(t.pairs))
+	for i, p := range t.pairs {
+		parts[i] = p.key + ":" + strconv.Quote(p.value)
+	}
+	return strings.Join(parts, " ")
+}
+
+// parseTag parses a tag in the conventional format. The parsing follows reflect.StructTag.Lookup.
+func parseTag(tag string) ([]tagPair, bool) {
+	var pairs []tagPair
+	for {
+		// skip leading space
+		i := 0
+		for i < len(tag) && tag[i] == ' ' {
+			i++
+		}
+		tag = tag[i:]
+		if tag == "" {
+			return pairs, true
+		}
+
+		// scan to colon. a space, a quote or a control character is a syntax error.
+		i = 0
+		for i < len(tag) && tag[i] > ' ' && tag[i] != ':' && tag[i] != '"' && tag[i] != 0x7f {
+			i++
+		}
+		if i == 0 || i+1 >= len(tag) || tag[i] != ':' || tag[i+1] != '"' {
+			return nil, false
+		}
+		key := tag[:i]
+		tag = tag[i+1:]
+
+		// scan quoted string to find value
+		i = 1
+		for i < len(tag) && tag[i] != '"' {
+			if tag[i] == '\\' {
+				i++
+			}
+			i++
+		}
+		if i >= len(tag) {
+			return nil, false
+		}
+		value, err := strconv.Unquote(tag[:i+1])
+		if err != nil {
+			return nil, false
+		}
+		tag = tag[i+1:]
+		pairs = append(pairs, tagPair{key, value})
+	}
+}
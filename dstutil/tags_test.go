@@ -0,0 +1,66 @@
+package dstutil_test
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/dave/dst"
+	"github.com/dave/dst/decorator"
+	"github.com/dave/dst/dstutil"
+)
+
+func TestStructTag(t *testing.T) {
+	f, err := decorator.Parse("package a\n\ntype T struct {\n\tA int `db:\"a\"  xml:\"a,attr\"`\n\tB int \"db:\\\"b\\\"\"\n\tC int\n\tD int `bad`\n}\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fields := f.Decls[0].(*dst.GenDecl).Specs[0].(*dst.TypeSpec).Type.(*dst.StructType).Fields.List
+
+	// an unchanged tag keeps its exact text
+	tag, ok := dstutil.ParseStructTag(fields[0])
+	if !ok {
+		t.Fatal("expected tag")
+	}
+	if keys := tag.Keys(); !reflect.DeepEqual(keys, []string{"db", "xml"}) {
+		t.Errorf("unexpected keys %q", keys)
+	}
+	if v, ok := tag.Get("xml"); !ok || v != "a,attr" {
+		t.Errorf("unexpected value %q", v)
+	}
+	dstutil.SetStructTag(fields[0], tag)
+	if fields[0].Tag.Value != "`db:\"a\"  xml:\"a,attr\"`" {
+		t.Errorf("unexpected tag %s", fields[0].Tag.Value)
+	}
+
+	tag.Set("json", "a,omitempty")
+	tag.Set("db", "aa")
+	dstutil.SetStructTag(fields[0], tag)
+
+	tag, ok = dstutil.ParseStructTag(fields[1])
+	if !ok {
+		t.Fatal("expected tag")
+	}
+	tag.Delete("db")
+	dstutil.SetStructTag(fields[1], tag)
+
+	var empty dstutil.StructTag
+	if _, ok := dstutil.ParseStructTag(fields[2]); ok {
+		t.Error("expected no tag")
+	}
+	empty.Set("json", "c")
+	dstutil.SetStructTag(fields[2], empty)
+
+	if _, ok := dstutil.ParseStructTag(fields[3]); ok {
+		t.Error("expected malformed tag")
+	}
+
+	buf := &bytes.Buffer{}
+	if err := decorator.Fprint(buf, f); err != nil {
+		t.Fatal(err)
+	}
+	expect := "package a\n\ntype T struct {\n\tA int `db:\"aa\" xml:\"a,attr\" json:\"a,omitempty\"`\n\tB int\n\tC int `json:\"c\"`\n\tD int `bad`\n}\n"
+	if buf.String() != expect {
+		t.Errorf("\nexpect: %q\nfound : %q", expect, buf.String())
+	}
+}
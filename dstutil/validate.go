@@ -0,0 +1,81 @@
+package dstutil
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/dave/dst"
+)
+
+// DecorationError describes a malformed decoration found by ValidateDecorations.
+type DecorationError struct {
+	DecorationLocation
+	Path       string // path of the node from the root (e.g. "File.Decls[1].Body.List[0]")
+	Decoration string // the malformed decoration
+	Reason     string // why the decoration is malformed
+}
+
+func (e *DecorationError) Error() string {
+	return fmt.Sprintf("%s %s[%d]: %s: %q", e.Path, e.Name, e.Index, e.Reason, e.Decoration)
+}
+
+// ValidateDecorations returns a *DecorationError for each malformed decoration in root (including
+// the children of root), in depth-first order. A decoration must be a line break ("\n"), a line
+// comment without a line break, or a single block comment. Malformed decorations are usually the
+// result of editing decorations by hand, and cause the printer to produce invalid code or panic.
+func ValidateDecorations(root dst.Node) []error {
+	var errs []error
+	var path []string
+	Apply(root, func(c *Cursor) bool {
+		n := c.Node()
+		switch {
+		case len(path) == 0:
+			path = append(path, reflect.Indirect(reflect.ValueOf(n)).Type().Name())
+		case c.Index() >= 0:
+			path = append(path, fmt.Sprintf("%s.%s[%d]", path[len(path)-1], c.Name(), c.Index()))
+		default:
+			path = append(path, path[len(path)-1]+"."+c.Name())
+		}
+		_, _, points := decorations(n)
+		for _, point := range points {
+			for i, d := range point.Decs {
+				if reason := malformedDecoration(d); reason != "" {
+					errs = append(errs, &DecorationError{
+						DecorationLocation: DecorationLocation{Node: n, Name: point.Name, Index: i},
+						Path:               path[len(path)-1],
+						Decoration:         d,
+						Reason:             reason,
+					})
+				}
+			}
+		}
+		return true
+	}, func(c *Cursor) bool {
+		path = path[:len(path)-1]
+		return true
+	})
+	return errs
+}
+
+// malformedDecoration returns the reason d is malformed, or an empty string if d is valid.
+func malformedDecoration(d string) string {
+	switch {
+	case d == "\n":
+		return ""
+	case strings.HasPrefix(d, "//"):
+		if strings.Contains(d, "\n") {
+			return "line comment contains a line break"
+		}
+	case strings.HasPrefix(d, "/*"):
+		if len(d) < 4 || !strings.HasSuffix(d, "*/") {
+			return "block comment is not terminated"
+		}
+		if strings.Contains(d[2:len(d)-2], "*/") {
+			return "block comment contains more than one comment"
+		}
+	default:
+		return "not a comment or a line break"
+	}
+	return ""
+}
@@ -0,0 +1,53 @@
+package dstutil_test
+
+import (
+	"testing"
+
+	"github.com/dave/dst"
+	"github.com/dave/dst/decorator"
+	"github.com/dave/dst/dstutil"
+)
+
+func TestValidateDecorations(t *testing.T) {
+	f, err := decorator.Parse(`// a
+package a
+
+func b() {
+	c() // d
+
+	/* e */
+	f()
+}
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if errs := dstutil.ValidateDecorations(f); len(errs) != 0 {
+		t.Fatalf("expected no errors, found %v", errs)
+	}
+
+	body := f.Decls[0].(*dst.FuncDecl).Body
+	body.List[0].Decorations().End.Replace("// d\n")
+	body.List[1].Decorations().Start.Replace("/* e")
+	call := body.List[1].(*dst.ExprStmt).X.(*dst.CallExpr)
+	call.Decs.Lparen.Append("/* g */ /* h */", "i")
+
+	errs := dstutil.ValidateDecorations(f)
+	expect := []string{
+		`File.Decls[0].Body.List[0] End[0]: line comment contains a line break: "// d\n"`,
+		`File.Decls[0].Body.List[1] Start[0]: block comment is not terminated: "/* e"`,
+		`File.Decls[0].Body.List[1].X Lparen[0]: block comment contains more than one comment: "/* g */ /* h */"`,
+		`File.Decls[0].Body.List[1].X Lparen[1]: not a comment or a line break: "i"`,
+	}
+	if len(errs) != len(expect) {
+		t.Fatalf("expected %d errors, found %v", len(expect), errs)
+	}
+	for i, err := range errs {
+		if err.Error() != expect[i] {
+			t.Errorf("\nexpect: %s\nfound : %s", expect[i], err)
+		}
+	}
+	if derr := errs[1].(*dstutil.DecorationError); derr.Node != body.List[1] || derr.Name != "Start" {
+		t.Errorf("unexpected location %#v", derr.DecorationLocation)
+	}
+}
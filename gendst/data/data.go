@@ -128,6 +128,7 @@ var Info = map[string][]Part{
 			LengthField: Field{"Length"},
 			FromField:   Field{"From"},
 			ToField:     Field{"To"},
+			SourceField: Field{"Source"},
 		},
 		Decoration{
 			Name: "End",
@@ -1051,6 +1052,7 @@ var Info = map[string][]Part{
 			LengthField: Field{"Length"},
 			FromField:   Field{"From"},
 			ToField:     Field{"To"},
+			SourceField: Field{"Source"},
 		},
 		Decoration{
 			Name: "End",
@@ -1915,11 +1917,11 @@ var Info = map[string][]Part{
 		Token{
 			Name:   "Assign",
 			Token:  Basic{jen.Qual("go/token", "ASSIGN")},
-			Exists: Expr(func(n *jen.Statement) *jen.Statement { return n.Dot("Values").Op("!=").Nil() }),
+			Exists: Expr(func(n *jen.Statement) *jen.Statement { return jen.Len(n.Dot("Values")).Op(">").Lit(0) }),
 		},
 		Decoration{
 			Name: "Assign",
-			Use:  Expr(func(n *jen.Statement) *jen.Statement { return n.Dot("Values").Op("!=").Nil() }),
+			Use:  Expr(func(n *jen.Statement) *jen.Statement { return jen.Len(n.Dot("Values")).Op(">").Lit(0) }),
 		},
 		List{
 			Name:      "Values",
@@ -1995,6 +1997,7 @@ var Info = map[string][]Part{
 			LengthField: Field{"Length"},
 			FromField:   Field{"From"},
 			ToField:     Field{"To"},
+			SourceField: Field{"Source"},
 		},
 		Decoration{
 			Name: "End",
@@ -2403,6 +2406,7 @@ type Bad struct {
 	Length             Code
 	LengthField        FieldSpec
 	FromField, ToField FieldSpec
+	SourceField        FieldSpec // optional field for the original source of the bad node
 }
 
 // Value that must be copied from ast.Node to dst.Node but doesn't result in anything rendered to the output.
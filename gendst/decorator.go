@@ -125,6 +125,9 @@ func generateDecorator(names []string) error {
 						case data.Bad:
 							g.Line().Comment("Bad")
 							g.Add(frag.LengthField.Get("out")).Op("=").Add(frag.Length.Get("n", true))
+							if frag.SourceField != nil {
+								g.Add(frag.SourceField.Get("out")).Op("=").Id("f").Dot("badSource").Call(frag.FromField.Get("n"), frag.ToField.Get("n"))
+							}
 						case data.Value:
 							g.Line().Commentf("Value: %s", frag.Name)
 							if frag.Value != nil {
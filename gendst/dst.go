@@ -41,6 +41,75 @@ func generateDst(names []string) error {
 	return f.Save("./decorations-node-generated.go")
 }
 
+func generateDstWalkDecorations(names []string) error {
+
+	f := NewFilePathName(DSTPATH, "dst")
+
+	f.Comment("notest")
+	f.Line()
+
+	f.Comment("walkDecorations calls fn for each decoration attachment point of n and its children, in the")
+	f.Comment("order they are rendered by the restorer.")
+	f.Func().Id("walkDecorations").Params(
+		Id("n").Id("Node"),
+		Id("fn").Func().Params(Id("n").Id("Node"), Id("name").String(), Id("decs").Id("Decorations")),
+	).BlockFunc(func(g *Group) {
+		g.Switch(Id("n").Op(":=").Id("n").Assert(Id("type"))).BlockFunc(func(g *Group) {
+			for _, nodeName := range names {
+				g.Case(Op("*").Id(nodeName)).BlockFunc(func(g *Group) {
+					for _, frag := range data.Info[nodeName] {
+						switch frag := frag.(type) {
+						case data.Decoration:
+							g.Id("fn").Call(Id("n"), Lit(frag.Name), Id("n").Dot("Decs").Dot(frag.Name))
+						case data.SpecialDecoration:
+							inner := frag.Decs.(data.InnerField)
+							g.Id("fn").Call(Id("n").Dot(inner.Inner), Lit(frag.Name), frag.Decs.Get("n").Dot(frag.Name))
+						case data.Node:
+							g.If(frag.Field.Get("n").Op("!=").Nil()).Block(
+								Id("walkDecorations").Call(frag.Field.Get("n"), Id("fn")),
+							)
+						case data.List:
+							if frag.NoRestore {
+								continue
+							}
+							g.For(List(Id("_"), Id("v")).Op(":=").Range().Add(frag.Field.Get("n"))).Block(
+								Id("walkDecorations").Call(Id("v"), Id("fn")),
+							)
+						}
+					}
+				})
+			}
+		})
+	})
+
+	f.Comment("decorationSlots returns the named decoration attachment points of n, excluding Start and End.")
+	f.Func().Id("decorationSlots").Params(Id("n").Id("Node")).Map(String()).Op("*").Id("Decorations").BlockFunc(func(g *Group) {
+		g.Switch(Id("n").Op(":=").Id("n").Assert(Id("type"))).BlockFunc(func(g *Group) {
+			for _, nodeName := range names {
+				var slots []data.Decoration
+				for _, frag := range data.Info[nodeName] {
+					if frag, ok := frag.(data.Decoration); ok && frag.Name != "Start" && frag.Name != "End" {
+						slots = append(slots, frag)
+					}
+				}
+				if len(slots) == 0 {
+					continue
+				}
+				g.Case(Op("*").Id(nodeName)).Block(
+					Return(Map(String()).Op("*").Id("Decorations").Values(DictFunc(func(d Dict) {
+						for _, frag := range slots {
+							d[Lit(frag.Name)] = Op("&").Id("n").Dot("Decs").Dot(frag.Name)
+						}
+					}))),
+				)
+			}
+		})
+		g.Return(Nil())
+	})
+
+	return f.Save("./decorations-walk-generated.go")
+}
+
 func generateDstDecs(names []string) error {
 
 	path := "github.com/dave/dst/gendst/data"
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/dave/dst/gendst/data"
+	. "github.com/dave/jennifer/jen"
+)
+
+// notest
+
+func generateHash(names []string) error {
+
+	f := NewFilePathName(DSTPATH, "dst")
+	f.Comment("hash writes the node and its children to the hasher.")
+	f.Func().Params(Id("h").Op("*").Id("hasher")).Id("hash").Params(Id("n").Id("Node")).BlockFunc(func(g *Group) {
+		g.Switch(Id("n").Op(":=").Id("n").Assert(Id("type"))).BlockFunc(func(g *Group) {
+			for _, nodeName := range names {
+				g.Case(Op("*").Qual(DSTPATH, nodeName)).BlockFunc(func(g *Group) {
+					g.Id("h").Dot("string").Call(Lit(nodeName))
+
+					if nodeName != "Package" {
+						g.Id("h").Dot("space").Call(Id("n").Dot("Decs").Dot("Before"))
+					}
+
+					for _, frag := range data.Info[nodeName] {
+						switch frag := frag.(type) {
+						case data.Decoration:
+							g.Id("h").Dot("decorations").Call(Id("n").Dot("Decs").Dot(frag.Name))
+						case data.Token:
+							if frag.NoPosField != nil {
+								g.Id("h").Dot("value").Call(frag.NoPosField.Get("n"))
+							}
+							if frag.TokenField != nil {
+								g.Id("h").Dot("value").Call(frag.TokenField.Get("n"))
+							}
+							if frag.ExistsField != nil {
+								g.Id("h").Dot("value").Call(frag.ExistsField.Get("n"))
+							}
+						case data.String:
+							g.Id("h").Dot("string").Call(frag.ValueField.Get("n"))
+						case data.Node:
+							g.Id("h").Dot("node").Call(frag.Field.Get("n").Op("!=").Nil())
+							g.If(frag.Field.Get("n").Op("!=").Nil()).Block(
+								Id("h").Dot("hash").Call(frag.Field.Get("n")),
+							)
+						case data.List:
+							g.Id("h").Dot("value").Call(Len(frag.Field.Get("n")))
+							g.For(List(Id("_"), Id("v")).Op(":=").Range().Add(frag.Field.Get("n"))).Block(
+								Id("h").Dot("hash").Call(Id("v")),
+							)
+						case data.Map:
+							if frag.Elem.TypeName() != "File" {
+								// objects are not part of the structure
+								continue
+							}
+							g.Id("h").Dot("value").Call(Len(frag.Field.Get("n")))
+							g.For(List(Id("_"), Id("k")).Op(":=").Range().Id("sortedFileNames").Call(frag.Field.Get("n"))).Block(
+								Id("h").Dot("string").Call(Id("k")),
+								Id("h").Dot("hash").Call(frag.Field.Get("n").Index(Id("k"))),
+							)
+						case data.Value:
+							g.Id("h").Dot("value").Call(frag.Field.Get("n"))
+						case data.Bad:
+							g.Id("h").Dot("value").Call(frag.LengthField.Get("n"))
+							if frag.SourceField != nil {
+								g.Id("h").Dot("string").Call(frag.SourceField.Get("n"))
+							}
+						case data.PathDecoration:
+							g.Id("h").Dot("string").Call(frag.Field.Get("n"))
+						case data.Init, data.Scope, data.Object, data.SpecialDecoration:
+							// ignore
+						default:
+							panic(fmt.Sprintf("unknown fragment type %T", frag))
+						}
+					}
+
+					if nodeName != "Package" {
+						g.Id("h").Dot("space").Call(Id("n").Dot("Decs").Dot("After"))
+					}
+				})
+			}
+			g.Default().Block(
+				Panic(Qual("fmt", "Sprintf").Call(Lit("%T"), Id("n"))),
+			)
+		})
+	})
+
+	return f.Save("./hash-generated.go")
+}
@@ -27,6 +27,9 @@ func run() error {
 	if err := generateDstDecs(names); err != nil {
 		return err
 	}
+	if err := generateDstWalkDecorations(names); err != nil {
+		return err
+	}
 	if err := generateFragger(names); err != nil {
 		return err
 	}
@@ -42,5 +45,11 @@ func run() error {
 	if err := generateClone(names); err != nil {
 		return err
 	}
+	if err := generateHash(names); err != nil {
+		return err
+	}
+	if err := generateStdlib(); err != nil {
+		return err
+	}
 	return nil
 }
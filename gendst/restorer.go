@@ -47,6 +47,24 @@ func generateRestorer(names []string) error {
 						)
 						g.Line()
 					}
+					if nodeName == "BadExpr" || nodeName == "BadStmt" {
+						g.Line()
+						g.Comment("Special case for bad nodes - restore the original source if available and the")
+						g.Comment("file is being printed")
+						g.If(Id("n").Dot("Source").Op("!=").Lit("").Op("&&").Id("r").Dot("printing")).Block(
+							Return(Id("r").Dot("restoreBadSource").Call(Id("n"), Id("n").Dot("Source"))),
+						)
+						g.Line()
+					}
+					if nodeName == "BadDecl" {
+						g.Line()
+						g.Comment("Special case for bad declarations - restore the original source if available and")
+						g.Comment("the file is being printed")
+						g.If(Id("n").Dot("Source").Op("!=").Lit("").Op("&&").Id("r").Dot("printing")).Block(
+							Return(Id("r").Dot("restoreBadDeclSource").Call(Id("n"))),
+						)
+						g.Line()
+					}
 					g.Id("out").Op(":=").Op("&").Qual("go/ast", nodeName).Values()
 					g.Id("r").Dot("Ast").Dot("Nodes").Index(Id("n")).Op("=").Id("out")
 					g.Id("r").Dot("Dst").Dot("Nodes").Index(Id("out")).Op("=").Id("n")
@@ -102,6 +120,14 @@ func generateRestorer(names []string) error {
 							if frag.PositionField != nil {
 								g.Add(frag.PositionField.Get("out")).Op("=").Id("r").Dot("cursor")
 							}
+							if nodeName == "Ident" {
+								// Special case for *dst.Ident - the name may be changed by IdentFormatter
+								g.Add(frag.ValueField.Get("out")).Op("=").Id("r").Dot("identName").Call(Id("n"))
+								g.Id("r").Dot("cursor").Op("+=").Qual("go/token", "Pos").Parens(
+									Len(frag.ValueField.Get("out")),
+								)
+								continue
+							}
 							g.Add(frag.ValueField.Get("out")).Op("=").Add(frag.ValueField.Get("n"))
 							g.Id("r").Dot("cursor").Op("+=").Qual("go/token", "Pos").Parens(
 								Len(frag.ValueField.Get("n")),
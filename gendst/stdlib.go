@@ -0,0 +1,156 @@
+package main
+
+import (
+	"go/ast"
+	"go/build/constraint"
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+
+	. "github.com/dave/jennifer/jen"
+)
+
+// notest
+
+const STDLIBPATH = "github.com/dave/dst/decorator/resolver/stdlib"
+
+func generateStdlib() error {
+
+	packages, err := stdlibPackages()
+	if err != nil {
+		return err
+	}
+	var paths []string
+	for path := range packages {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	f := NewFilePathName(STDLIBPATH, "stdlib")
+	f.Comment("packages is a map of package path -> package name for the importable packages of the")
+	f.Comment("standard library.")
+	f.Var().Id("packages").Op("=").Map(String()).String().Values(DictFunc(func(d Dict) {
+		for _, path := range paths {
+			d[Lit(path)] = Lit(packages[path])
+		}
+	}))
+	return f.Save("./decorator/resolver/stdlib/packages-generated.go")
+}
+
+// stdlibPackages lists the importable packages in GOROOT (commands, internal and vendored packages
+// are excluded). GOROOT/src is walked rather than using "go list std", which only lists the
+// packages for the current GOOS and GOARCH (e.g. syscall/js would be missing), and packages that
+// require a GOEXPERIMENT.
+func stdlibPackages() (map[string]string, error) {
+	src := filepath.Join(runtime.GOROOT(), "src")
+	packages := map[string]string{}
+	err := filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		switch {
+		case rel == ".":
+			return nil
+		case rel == "cmd", rel == "vendor", info.Name() == "testdata", isInternal(rel):
+			return filepath.SkipDir
+		case strings.HasPrefix(info.Name(), "_"), strings.HasPrefix(info.Name(), "."):
+			// ignored by the go tool
+			return filepath.SkipDir
+		case rel == "builtin":
+			// documentation only, not importable
+			return nil
+		}
+		name, err := packageName(path)
+		if err != nil {
+			return err
+		}
+		if name != "" {
+			packages[rel] = name
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return packages, nil
+}
+
+// packageName returns the name of the package in dir, or an empty string if dir has no Go files
+// for any platform. Test files and files with the "ignore" build tag (e.g. generators) are
+// excluded.
+func packageName(dir string) (string, error) {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return "", err
+	}
+	for _, file := range files {
+		if file.IsDir() || !strings.HasSuffix(file.Name(), ".go") || strings.HasSuffix(file.Name(), "_test.go") {
+			continue
+		}
+		f, err := parser.ParseFile(token.NewFileSet(), filepath.Join(dir, file.Name()), nil, parser.PackageClauseOnly|parser.ParseComments)
+		if err != nil {
+			return "", err
+		}
+		if ignored(f) {
+			continue
+		}
+		return f.Name.Name, nil
+	}
+	return "", nil
+}
+
+// ignored returns true if the build constraints of f use the "ignore" tag.
+func ignored(f *ast.File) bool {
+	for _, group := range f.Comments {
+		if group.Pos() >= f.Package {
+			break
+		}
+		for _, c := range group.List {
+			if !constraint.IsGoBuild(c.Text) && !constraint.IsPlusBuild(c.Text) {
+				continue
+			}
+			expr, err := constraint.Parse(c.Text)
+			if err == nil && hasTag(expr, "ignore") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// hasTag returns true if the build constraint expr uses tag.
+func hasTag(expr constraint.Expr, tag string) bool {
+	switch expr := expr.(type) {
+	case *constraint.TagExpr:
+		return expr.Tag == tag
+	case *constraint.NotExpr:
+		return hasTag(expr.X, tag)
+	case *constraint.AndExpr:
+		return hasTag(expr.X, tag) || hasTag(expr.Y, tag)
+	case *constraint.OrExpr:
+		return hasTag(expr.X, tag) || hasTag(expr.Y, tag)
+	}
+	return false
+}
+
+func isInternal(path string) bool {
+	for _, part := range strings.Split(path, "/") {
+		if part == "internal" {
+			return true
+		}
+	}
+	return false
+}
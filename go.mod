@@ -13,4 +13,4 @@ require (
 	gopkg.in/src-d/go-billy.v4 v4.3.0
 )
 
-go 1.14
+go 1.16
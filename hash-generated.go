@@ -0,0 +1,811 @@
+package dst
+
+import "fmt"
+
+// hash writes the node and its children to the hasher.
+func (h *hasher) hash(n Node) {
+	switch n := n.(type) {
+	case *ArrayType:
+		h.string("ArrayType")
+		h.space(n.Decs.Before)
+		h.decorations(n.Decs.Start)
+		h.decorations(n.Decs.Lbrack)
+		h.node(n.Len != nil)
+		if n.Len != nil {
+			h.hash(n.Len)
+		}
+		h.decorations(n.Decs.Len)
+		h.node(n.Elt != nil)
+		if n.Elt != nil {
+			h.hash(n.Elt)
+		}
+		h.decorations(n.Decs.End)
+		h.space(n.Decs.After)
+	case *AssignStmt:
+		h.string("AssignStmt")
+		h.space(n.Decs.Before)
+		h.decorations(n.Decs.Start)
+		h.value(len(n.Lhs))
+		for _, v := range n.Lhs {
+			h.hash(v)
+		}
+		h.value(n.Tok)
+		h.decorations(n.Decs.Tok)
+		h.value(len(n.Rhs))
+		for _, v := range n.Rhs {
+			h.hash(v)
+		}
+		h.decorations(n.Decs.End)
+		h.space(n.Decs.After)
+	case *BadDecl:
+		h.string("BadDecl")
+		h.space(n.Decs.Before)
+		h.decorations(n.Decs.Start)
+		h.value(n.Length)
+		h.string(n.Source)
+		h.decorations(n.Decs.End)
+		h.space(n.Decs.After)
+	case *BadExpr:
+		h.string("BadExpr")
+		h.space(n.Decs.Before)
+		h.decorations(n.Decs.Start)
+		h.value(n.Length)
+		h.string(n.Source)
+		h.decorations(n.Decs.End)
+		h.space(n.Decs.After)
+	case *BadStmt:
+		h.string("BadStmt")
+		h.space(n.Decs.Before)
+		h.decorations(n.Decs.Start)
+		h.value(n.Length)
+		h.string(n.Source)
+		h.decorations(n.Decs.End)
+		h.space(n.Decs.After)
+	case *BasicLit:
+		h.string("BasicLit")
+		h.space(n.Decs.Before)
+		h.decorations(n.Decs.Start)
+		h.string(n.Value)
+		h.decorations(n.Decs.End)
+		h.value(n.Kind)
+		h.space(n.Decs.After)
+	case *BinaryExpr:
+		h.string("BinaryExpr")
+		h.space(n.Decs.Before)
+		h.decorations(n.Decs.Start)
+		h.node(n.X != nil)
+		if n.X != nil {
+			h.hash(n.X)
+		}
+		h.decorations(n.Decs.X)
+		h.value(n.Op)
+		h.decorations(n.Decs.Op)
+		h.node(n.Y != nil)
+		if n.Y != nil {
+			h.hash(n.Y)
+		}
+		h.decorations(n.Decs.End)
+		h.space(n.Decs.After)
+	case *BlockStmt:
+		h.string("BlockStmt")
+		h.space(n.Decs.Before)
+		h.decorations(n.Decs.Start)
+		h.decorations(n.Decs.Lbrace)
+		h.value(len(n.List))
+		for _, v := range n.List {
+			h.hash(v)
+		}
+		h.value(n.RbraceHasNoPos)
+		h.decorations(n.Decs.End)
+		h.space(n.Decs.After)
+	case *BranchStmt:
+		h.string("BranchStmt")
+		h.space(n.Decs.Before)
+		h.decorations(n.Decs.Start)
+		h.value(n.Tok)
+		h.decorations(n.Decs.Tok)
+		h.node(n.Label != nil)
+		if n.Label != nil {
+			h.hash(n.Label)
+		}
+		h.decorations(n.Decs.End)
+		h.space(n.Decs.After)
+	case *CallExpr:
+		h.string("CallExpr")
+		h.space(n.Decs.Before)
+		h.decorations(n.Decs.Start)
+		h.node(n.Fun != nil)
+		if n.Fun != nil {
+			h.hash(n.Fun)
+		}
+		h.decorations(n.Decs.Fun)
+		h.decorations(n.Decs.Lparen)
+		h.value(len(n.Args))
+		for _, v := range n.Args {
+			h.hash(v)
+		}
+		h.value(n.Ellipsis)
+		h.decorations(n.Decs.Ellipsis)
+		h.decorations(n.Decs.End)
+		h.space(n.Decs.After)
+	case *CaseClause:
+		h.string("CaseClause")
+		h.space(n.Decs.Before)
+		h.decorations(n.Decs.Start)
+		h.decorations(n.Decs.Case)
+		h.value(len(n.List))
+		for _, v := range n.List {
+			h.hash(v)
+		}
+		h.decorations(n.Decs.Colon)
+		h.value(len(n.Body))
+		for _, v := range n.Body {
+			h.hash(v)
+		}
+		h.decorations(n.Decs.End)
+		h.space(n.Decs.After)
+	case *ChanType:
+		h.string("ChanType")
+		h.space(n.Decs.Before)
+		h.decorations(n.Decs.Start)
+		h.decorations(n.Decs.Begin)
+		h.decorations(n.Decs.Arrow)
+		h.node(n.Value != nil)
+		if n.Value != nil {
+			h.hash(n.Value)
+		}
+		h.decorations(n.Decs.End)
+		h.value(n.Dir)
+		h.space(n.Decs.After)
+	case *CommClause:
+		h.string("CommClause")
+		h.space(n.Decs.Before)
+		h.decorations(n.Decs.Start)
+		h.decorations(n.Decs.Case)
+		h.node(n.Comm != nil)
+		if n.Comm != nil {
+			h.hash(n.Comm)
+		}
+		h.decorations(n.Decs.Comm)
+		h.decorations(n.Decs.Colon)
+		h.value(len(n.Body))
+		for _, v := range n.Body {
+			h.hash(v)
+		}
+		h.decorations(n.Decs.End)
+		h.space(n.Decs.After)
+	case *CompositeLit:
+		h.string("CompositeLit")
+		h.space(n.Decs.Before)
+		h.decorations(n.Decs.Start)
+		h.node(n.Type != nil)
+		if n.Type != nil {
+			h.hash(n.Type)
+		}
+		h.decorations(n.Decs.Type)
+		h.decorations(n.Decs.Lbrace)
+		h.value(len(n.Elts))
+		for _, v := range n.Elts {
+			h.hash(v)
+		}
+		h.decorations(n.Decs.End)
+		h.value(n.Incomplete)
+		h.space(n.Decs.After)
+	case *DeclStmt:
+		h.string("DeclStmt")
+		h.space(n.Decs.Before)
+		h.decorations(n.Decs.Start)
+		h.node(n.Decl != nil)
+		if n.Decl != nil {
+			h.hash(n.Decl)
+		}
+		h.decorations(n.Decs.End)
+		h.space(n.Decs.After)
+	case *DeferStmt:
+		h.string("DeferStmt")
+		h.space(n.Decs.Before)
+		h.decorations(n.Decs.Start)
+		h.decorations(n.Decs.Defer)
+		h.node(n.Call != nil)
+		if n.Call != nil {
+			h.hash(n.Call)
+		}
+		h.decorations(n.Decs.End)
+		h.space(n.Decs.After)
+	case *Ellipsis:
+		h.string("Ellipsis")
+		h.space(n.Decs.Before)
+		h.decorations(n.Decs.Start)
+		h.decorations(n.Decs.Ellipsis)
+		h.node(n.Elt != nil)
+		if n.Elt != nil {
+			h.hash(n.Elt)
+		}
+		h.decorations(n.Decs.End)
+		h.space(n.Decs.After)
+	case *EmptyStmt:
+		h.string("EmptyStmt")
+		h.space(n.Decs.Before)
+		h.decorations(n.Decs.Start)
+		h.decorations(n.Decs.End)
+		h.value(n.Implicit)
+		h.space(n.Decs.After)
+	case *ExprStmt:
+		h.string("ExprStmt")
+		h.space(n.Decs.Before)
+		h.decorations(n.Decs.Start)
+		h.node(n.X != nil)
+		if n.X != nil {
+			h.hash(n.X)
+		}
+		h.decorations(n.Decs.End)
+		h.space(n.Decs.After)
+	case *Field:
+		h.string("Field")
+		h.space(n.Decs.Before)
+		h.decorations(n.Decs.Start)
+		h.value(len(n.Names))
+		for _, v := range n.Names {
+			h.hash(v)
+		}
+		h.node(n.Type != nil)
+		if n.Type != nil {
+			h.hash(n.Type)
+		}
+		h.decorations(n.Decs.Type)
+		h.node(n.Tag != nil)
+		if n.Tag != nil {
+			h.hash(n.Tag)
+		}
+		h.decorations(n.Decs.End)
+		h.space(n.Decs.After)
+	case *FieldList:
+		h.string("FieldList")
+		h.space(n.Decs.Before)
+		h.decorations(n.Decs.Start)
+		h.value(n.Opening)
+		h.decorations(n.Decs.Opening)
+		h.value(len(n.List))
+		for _, v := range n.List {
+			h.hash(v)
+		}
+		h.value(n.Closing)
+		h.decorations(n.Decs.End)
+		h.space(n.Decs.After)
+	case *File:
+		h.string("File")
+		h.space(n.Decs.Before)
+		h.decorations(n.Decs.Start)
+		h.decorations(n.Decs.Package)
+		h.node(n.Name != nil)
+		if n.Name != nil {
+			h.hash(n.Name)
+		}
+		h.decorations(n.Decs.Name)
+		h.value(len(n.Decls))
+		for _, v := range n.Decls {
+			h.hash(v)
+		}
+		h.decorations(n.Decs.End)
+		h.value(len(n.Imports))
+		for _, v := range n.Imports {
+			h.hash(v)
+		}
+		h.space(n.Decs.After)
+	case *ForStmt:
+		h.string("ForStmt")
+		h.space(n.Decs.Before)
+		h.decorations(n.Decs.Start)
+		h.decorations(n.Decs.For)
+		h.node(n.Init != nil)
+		if n.Init != nil {
+			h.hash(n.Init)
+		}
+		h.decorations(n.Decs.Init)
+		h.node(n.Cond != nil)
+		if n.Cond != nil {
+			h.hash(n.Cond)
+		}
+		h.decorations(n.Decs.Cond)
+		h.node(n.Post != nil)
+		if n.Post != nil {
+			h.hash(n.Post)
+		}
+		h.decorations(n.Decs.Post)
+		h.node(n.Body != nil)
+		if n.Body != nil {
+			h.hash(n.Body)
+		}
+		h.decorations(n.Decs.End)
+		h.space(n.Decs.After)
+	case *FuncDecl:
+		h.string("FuncDecl")
+		h.space(n.Decs.Before)
+		h.decorations(n.Decs.Start)
+		h.value(n.Type.Func)
+		h.decorations(n.Decs.Func)
+		h.node(n.Recv != nil)
+		if n.Recv != nil {
+			h.hash(n.Recv)
+		}
+		h.decorations(n.Decs.Recv)
+		h.node(n.Name != nil)
+		if n.Name != nil {
+			h.hash(n.Name)
+		}
+		h.decorations(n.Decs.Name)
+		h.node(n.Type.Params != nil)
+		if n.Type.Params != nil {
+			h.hash(n.Type.Params)
+		}
+		h.decorations(n.Decs.Params)
+		h.node(n.Type.Results != nil)
+		if n.Type.Results != nil {
+			h.hash(n.Type.Results)
+		}
+		h.decorations(n.Decs.Results)
+		h.node(n.Body != nil)
+		if n.Body != nil {
+			h.hash(n.Body)
+		}
+		h.decorations(n.Decs.End)
+		h.space(n.Decs.After)
+	case *FuncLit:
+		h.string("FuncLit")
+		h.space(n.Decs.Before)
+		h.decorations(n.Decs.Start)
+		h.node(n.Type != nil)
+		if n.Type != nil {
+			h.hash(n.Type)
+		}
+		h.decorations(n.Decs.Type)
+		h.node(n.Body != nil)
+		if n.Body != nil {
+			h.hash(n.Body)
+		}
+		h.decorations(n.Decs.End)
+		h.space(n.Decs.After)
+	case *FuncType:
+		h.string("FuncType")
+		h.space(n.Decs.Before)
+		h.decorations(n.Decs.Start)
+		h.value(n.Func)
+		h.decorations(n.Decs.Func)
+		h.node(n.Params != nil)
+		if n.Params != nil {
+			h.hash(n.Params)
+		}
+		h.decorations(n.Decs.Params)
+		h.node(n.Results != nil)
+		if n.Results != nil {
+			h.hash(n.Results)
+		}
+		h.decorations(n.Decs.End)
+		h.space(n.Decs.After)
+	case *GenDecl:
+		h.string("GenDecl")
+		h.space(n.Decs.Before)
+		h.decorations(n.Decs.Start)
+		h.value(n.Tok)
+		h.decorations(n.Decs.Tok)
+		h.value(n.Lparen)
+		h.decorations(n.Decs.Lparen)
+		h.value(len(n.Specs))
+		for _, v := range n.Specs {
+			h.hash(v)
+		}
+		h.value(n.Rparen)
+		h.decorations(n.Decs.End)
+		h.space(n.Decs.After)
+	case *GoStmt:
+		h.string("GoStmt")
+		h.space(n.Decs.Before)
+		h.decorations(n.Decs.Start)
+		h.decorations(n.Decs.Go)
+		h.node(n.Call != nil)
+		if n.Call != nil {
+			h.hash(n.Call)
+		}
+		h.decorations(n.Decs.End)
+		h.space(n.Decs.After)
+	case *Ident:
+		h.string("Ident")
+		h.space(n.Decs.Before)
+		h.decorations(n.Decs.Start)
+		h.decorations(n.Decs.X)
+		h.string(n.Name)
+		h.decorations(n.Decs.End)
+		h.string(n.Path)
+		h.space(n.Decs.After)
+	case *IfStmt:
+		h.string("IfStmt")
+		h.space(n.Decs.Before)
+		h.decorations(n.Decs.Start)
+		h.decorations(n.Decs.If)
+		h.node(n.Init != nil)
+		if n.Init != nil {
+			h.hash(n.Init)
+		}
+		h.decorations(n.Decs.Init)
+		h.node(n.Cond != nil)
+		if n.Cond != nil {
+			h.hash(n.Cond)
+		}
+		h.decorations(n.Decs.Cond)
+		h.node(n.Body != nil)
+		if n.Body != nil {
+			h.hash(n.Body)
+		}
+		h.decorations(n.Decs.Else)
+		h.node(n.Else != nil)
+		if n.Else != nil {
+			h.hash(n.Else)
+		}
+		h.decorations(n.Decs.End)
+		h.space(n.Decs.After)
+	case *ImportSpec:
+		h.string("ImportSpec")
+		h.space(n.Decs.Before)
+		h.decorations(n.Decs.Start)
+		h.node(n.Name != nil)
+		if n.Name != nil {
+			h.hash(n.Name)
+		}
+		h.decorations(n.Decs.Name)
+		h.node(n.Path != nil)
+		if n.Path != nil {
+			h.hash(n.Path)
+		}
+		h.decorations(n.Decs.End)
+		h.space(n.Decs.After)
+	case *IncDecStmt:
+		h.string("IncDecStmt")
+		h.space(n.Decs.Before)
+		h.decorations(n.Decs.Start)
+		h.node(n.X != nil)
+		if n.X != nil {
+			h.hash(n.X)
+		}
+		h.decorations(n.Decs.X)
+		h.value(n.Tok)
+		h.decorations(n.Decs.End)
+		h.space(n.Decs.After)
+	case *IndexExpr:
+		h.string("IndexExpr")
+		h.space(n.Decs.Before)
+		h.decorations(n.Decs.Start)
+		h.node(n.X != nil)
+		if n.X != nil {
+			h.hash(n.X)
+		}
+		h.decorations(n.Decs.X)
+		h.decorations(n.Decs.Lbrack)
+		h.node(n.Index != nil)
+		if n.Index != nil {
+			h.hash(n.Index)
+		}
+		h.decorations(n.Decs.Index)
+		h.decorations(n.Decs.End)
+		h.space(n.Decs.After)
+	case *InterfaceType:
+		h.string("InterfaceType")
+		h.space(n.Decs.Before)
+		h.decorations(n.Decs.Start)
+		h.decorations(n.Decs.Interface)
+		h.node(n.Methods != nil)
+		if n.Methods != nil {
+			h.hash(n.Methods)
+		}
+		h.decorations(n.Decs.End)
+		h.value(n.Incomplete)
+		h.space(n.Decs.After)
+	case *KeyValueExpr:
+		h.string("KeyValueExpr")
+		h.space(n.Decs.Before)
+		h.decorations(n.Decs.Start)
+		h.node(n.Key != nil)
+		if n.Key != nil {
+			h.hash(n.Key)
+		}
+		h.decorations(n.Decs.Key)
+		h.decorations(n.Decs.Colon)
+		h.node(n.Value != nil)
+		if n.Value != nil {
+			h.hash(n.Value)
+		}
+		h.decorations(n.Decs.End)
+		h.space(n.Decs.After)
+	case *LabeledStmt:
+		h.string("LabeledStmt")
+		h.space(n.Decs.Before)
+		h.decorations(n.Decs.Start)
+		h.node(n.Label != nil)
+		if n.Label != nil {
+			h.hash(n.Label)
+		}
+		h.decorations(n.Decs.Label)
+		h.decorations(n.Decs.Colon)
+		h.node(n.Stmt != nil)
+		if n.Stmt != nil {
+			h.hash(n.Stmt)
+		}
+		h.decorations(n.Decs.End)
+		h.space(n.Decs.After)
+	case *MapType:
+		h.string("MapType")
+		h.space(n.Decs.Before)
+		h.decorations(n.Decs.Start)
+		h.decorations(n.Decs.Map)
+		h.node(n.Key != nil)
+		if n.Key != nil {
+			h.hash(n.Key)
+		}
+		h.decorations(n.Decs.Key)
+		h.node(n.Value != nil)
+		if n.Value != nil {
+			h.hash(n.Value)
+		}
+		h.decorations(n.Decs.End)
+		h.space(n.Decs.After)
+	case *Package:
+		h.string("Package")
+		h.value(n.Name)
+		h.value(len(n.Files))
+		for _, k := range sortedFileNames(n.Files) {
+			h.string(k)
+			h.hash(n.Files[k])
+		}
+	case *ParenExpr:
+		h.string("ParenExpr")
+		h.space(n.Decs.Before)
+		h.decorations(n.Decs.Start)
+		h.decorations(n.Decs.Lparen)
+		h.node(n.X != nil)
+		if n.X != nil {
+			h.hash(n.X)
+		}
+		h.decorations(n.Decs.X)
+		h.decorations(n.Decs.End)
+		h.space(n.Decs.After)
+	case *RangeStmt:
+		h.string("RangeStmt")
+		h.space(n.Decs.Before)
+		h.decorations(n.Decs.Start)
+		h.decorations(n.Decs.For)
+		h.node(n.Key != nil)
+		if n.Key != nil {
+			h.hash(n.Key)
+		}
+		h.decorations(n.Decs.Key)
+		h.node(n.Value != nil)
+		if n.Value != nil {
+			h.hash(n.Value)
+		}
+		h.decorations(n.Decs.Value)
+		h.value(n.Tok)
+		h.decorations(n.Decs.Range)
+		h.node(n.X != nil)
+		if n.X != nil {
+			h.hash(n.X)
+		}
+		h.decorations(n.Decs.X)
+		h.node(n.Body != nil)
+		if n.Body != nil {
+			h.hash(n.Body)
+		}
+		h.decorations(n.Decs.End)
+		h.space(n.Decs.After)
+	case *ReturnStmt:
+		h.string("ReturnStmt")
+		h.space(n.Decs.Before)
+		h.decorations(n.Decs.Start)
+		h.decorations(n.Decs.Return)
+		h.value(len(n.Results))
+		for _, v := range n.Results {
+			h.hash(v)
+		}
+		h.decorations(n.Decs.End)
+		h.space(n.Decs.After)
+	case *SelectStmt:
+		h.string("SelectStmt")
+		h.space(n.Decs.Before)
+		h.decorations(n.Decs.Start)
+		h.decorations(n.Decs.Select)
+		h.node(n.Body != nil)
+		if n.Body != nil {
+			h.hash(n.Body)
+		}
+		h.decorations(n.Decs.End)
+		h.space(n.Decs.After)
+	case *SelectorExpr:
+		h.string("SelectorExpr")
+		h.space(n.Decs.Before)
+		h.decorations(n.Decs.Start)
+		h.node(n.X != nil)
+		if n.X != nil {
+			h.hash(n.X)
+		}
+		h.decorations(n.Decs.X)
+		h.node(n.Sel != nil)
+		if n.Sel != nil {
+			h.hash(n.Sel)
+		}
+		h.decorations(n.Decs.End)
+		h.space(n.Decs.After)
+	case *SendStmt:
+		h.string("SendStmt")
+		h.space(n.Decs.Before)
+		h.decorations(n.Decs.Start)
+		h.node(n.Chan != nil)
+		if n.Chan != nil {
+			h.hash(n.Chan)
+		}
+		h.decorations(n.Decs.Chan)
+		h.decorations(n.Decs.Arrow)
+		h.node(n.Value != nil)
+		if n.Value != nil {
+			h.hash(n.Value)
+		}
+		h.decorations(n.Decs.End)
+		h.space(n.Decs.After)
+	case *SliceExpr:
+		h.string("SliceExpr")
+		h.space(n.Decs.Before)
+		h.decorations(n.Decs.Start)
+		h.node(n.X != nil)
+		if n.X != nil {
+			h.hash(n.X)
+		}
+		h.decorations(n.Decs.X)
+		h.decorations(n.Decs.Lbrack)
+		h.node(n.Low != nil)
+		if n.Low != nil {
+			h.hash(n.Low)
+		}
+		h.decorations(n.Decs.Low)
+		h.node(n.High != nil)
+		if n.High != nil {
+			h.hash(n.High)
+		}
+		h.decorations(n.Decs.High)
+		h.node(n.Max != nil)
+		if n.Max != nil {
+			h.hash(n.Max)
+		}
+		h.decorations(n.Decs.Max)
+		h.decorations(n.Decs.End)
+		h.value(n.Slice3)
+		h.space(n.Decs.After)
+	case *StarExpr:
+		h.string("StarExpr")
+		h.space(n.Decs.Before)
+		h.decorations(n.Decs.Start)
+		h.decorations(n.Decs.Star)
+		h.node(n.X != nil)
+		if n.X != nil {
+			h.hash(n.X)
+		}
+		h.decorations(n.Decs.End)
+		h.space(n.Decs.After)
+	case *StructType:
+		h.string("StructType")
+		h.space(n.Decs.Before)
+		h.decorations(n.Decs.Start)
+		h.decorations(n.Decs.Struct)
+		h.node(n.Fields != nil)
+		if n.Fields != nil {
+			h.hash(n.Fields)
+		}
+		h.decorations(n.Decs.End)
+		h.value(n.Incomplete)
+		h.space(n.Decs.After)
+	case *SwitchStmt:
+		h.string("SwitchStmt")
+		h.space(n.Decs.Before)
+		h.decorations(n.Decs.Start)
+		h.decorations(n.Decs.Switch)
+		h.node(n.Init != nil)
+		if n.Init != nil {
+			h.hash(n.Init)
+		}
+		h.decorations(n.Decs.Init)
+		h.node(n.Tag != nil)
+		if n.Tag != nil {
+			h.hash(n.Tag)
+		}
+		h.decorations(n.Decs.Tag)
+		h.node(n.Body != nil)
+		if n.Body != nil {
+			h.hash(n.Body)
+		}
+		h.decorations(n.Decs.End)
+		h.space(n.Decs.After)
+	case *TypeAssertExpr:
+		h.string("TypeAssertExpr")
+		h.space(n.Decs.Before)
+		h.decorations(n.Decs.Start)
+		h.node(n.X != nil)
+		if n.X != nil {
+			h.hash(n.X)
+		}
+		h.decorations(n.Decs.X)
+		h.decorations(n.Decs.Lparen)
+		h.node(n.Type != nil)
+		if n.Type != nil {
+			h.hash(n.Type)
+		}
+		h.decorations(n.Decs.Type)
+		h.decorations(n.Decs.End)
+		h.space(n.Decs.After)
+	case *TypeSpec:
+		h.string("TypeSpec")
+		h.space(n.Decs.Before)
+		h.decorations(n.Decs.Start)
+		h.node(n.Name != nil)
+		if n.Name != nil {
+			h.hash(n.Name)
+		}
+		h.value(n.Assign)
+		h.decorations(n.Decs.Name)
+		h.node(n.Type != nil)
+		if n.Type != nil {
+			h.hash(n.Type)
+		}
+		h.decorations(n.Decs.End)
+		h.space(n.Decs.After)
+	case *TypeSwitchStmt:
+		h.string("TypeSwitchStmt")
+		h.space(n.Decs.Before)
+		h.decorations(n.Decs.Start)
+		h.decorations(n.Decs.Switch)
+		h.node(n.Init != nil)
+		if n.Init != nil {
+			h.hash(n.Init)
+		}
+		h.decorations(n.Decs.Init)
+		h.node(n.Assign != nil)
+		if n.Assign != nil {
+			h.hash(n.Assign)
+		}
+		h.decorations(n.Decs.Assign)
+		h.node(n.Body != nil)
+		if n.Body != nil {
+			h.hash(n.Body)
+		}
+		h.decorations(n.Decs.End)
+		h.space(n.Decs.After)
+	case *UnaryExpr:
+		h.string("UnaryExpr")
+		h.space(n.Decs.Before)
+		h.decorations(n.Decs.Start)
+		h.value(n.Op)
+		h.decorations(n.Decs.Op)
+		h.node(n.X != nil)
+		if n.X != nil {
+			h.hash(n.X)
+		}
+		h.decorations(n.Decs.End)
+		h.space(n.Decs.After)
+	case *ValueSpec:
+		h.string("ValueSpec")
+		h.space(n.Decs.Before)
+		h.decorations(n.Decs.Start)
+		h.value(len(n.Names))
+		for _, v := range n.Names {
+			h.hash(v)
+		}
+		h.node(n.Type != nil)
+		if n.Type != nil {
+			h.hash(n.Type)
+		}
+		h.decorations(n.Decs.Assign)
+		h.value(len(n.Values))
+		for _, v := range n.Values {
+			h.hash(v)
+		}
+		h.decorations(n.Decs.End)
+		h.space(n.Decs.After)
+	default:
+		panic(fmt.Sprintf("%T", n))
+	}
+}
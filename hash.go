@@ -0,0 +1,107 @@
+package dst
+
+import (
+	"fmt"
+	"go/token"
+	"sort"
+)
+
+// Hash returns a structural hash of the node and its children. Trees that are structurally equal
+// (the same node types, names, literal values and tokens) have the same hash, regardless of their
+// decorations. Objects and scopes are ignored. The hash is deterministic, but it is not
+// cryptographic and different trees may collide, so it should only be used as a cache key
+// alongside a full comparison, or where occasional collisions are acceptable.
+func Hash(n Node) uint64 {
+	h := &hasher{sum: fnvOffset}
+	h.hash(n)
+	return h.sum
+}
+
+// HashDecorated is the same as Hash, but the decorations (line spacing and comments) are also
+// included in the hash.
+func HashDecorated(n Node) uint64 {
+	h := &hasher{sum: fnvOffset, decorated: true}
+	h.hash(n)
+	return h.sum
+}
+
+const (
+	fnvOffset = 14695981039346656037
+	fnvPrime  = 1099511628211
+)
+
+// hasher computes a 64-bit FNV-1a hash.
+type hasher struct {
+	sum       uint64
+	decorated bool
+}
+
+func (h *hasher) byte(b byte) {
+	h.sum ^= uint64(b)
+	h.sum *= fnvPrime
+}
+
+func (h *hasher) uint(v uint64) {
+	for i := 0; i < 8; i++ {
+		h.byte(byte(v >> (8 * i)))
+	}
+}
+
+func (h *hasher) string(s string) {
+	// the length prevents collisions between adjacent strings (e.g. "ab" + "c" and "a" + "bc")
+	h.uint(uint64(len(s)))
+	for i := 0; i < len(s); i++ {
+		h.byte(s[i])
+	}
+}
+
+// node records whether an optional child node is present.
+func (h *hasher) node(exists bool) {
+	h.value(exists)
+}
+
+func (h *hasher) value(v interface{}) {
+	switch v := v.(type) {
+	case bool:
+		if v {
+			h.byte(1)
+		} else {
+			h.byte(0)
+		}
+	case int:
+		h.uint(uint64(v))
+	case string:
+		h.string(v)
+	case token.Token:
+		h.uint(uint64(v))
+	case ChanDir:
+		h.uint(uint64(v))
+	default:
+		panic(fmt.Sprintf("%T", v))
+	}
+}
+
+func (h *hasher) space(s SpaceType) {
+	if h.decorated {
+		h.uint(uint64(s))
+	}
+}
+
+func (h *hasher) decorations(d Decorations) {
+	if !h.decorated {
+		return
+	}
+	h.uint(uint64(len(d)))
+	for _, s := range d {
+		h.string(s)
+	}
+}
+
+func sortedFileNames(m map[string]*File) []string {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
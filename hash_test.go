@@ -0,0 +1,80 @@
+package dst_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/dave/dst"
+	"github.com/dave/dst/decorator"
+)
+
+func TestHash(t *testing.T) {
+	src := `package a
+
+import "fmt"
+
+func main() {
+	a := []int{1, 2}
+	for i, v := range a {
+		fmt.Println(i, v)
+	}
+}
+`
+	parse := func(src string) *dst.File {
+		t.Helper()
+		f, err := decorator.Parse(src)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return f
+	}
+
+	f := parse(src)
+	if dst.Hash(f) != dst.Hash(parse(src)) {
+		t.Error("expected equal trees to hash equally")
+	}
+	if dst.Hash(f) != dst.Hash(dst.Clone(f)) {
+		t.Error("expected a clone to hash equally")
+	}
+	if dst.HashDecorated(f) != dst.HashDecorated(parse(src)) {
+		t.Error("expected equal decorated trees to hash equally")
+	}
+
+	// a small change flips the hash
+	changes := []struct{ name, old, new string }{
+		{"literal", "1, 2}", "1, 3}"},
+		{"token", "i, v := range", "i, v = range"},
+		{"name", "Println(i, v)", "Print(i, v)"},
+		{"missing", "[]int{1, 2}", "[]int{1}"},
+	}
+	for _, c := range changes {
+		if dst.Hash(parse(replaceOnce(t, src, c.old, c.new))) == dst.Hash(f) {
+			t.Errorf("%s: expected different hash", c.name)
+		}
+	}
+
+	// decorations are only included by HashDecorated
+	commented := parse(replaceOnce(t, src, "func main() {", "// main is the entry point.\nfunc main() {"))
+	if dst.Hash(commented) != dst.Hash(f) {
+		t.Error("expected decorations to be ignored by Hash")
+	}
+	if dst.HashDecorated(commented) == dst.HashDecorated(f) {
+		t.Error("expected decorations to change HashDecorated")
+	}
+
+	// hashing a subtree
+	body := f.Decls[1].(*dst.FuncDecl).Body
+	other := parse(src).Decls[1].(*dst.FuncDecl).Body
+	if dst.Hash(body) != dst.Hash(other) || dst.Hash(body) == dst.Hash(f) {
+		t.Error("unexpected subtree hash")
+	}
+}
+
+func replaceOnce(t *testing.T, s, old, new string) string {
+	t.Helper()
+	out := strings.Replace(s, old, new, 1)
+	if out == s {
+		t.Fatalf("%q not found", old)
+	}
+	return out
+}
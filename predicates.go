@@ -0,0 +1,115 @@
+package dst
+
+// IsExpr returns true if n is an expression or type node.
+func IsExpr(n Node) bool {
+	_, ok := n.(Expr)
+	return ok
+}
+
+// IsStmt returns true if n is a statement node.
+func IsStmt(n Node) bool {
+	_, ok := n.(Stmt)
+	return ok
+}
+
+// IsDecl returns true if n is a declaration node.
+func IsDecl(n Node) bool {
+	_, ok := n.(Decl)
+	return ok
+}
+
+// IsSpec returns true if n is an import, value or type spec.
+func IsSpec(n Node) bool {
+	_, ok := n.(Spec)
+	return ok
+}
+
+// AsIdent returns n as an *Ident, and false if n is not an *Ident.
+func AsIdent(n Node) (*Ident, bool) {
+	v, ok := n.(*Ident)
+	return v, ok
+}
+
+// AsBasicLit returns n as a *BasicLit, and false if n is not a *BasicLit.
+func AsBasicLit(n Node) (*BasicLit, bool) {
+	v, ok := n.(*BasicLit)
+	return v, ok
+}
+
+// AsCompositeLit returns n as a *CompositeLit, and false if n is not a *CompositeLit.
+func AsCompositeLit(n Node) (*CompositeLit, bool) {
+	v, ok := n.(*CompositeLit)
+	return v, ok
+}
+
+// AsFuncLit returns n as a *FuncLit, and false if n is not a *FuncLit.
+func AsFuncLit(n Node) (*FuncLit, bool) {
+	v, ok := n.(*FuncLit)
+	return v, ok
+}
+
+// AsSelector returns n as a *SelectorExpr, and false if n is not a *SelectorExpr.
+func AsSelector(n Node) (*SelectorExpr, bool) {
+	v, ok := n.(*SelectorExpr)
+	return v, ok
+}
+
+// AsCall returns n as a *CallExpr, and false if n is not a *CallExpr.
+func AsCall(n Node) (*CallExpr, bool) {
+	v, ok := n.(*CallExpr)
+	return v, ok
+}
+
+// AsBinary returns n as a *BinaryExpr, and false if n is not a *BinaryExpr.
+func AsBinary(n Node) (*BinaryExpr, bool) {
+	v, ok := n.(*BinaryExpr)
+	return v, ok
+}
+
+// AsUnary returns n as a *UnaryExpr, and false if n is not a *UnaryExpr.
+func AsUnary(n Node) (*UnaryExpr, bool) {
+	v, ok := n.(*UnaryExpr)
+	return v, ok
+}
+
+// AsAssign returns n as an *AssignStmt, and false if n is not an *AssignStmt.
+func AsAssign(n Node) (*AssignStmt, bool) {
+	v, ok := n.(*AssignStmt)
+	return v, ok
+}
+
+// AsExprStmt returns n as an *ExprStmt, and false if n is not an *ExprStmt.
+func AsExprStmt(n Node) (*ExprStmt, bool) {
+	v, ok := n.(*ExprStmt)
+	return v, ok
+}
+
+// AsReturn returns n as a *ReturnStmt, and false if n is not a *ReturnStmt.
+func AsReturn(n Node) (*ReturnStmt, bool) {
+	v, ok := n.(*ReturnStmt)
+	return v, ok
+}
+
+// AsBlock returns n as a *BlockStmt, and false if n is not a *BlockStmt.
+func AsBlock(n Node) (*BlockStmt, bool) {
+	v, ok := n.(*BlockStmt)
+	return v, ok
+}
+
+// AsIf returns n as an *IfStmt, and false if n is not an *IfStmt.
+func AsIf(n Node) (*IfStmt, bool) {
+	v, ok := n.(*IfStmt)
+	return v, ok
+}
+
+// AsFuncDecl returns n as a *FuncDecl, and false if n is not a *FuncDecl.
+func AsFuncDecl(n Node) (*FuncDecl, bool) {
+	v, ok := n.(*FuncDecl)
+	return v, ok
+}
+
+// AsGenDecl returns n as a *GenDecl, and false if n is not a *GenDecl.
+func AsGenDecl(n Node) (*GenDecl, bool) {
+	v, ok := n.(*GenDecl)
+	return v, ok
+}
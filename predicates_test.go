@@ -0,0 +1,120 @@
+package dst_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/dave/dst"
+	"github.com/dave/dst/decorator"
+)
+
+func TestPredicates(t *testing.T) {
+	f, err := decorator.Parse(`package a
+
+import "fmt"
+
+func main() {
+	a := 1
+	if a > 0 {
+		fmt.Println(-a, []int{a}, func() {})
+	}
+	return
+}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var kinds []string
+	dst.Inspect(f, func(n dst.Node) bool {
+		if n == nil {
+			return false
+		}
+		var k []string
+		for name, is := range map[string]func(dst.Node) bool{
+			"expr": dst.IsExpr,
+			"stmt": dst.IsStmt,
+			"decl": dst.IsDecl,
+			"spec": dst.IsSpec,
+		} {
+			if is(n) {
+				k = append(k, name)
+			}
+		}
+		if len(k) > 1 {
+			t.Errorf("%T matched %v", n, k)
+		}
+		if len(k) == 1 {
+			kinds = append(kinds, fmt.Sprintf("%s:%s", strings.TrimPrefix(fmt.Sprintf("%T", n), "*dst."), k[0]))
+		}
+		return true
+	})
+	expect := "Ident:expr GenDecl:decl ImportSpec:spec BasicLit:expr FuncDecl:decl Ident:expr FuncType:expr " +
+		"BlockStmt:stmt AssignStmt:stmt Ident:expr BasicLit:expr IfStmt:stmt BinaryExpr:expr Ident:expr " +
+		"BasicLit:expr BlockStmt:stmt ExprStmt:stmt CallExpr:expr SelectorExpr:expr Ident:expr Ident:expr " +
+		"UnaryExpr:expr Ident:expr CompositeLit:expr ArrayType:expr Ident:expr Ident:expr FuncLit:expr " +
+		"FuncType:expr BlockStmt:stmt ReturnStmt:stmt"
+	if found := strings.Join(kinds, " "); found != expect {
+		t.Errorf("\nexpect: %s\nfound : %s", expect, found)
+	}
+
+	fd, ok := dst.AsFuncDecl(f.Decls[1])
+	if !ok {
+		t.Fatal("expected *dst.FuncDecl")
+	}
+	if _, ok := dst.AsGenDecl(fd); ok {
+		t.Error("unexpected *dst.GenDecl")
+	}
+	if gd, ok := dst.AsGenDecl(f.Decls[0]); !ok || len(gd.Specs) != 1 {
+		t.Error("expected *dst.GenDecl")
+	}
+	if body, ok := dst.AsBlock(fd.Body); !ok || len(body.List) != 3 {
+		t.Fatal("expected *dst.BlockStmt")
+	}
+	assign, ok := dst.AsAssign(fd.Body.List[0])
+	if !ok {
+		t.Fatal("expected *dst.AssignStmt")
+	}
+	if id, ok := dst.AsIdent(assign.Lhs[0]); !ok || id.Name != "a" {
+		t.Error("expected *dst.Ident a")
+	}
+	if lit, ok := dst.AsBasicLit(assign.Rhs[0]); !ok || lit.Value != "1" {
+		t.Error("expected *dst.BasicLit 1")
+	}
+	ifs, ok := dst.AsIf(fd.Body.List[1])
+	if !ok {
+		t.Fatal("expected *dst.IfStmt")
+	}
+	if _, ok := dst.AsBinary(ifs.Cond); !ok {
+		t.Error("expected *dst.BinaryExpr")
+	}
+	es, ok := dst.AsExprStmt(ifs.Body.List[0])
+	if !ok {
+		t.Fatal("expected *dst.ExprStmt")
+	}
+	call, ok := dst.AsCall(es.X)
+	if !ok {
+		t.Fatal("expected *dst.CallExpr")
+	}
+	if _, ok := dst.AsCall(call.Fun); ok {
+		t.Error("unexpected *dst.CallExpr")
+	}
+	if _, ok := dst.AsSelector(call.Fun); !ok {
+		t.Error("expected *dst.SelectorExpr")
+	}
+	if _, ok := dst.AsUnary(call.Args[0]); !ok {
+		t.Error("expected *dst.UnaryExpr")
+	}
+	if _, ok := dst.AsCompositeLit(call.Args[1]); !ok {
+		t.Error("expected *dst.CompositeLit")
+	}
+	if _, ok := dst.AsFuncLit(call.Args[2]); !ok {
+		t.Error("expected *dst.FuncLit")
+	}
+	if _, ok := dst.AsReturn(fd.Body.List[2]); !ok {
+		t.Error("expected *dst.ReturnStmt")
+	}
+	if _, ok := dst.AsCall(nil); ok {
+		t.Error("unexpected *dst.CallExpr for nil")
+	}
+}
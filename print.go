@@ -11,6 +11,7 @@ import (
 	"io"
 	"os"
 	"reflect"
+	"sort"
 )
 
 // A FieldFilter may be provided to Fprint to control the output.
@@ -34,7 +35,8 @@ func NotNilFilter(_ string, v reflect.Value) bool {
 // A non-nil FieldFilter f may be provided to control the output:
 // struct fields for which f(fieldname, fieldvalue) is true are
 // printed; all others are filtered from the output. Unexported
-// struct fields are never printed.
+// struct fields are never printed. Map entries are printed in
+// sorted key order, so the output is deterministic.
 func Fprint(w io.Writer, x interface{}, f FieldFilter) error {
 	return fprint(w, x, f)
 }
@@ -128,6 +130,16 @@ func (p *printer) printf(format string, args ...interface{}) {
 	}
 }
 
+// sortedMapKeys returns the keys of a map ordered by their printed value, so that maps (e.g.
+// Scope.Objects or Package.Files) are printed deterministically.
+func sortedMapKeys(x reflect.Value) []reflect.Value {
+	keys := x.MapKeys()
+	sort.SliceStable(keys, func(i, j int) bool {
+		return fmt.Sprint(keys[i].Interface()) < fmt.Sprint(keys[j].Interface())
+	})
+	return keys
+}
+
 // Implementation note: Print is written for AST nodes but could be
 // used to print arbitrary data structures; such a version should
 // probably be in a different package.
@@ -152,7 +164,7 @@ func (p *printer) print(x reflect.Value) {
 		if x.Len() > 0 {
 			p.indent++
 			p.printf("\n")
-			for _, key := range x.MapKeys() {
+			for _, key := range sortedMapKeys(x) {
 				p.print(key)
 				p.printf(": ")
 				p.print(x.MapIndex(key))
@@ -6,6 +6,7 @@ package dst
 
 import (
 	"bytes"
+	"go/token"
 	"strings"
 	"testing"
 )
@@ -28,6 +29,12 @@ var tests = []struct {
 		`0  map[string]int (len = 1) {
 		1  .  "a": 1
 		2  }`},
+	{map[string]int{"c": 3, "a": 1, "b": 2},
+		`0  map[string]int (len = 3) {
+		1  .  "a": 1
+		2  .  "b": 2
+		3  .  "c": 3
+		4  }`},
 
 	// pointers
 	{new(int), "0  *0"},
@@ -95,3 +102,70 @@ func TestPrint(t *testing.T) {
 		}
 	}
 }
+
+func TestPrintNode(t *testing.T) {
+	n := &ExprStmt{
+		X: &CallExpr{
+			Fun: &Ident{Name: "f", Path: "a/b"},
+			Args: []Expr{
+				&BasicLit{Kind: token.INT, Value: "1"},
+			},
+			Decs: CallExprDecorations{Lparen: Decorations{"/* a */"}},
+		},
+		Decs: ExprStmtDecorations{
+			NodeDecs: NodeDecs{Before: NewLine, End: Decorations{"// b"}, After: EmptyLine},
+		},
+	}
+	expect := `0  *dst.ExprStmt {
+		1  .  X: *dst.CallExpr {
+		2  .  .  Fun: *dst.Ident {
+		3  .  .  .  Name: "f"
+		4  .  .  .  Path: "a/b"
+		5  .  .  .  Decs: dst.IdentDecorations {
+		6  .  .  .  .  NodeDecs: dst.NodeDecs {
+		7  .  .  .  .  .  Before: None
+		8  .  .  .  .  .  After: None
+		9  .  .  .  .  }
+		10  .  .  .  }
+		11  .  .  }
+		12  .  .  Args: []dst.Expr (len = 1) {
+		13  .  .  .  0: *dst.BasicLit {
+		14  .  .  .  .  Kind: INT
+		15  .  .  .  .  Value: "1"
+		16  .  .  .  .  Decs: dst.BasicLitDecorations {
+		17  .  .  .  .  .  NodeDecs: dst.NodeDecs {
+		18  .  .  .  .  .  .  Before: None
+		19  .  .  .  .  .  .  After: None
+		20  .  .  .  .  .  }
+		21  .  .  .  .  }
+		22  .  .  .  }
+		23  .  .  }
+		24  .  .  Ellipsis: false
+		25  .  .  Decs: dst.CallExprDecorations {
+		26  .  .  .  NodeDecs: dst.NodeDecs {
+		27  .  .  .  .  Before: None
+		28  .  .  .  .  After: None
+		29  .  .  .  }
+		30  .  .  .  Lparen: dst.Decorations (len = 1) {
+		31  .  .  .  .  0: "/* a */"
+		32  .  .  .  }
+		33  .  .  }
+		34  .  }
+		35  .  Decs: dst.ExprStmtDecorations {
+		36  .  .  NodeDecs: dst.NodeDecs {
+		37  .  .  .  Before: NewLine
+		38  .  .  .  End: dst.Decorations (len = 1) {
+		39  .  .  .  .  0: "// b"
+		40  .  .  .  }
+		41  .  .  .  After: EmptyLine
+		42  .  .  }
+		43  .  }
+		44  }`
+	var buf bytes.Buffer
+	if err := Fprint(&buf, n, NotNilFilter); err != nil {
+		t.Fatal(err)
+	}
+	if s, ts := trim(buf.String()), trim(expect); s != ts {
+		t.Errorf("got:\n%s\nexpected:\n%s\n", s, ts)
+	}
+}